@@ -0,0 +1,215 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/crisecheverria/codequest/internal/challenge"
+	"github.com/crisecheverria/codequest/internal/harness"
+	"github.com/crisecheverria/codequest/internal/native"
+)
+
+// caseSelection picks the test cases codequest test runs. A case must
+// satisfy every part that is set.
+type caseSelection struct {
+	// ranges are the sample case numbers given with --case
+	ranges []caseRange
+	// match is the --match pattern for case descriptions
+	match *regexp.Regexp
+	// failed holds the positions of the cases that failed the last time
+	// they ran, when only those should run again
+	failed map[int]bool
+	// onlyLocal leaves out the challenge's own test cases
+	onlyLocal bool
+}
+
+// caseRange is a range of sample case numbers, from 1. A last of 0 leaves
+// the range open.
+type caseRange struct {
+	first, last int
+}
+
+func (r caseRange) contains(number int) bool {
+	return number >= r.first && (r.last == 0 || number <= r.last)
+}
+
+// parseCaseRanges parses case numbers and ranges such as 3, 2-4, 5- or -2.
+func parseCaseRanges(specs []string) ([]caseRange, error) {
+	var ranges []caseRange
+	for _, spec := range specs {
+		spec = strings.TrimSpace(spec)
+		first, last, isRange := strings.Cut(spec, "-")
+		if !isRange {
+			last = first
+		}
+
+		r := caseRange{first: 1}
+		var err error
+		if first != "" {
+			r.first, err = strconv.Atoi(first)
+		}
+		if err == nil && last != "" {
+			r.last, err = strconv.Atoi(last)
+		}
+		if err != nil || spec == "" || spec == "-" || r.first < 1 || r.last < 0 || (r.last != 0 && r.last < r.first) {
+			return nil, fmt.Errorf("invalid test case %q, expected a number such as 3 or a range such as 2-4", spec)
+		}
+		ranges = append(ranges, r)
+	}
+	return ranges, nil
+}
+
+// descriptionPattern returns the case-insensitive pattern --match selects
+// descriptions with. A pattern that isn't a valid regular expression is
+// matched as plain text.
+func descriptionPattern(pattern string) *regexp.Regexp {
+	if re, err := regexp.Compile("(?i)" + pattern); err == nil {
+		return re
+	}
+	return regexp.MustCompile("(?i)" + regexp.QuoteMeta(pattern))
+}
+
+// empty reports whether nothing narrows the selection.
+func (s caseSelection) empty() bool {
+	return s.ranges == nil && s.match == nil && s.failed == nil && !s.onlyLocal
+}
+
+// testRun is the test cases one run covers.
+type testRun struct {
+	cases []challenge.TestCase
+	// positions are the indices of cases in the challenge's test cases,
+	// followed by the local ones
+	positions []int
+	// numbers are the numbers the README gives the sample cases, 0 for
+	// hidden ones, and the position of local cases in their file, from 1
+	numbers []int
+	// fingerprint identifies the test cases positions refer to
+	fingerprint string
+}
+
+// selectCases returns the sample cases of ch, or every case if all is set,
+// followed by the local ones, that s selects. Hidden cases are only
+// selected as cases that failed, and local ones are never selected by
+// number.
+func selectCases(ch challenge.Challenge, local []challenge.TestCase, all bool, s caseSelection) testRun {
+	run := testRun{fingerprint: casesFingerprint(ch, local)}
+	number := 0
+	for i, testCase := range ch.TestCases {
+		if s.onlyLocal {
+			break
+		}
+		sample := 0
+		if !testCase.Hidden {
+			number++
+			sample = number
+		}
+
+		switch {
+		case s.failed != nil && !s.failed[i]:
+			continue
+		case s.failed == nil && testCase.Hidden && !all:
+			continue
+		case s.ranges != nil && !s.inRanges(sample):
+			continue
+		case s.match != nil && (testCase.Hidden || !s.match.MatchString(testCase.Description)):
+			continue
+		}
+
+		run.cases = append(run.cases, testCase)
+		run.positions = append(run.positions, i)
+		run.numbers = append(run.numbers, sample)
+	}
+
+	for j, testCase := range local {
+		i := len(ch.TestCases) + j
+		switch {
+		case s.failed != nil && !s.failed[i]:
+			continue
+		case s.ranges != nil:
+			continue
+		case s.match != nil && !s.match.MatchString(testCase.Description):
+			continue
+		}
+
+		run.cases = append(run.cases, testCase)
+		run.positions = append(run.positions, i)
+		run.numbers = append(run.numbers, j+1)
+	}
+	return run
+}
+
+// casesFingerprint returns a hash of the test cases of ch followed by the
+// local ones. Positions of cases saved with one fingerprint no longer refer
+// to the same cases once it changes.
+func casesFingerprint(ch challenge.Challenge, local []challenge.TestCase) string {
+	hash := sha256.New()
+	for _, testCases := range [][]challenge.TestCase{ch.TestCases, local} {
+		data, err := json.Marshal(testCases)
+		if err != nil {
+			data = []byte(fmt.Sprint(testCases))
+		}
+		hash.Write(data)
+		hash.Write([]byte{0})
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// caseLabel names the test case shown with number, such as "Test 2" or
+// "Local test 1".
+func caseLabel(testCase challenge.TestCase, number int) string {
+	if testCase.Local {
+		return fmt.Sprintf("Local test %d", number)
+	}
+	return fmt.Sprintf("Test %d", number)
+}
+
+func (s caseSelection) inRanges(number int) bool {
+	for _, r := range s.ranges {
+		if number > 0 && r.contains(number) {
+			return true
+		}
+	}
+	return false
+}
+
+// reached returns the cases of run that result got to. A program stopped
+// at its first failed case never ran the cases after it.
+func (run testRun) reached(result *native.ExecutionResult) testRun {
+	if result.Status != native.StatusStopped {
+		return run
+	}
+	caseResults, _ := harness.ParseResults(result.Output)
+	last := -1
+	for _, caseResult := range caseResults {
+		last = max(last, caseResult.Index)
+	}
+	if last+1 >= len(run.cases) {
+		return run
+	}
+	return testRun{cases: run.cases[:last+1], positions: run.positions[:last+1], numbers: run.numbers[:last+1], fingerprint: run.fingerprint}
+}
+
+// failedPositions returns the positions in the challenge of the cases of
+// run that result doesn't report as passed.
+func (run testRun) failedPositions(result *native.ExecutionResult) []int {
+	caseResults, _ := harness.ParseResults(result.Output)
+	passed := map[int]bool{}
+	for _, caseResult := range caseResults {
+		if caseResult.Passed {
+			passed[caseResult.Index] = true
+		}
+	}
+
+	var failed []int
+	for i, position := range run.positions {
+		if !passed[i] {
+			failed = append(failed, position)
+		}
+	}
+	return failed
+}
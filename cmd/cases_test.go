@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/crisecheverria/codequest/internal/challenge"
+	"github.com/crisecheverria/codequest/internal/harness"
+	"github.com/crisecheverria/codequest/internal/native"
+)
+
+func TestParseCaseRanges(t *testing.T) {
+	ranges, err := parseCaseRanges([]string{"3", "2-4", "5-", "-2"})
+	if err != nil {
+		t.Fatalf("parseCaseRanges() failed: %v", err)
+	}
+	want := []caseRange{{3, 3}, {2, 4}, {5, 0}, {1, 2}}
+	if !reflect.DeepEqual(ranges, want) {
+		t.Errorf("parseCaseRanges() = %v, want %v", ranges, want)
+	}
+
+	for _, spec := range []string{"", "-", "0", "4-2", "a", "1-b", "1-2-3"} {
+		if _, err := parseCaseRanges([]string{spec}); err == nil {
+			t.Errorf("Expected an error for %q", spec)
+		}
+	}
+}
+
+func TestSelectCases(t *testing.T) {
+	ch := challenge.Challenge{TestCases: []challenge.TestCase{
+		{Description: "handles an empty list"},
+		{Description: "secret empty case", Hidden: true},
+		{Description: "sorts numbers"},
+		{Description: "keeps duplicates"},
+	}}
+
+	local := []challenge.TestCase{
+		{Description: "my empty string", Local: true},
+		{Description: "my long list", Local: true},
+	}
+
+	tests := []struct {
+		name      string
+		all       bool
+		selection caseSelection
+		positions []int
+		numbers   []int
+	}{
+		{"samples", false, caseSelection{}, []int{0, 2, 3, 4, 5}, []int{1, 2, 3, 1, 2}},
+		{"all", true, caseSelection{}, []int{0, 1, 2, 3, 4, 5}, []int{1, 0, 2, 3, 1, 2}},
+		{"numbers", false, caseSelection{ranges: []caseRange{{2, 0}}}, []int{2, 3}, []int{2, 3}},
+		{"match", false, caseSelection{match: descriptionPattern("EMPTY|dup")}, []int{0, 3, 4}, []int{1, 3, 1}},
+		{"invalid pattern", false, caseSelection{match: descriptionPattern("list (")}, nil, nil},
+		{"numbers and match", false, caseSelection{ranges: []caseRange{{1, 2}}, match: descriptionPattern("s")}, []int{0, 2}, []int{1, 2}},
+		{"failed", false, caseSelection{failed: map[int]bool{1: true, 3: true, 5: true}}, []int{1, 3, 5}, []int{0, 3, 2}},
+		{"only local", false, caseSelection{onlyLocal: true}, []int{4, 5}, []int{1, 2}},
+		{"only local and match", false, caseSelection{onlyLocal: true, match: descriptionPattern("list")}, []int{5}, []int{2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			run := selectCases(ch, local, tt.all, tt.selection)
+			if !reflect.DeepEqual(run.positions, tt.positions) || !reflect.DeepEqual(run.numbers, tt.numbers) || len(run.cases) != len(tt.positions) {
+				t.Errorf("selectCases() = positions %v, numbers %v, want %v, %v", run.positions, run.numbers, tt.positions, tt.numbers)
+			}
+		})
+	}
+}
+
+func TestTestRunReached(t *testing.T) {
+	run := testRun{
+		cases:     make([]challenge.TestCase, 4),
+		positions: []int{0, 2, 3, 5},
+		numbers:   []int{1, 2, 3, 4},
+	}
+	output := harness.ResultPrefix + `{"index":0,"passed":true}` + "\n" +
+		harness.ResultPrefix + `{"index":1,"passed":false}` + "\n"
+
+	stopped := run.reached(&native.ExecutionResult{Status: native.StatusStopped, Output: output})
+	if len(stopped.cases) != 2 || !reflect.DeepEqual(stopped.positions, []int{0, 2}) {
+		t.Errorf("Expected the cases up to the failed one, got positions %v", stopped.positions)
+	}
+	if failed := stopped.failedPositions(&native.ExecutionResult{Output: output}); !reflect.DeepEqual(failed, []int{2}) {
+		t.Errorf("failedPositions() = %v, want [2]", failed)
+	}
+
+	// A program that crashed ran no more cases, but they still failed
+	crashed := run.reached(&native.ExecutionResult{Status: native.StatusRuntimeError, Output: output})
+	if len(crashed.cases) != 4 {
+		t.Errorf("Expected every case of a crashed run, got %d", len(crashed.cases))
+	}
+	if failed := crashed.failedPositions(&native.ExecutionResult{Output: output}); !reflect.DeepEqual(failed, []int{2, 3, 5}) {
+		t.Errorf("failedPositions() = %v, want [2 3 5]", failed)
+	}
+}
+
+func TestRememberFailed(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	ch := challenge.Challenge{TestCases: []challenge.TestCase{
+		{Description: "first"}, {Description: "second"}, {Description: "third"},
+	}}
+	output := harness.ResultPrefix + `{"index":0,"passed":false}` + "\n"
+
+	// A case that didn't run keeps failing, while those that ran are
+	// replaced by their new result
+	metadata := &ChallengeMetadata{FailedCases: []int{0, 2}, CasesFingerprint: casesFingerprint(ch, nil)}
+	run := selectCases(ch, nil, false, caseSelection{ranges: []caseRange{{1, 2}}})
+	rememberFailed(metadata, run, &native.ExecutionResult{Output: output})
+	if !reflect.DeepEqual(metadata.FailedCases, []int{0, 1, 2}) {
+		t.Errorf("FailedCases = %v, want [0 1 2]", metadata.FailedCases)
+	}
+
+	// Positions saved for other test cases are dropped
+	local := []challenge.TestCase{{Description: "mine", Local: true}}
+	run = selectCases(ch, local, false, caseSelection{ranges: []caseRange{{1, 1}}})
+	rememberFailed(metadata, run, &native.ExecutionResult{Output: output})
+	if !reflect.DeepEqual(metadata.FailedCases, []int{0}) || metadata.CasesFingerprint != casesFingerprint(ch, local) {
+		t.Errorf("FailedCases = %v, want [0] with the new fingerprint", metadata.FailedCases)
+	}
+
+	saved, err := loadChallengeMetadata(".challenge.json")
+	if err != nil || !reflect.DeepEqual(saved.FailedCases, []int{0}) || saved.CasesFingerprint != metadata.CasesFingerprint {
+		t.Errorf("Expected the failed cases to be saved, got %+v (%v)", saved, err)
+	}
+}
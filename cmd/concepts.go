@@ -0,0 +1,137 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/crisecheverria/codequest/internal/challenge"
+	"github.com/crisecheverria/codequest/internal/native"
+	"github.com/spf13/cobra"
+)
+
+var conceptsCmd = &cobra.Command{
+	Use:   "concepts",
+	Short: "Show the concepts each language teaches and how they build on each other",
+	Long: `Print the concept dependency tree of every language, with learning
+resources and the number of challenges that exercise each concept. A concept
+appears under the prerequisite that comes latest in the learning path; any
+other prerequisites are listed next to it.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		language, _ := cmd.Flags().GetString("language")
+
+		concepts, err := challenge.LoadConcepts()
+		if err != nil {
+			return fmt.Errorf("failed to load concepts: %w", err)
+		}
+		challenges, err := challenge.LoadChallenges()
+		if err != nil {
+			return fmt.Errorf("failed to load challenges: %w", err)
+		}
+
+		for _, problem := range challenge.ValidateConcepts(concepts, challenges) {
+			fmt.Fprintf(os.Stderr, "⚠️  %v\n", problem)
+		}
+
+		if language != "" {
+			challenges = challenge.FilterByLanguage(challenges, language)
+		}
+		counts := map[string]int{}
+		for _, ch := range challenges {
+			for _, tag := range ch.ConceptTags {
+				counts[tag]++
+			}
+		}
+
+		byLanguage := map[string][]challenge.Concept{}
+		for _, concept := range concepts {
+			if language != "" && !strings.EqualFold(concept.Language, language) && concept.Language != challenge.ConceptLanguageAll {
+				continue
+			}
+			byLanguage[concept.Language] = append(byLanguage[concept.Language], concept)
+		}
+		if len(byLanguage) == 0 {
+			fmt.Println("No concepts found matching the criteria.")
+			return nil
+		}
+
+		for i, id := range conceptLanguages(byLanguage) {
+			if i > 0 {
+				fmt.Println()
+			}
+			fmt.Println(conceptLanguageName(id))
+			roots := challenge.ConceptTree(byLanguage[id])
+			for j, root := range roots {
+				printConceptNode(root, "", j == len(roots)-1, counts)
+			}
+		}
+		return nil
+	},
+}
+
+// conceptLanguages returns the languages in byLanguage sorted by id, with
+// the concepts shared by all languages last.
+func conceptLanguages(byLanguage map[string][]challenge.Concept) []string {
+	var ids []string
+	for id := range byLanguage {
+		if id != challenge.ConceptLanguageAll {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	if _, ok := byLanguage[challenge.ConceptLanguageAll]; ok {
+		ids = append(ids, challenge.ConceptLanguageAll)
+	}
+	return ids
+}
+
+func conceptLanguageName(id string) string {
+	if id == challenge.ConceptLanguageAll {
+		return "All languages"
+	}
+	if lang, ok := native.LookupLanguage(id); ok {
+		return lang.Name
+	}
+	return id
+}
+
+// printConceptNode prints node and its children as a tree branch below
+// prefix.
+func printConceptNode(node *challenge.ConceptNode, prefix string, last bool, counts map[string]int) {
+	connector, childPrefix := "├── ", prefix+"│   "
+	if last {
+		connector, childPrefix = "└── ", prefix+"    "
+	}
+
+	line := fmt.Sprintf("%s%s%s (%s) · %s", prefix, connector, node.Concept.Name, node.Concept.Slug, challengeCount(counts[node.Concept.Slug]))
+	if len(node.Requires) > 0 {
+		line += " · also requires " + strings.Join(node.Requires, ", ")
+	}
+	fmt.Println(line)
+
+	// Resources line up under the concept, next to the branch of its children
+	resourcePrefix := childPrefix + "    "
+	if len(node.Children) > 0 {
+		resourcePrefix = childPrefix + "│   "
+	}
+	for _, resource := range node.Concept.Resources {
+		fmt.Printf("%s%s: %s\n", resourcePrefix, resource.Title, resource.URL)
+	}
+
+	for i, child := range node.Children {
+		printConceptNode(child, childPrefix, i == len(node.Children)-1, counts)
+	}
+}
+
+func challengeCount(n int) string {
+	if n == 1 {
+		return "1 challenge"
+	}
+	return fmt.Sprintf("%d challenges", n)
+}
+
+func init() {
+	conceptsCmd.Flags().StringP("language", "l", "", "Only show concepts for this language and those shared by all languages")
+	rootCmd.AddCommand(conceptsCmd)
+}
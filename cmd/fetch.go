@@ -2,8 +2,10 @@ package cmd
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/crisecheverria/codequest/internal/challenge"
+	"github.com/crisecheverria/codequest/internal/native"
 	"github.com/spf13/cobra"
 )
 
@@ -12,22 +14,52 @@ var fetchCmd = &cobra.Command{
 	Short: "Fetch a specific challenge and create local files",
 	Long: `Fetch a coding challenge by its slug and create the necessary template files 
 for local development. This creates a working directory with the challenge template,
-test cases, and instructions.`,
+test cases, and instructions.
+
+A challenge available in several languages needs --language to pick one,
+such as 'codequest fetch add-two-numbers --language go'.`,
 	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
 		slug := args[0]
+		language, _ := cmd.Flags().GetString("language")
 
 		challenges, err := challenge.LoadChallenges()
 		if err != nil {
 			return fmt.Errorf("failed to load challenges: %w", err)
 		}
 
-		ch, found := challenge.FindBySlug(challenges, slug)
-		if !found {
+		variants := challenge.FindVariants(challenges, slug)
+		if len(variants) == 0 {
 			return fmt.Errorf("challenge '%s' not found", slug)
 		}
+		languages := variants[0].Languages()
+
+		var ch challenge.Challenge
+		switch {
+		case language != "":
+			found := false
+			for _, variant := range variants {
+				if strings.EqualFold(variant.Language, language) {
+					ch, found = variant, true
+					break
+				}
+			}
+			if !found {
+				return fmt.Errorf("challenge '%s' is not available in %s, only in %s", slug, language, strings.Join(languages, ", "))
+			}
+		case len(variants) == 1:
+			ch = variants[0]
+		default:
+			return fmt.Errorf("challenge '%s' is available in %s; choose one with --language, such as 'codequest fetch %s --language %s'", slug, strings.Join(languages, ", "), slug, languages[0])
+		}
+
+		lang, ok := native.LookupLanguage(ch.Language)
+		if !ok {
+			return fmt.Errorf("challenge '%s' uses unsupported language: %s", slug, ch.Language)
+		}
 
-		workDir, err := challenge.CreateWorkspace(ch)
+		workDir, err := challenge.CreateWorkspace(ch, lang.SolutionFile)
 		if err != nil {
 			return fmt.Errorf("failed to create workspace: %w", err)
 		}
@@ -41,5 +73,6 @@ test cases, and instructions.`,
 }
 
 func init() {
+	fetchCmd.Flags().StringP("language", "l", "", "Language of a challenge available in several languages")
 	rootCmd.AddCommand(fetchCmd)
 }
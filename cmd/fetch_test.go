@@ -1,9 +1,35 @@
 package cmd
 
 import (
+	"bytes"
+	"strings"
 	"testing"
 )
 
+func TestFetchNeedsLanguage(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	var out bytes.Buffer
+	rootCmd.SetOut(&out)
+	rootCmd.SetErr(&out)
+	rootCmd.SetArgs([]string{"fetch", "add-two-numbers"})
+	defer func() {
+		rootCmd.SetOut(nil)
+		rootCmd.SetErr(nil)
+		rootCmd.SetArgs(nil)
+		fetchCmd.SilenceUsage = false
+	}()
+
+	err := rootCmd.Execute()
+	if err == nil || !strings.Contains(err.Error(), "typescript, go, python, rust") || !strings.Contains(err.Error(), "--language") {
+		t.Fatalf("Expected an error listing the languages, got %v", err)
+	}
+	if strings.Contains(out.String(), "Usage:") {
+		t.Errorf("Expected no usage with the error, got:\n%s", out.String())
+	}
+}
+
 func TestValidateChallengeName(t *testing.T) {
 	tests := []struct {
 		name  string
@@ -2,8 +2,11 @@ package cmd
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/crisecheverria/codequest/internal/challenge"
+	"github.com/crisecheverria/codequest/internal/native"
+	"github.com/crisecheverria/codequest/internal/progress"
 	"github.com/spf13/cobra"
 )
 
@@ -21,14 +24,29 @@ var listCmd = &cobra.Command{
 		}
 
 		filtered := challenge.FilterChallenges(challenges, language, difficulty)
-		challenge.DisplayChallengeList(filtered)
+
+		var summary progress.Summary
+		if journal, err := progress.DefaultJournal(); err == nil {
+			events, err := journal.Events()
+			if err != nil {
+				return err
+			}
+			summary = progress.Summarize(progress.MigrateSlugs(events, challenges))
+		}
+		challenge.DisplayChallengeList(filtered, func(ch challenge.Challenge) string {
+			return string(summary.Status(ch))
+		})
 
 		return nil
 	},
 }
 
 func init() {
-	listCmd.Flags().StringP("language", "l", "", "Filter by language (typescript, javascript, go, python)")
+	var ids []string
+	for _, lang := range native.Languages() {
+		ids = append(ids, lang.ID)
+	}
+	listCmd.Flags().StringP("language", "l", "", fmt.Sprintf("Filter by language (%s)", strings.Join(ids, ", ")))
 	listCmd.Flags().StringP("difficulty", "d", "", "Filter by difficulty (easy, medium, hard)")
 	rootCmd.AddCommand(listCmd)
 }
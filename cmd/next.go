@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/crisecheverria/codequest/internal/challenge"
+	"github.com/crisecheverria/codequest/internal/progress"
+	"github.com/crisecheverria/codequest/internal/recommend"
+	"github.com/spf13/cobra"
+)
+
+var nextCmd = &cobra.Command{
+	Use:   "next",
+	Short: "Recommend the challenge to take on next",
+	Long: `Recommend an unsolved challenge based on the challenges you have solved,
+the concepts they cover and difficulty. Challenges whose prerequisite concepts
+you have practiced come first, easier before harder, and a challenge you have
+started comes before a new one.
+
+Without --language, the language of your most recent run is used.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		language, _ := cmd.Flags().GetString("language")
+		conceptSlug, _ := cmd.Flags().GetString("concept")
+
+		challenges, err := challenge.LoadChallenges()
+		if err != nil {
+			return fmt.Errorf("failed to load challenges: %w", err)
+		}
+		concepts, err := challenge.LoadConcepts()
+		if err != nil {
+			return fmt.Errorf("failed to load concepts: %w", err)
+		}
+		if conceptSlug != "" {
+			if _, ok := challenge.FindConcept(concepts, conceptSlug); !ok {
+				return fmt.Errorf("concept '%s' not found, run 'codequest concepts' to see them all", conceptSlug)
+			}
+		}
+
+		var events []progress.Event
+		if journal, err := progress.DefaultJournal(); err == nil {
+			if events, err = journal.Events(); err != nil {
+				return err
+			}
+			events = progress.MigrateSlugs(events, challenges)
+		}
+
+		// A concept can belong to a single language, so only default the
+		// language when the concept leaves the choice open
+		if language == "" && conceptSlug == "" && len(events) > 0 {
+			language = events[len(events)-1].Language
+			fmt.Printf("Continuing with %s, the language you practiced last. Use --language to pick another.\n\n", conceptLanguageName(language))
+		}
+
+		rec, ok := recommend.Next(challenges, concepts, progress.Summarize(events), recommend.Options{
+			Language: language,
+			Concept:  conceptSlug,
+		})
+		if !ok {
+			fmt.Println("🎉 You have solved every challenge matching the criteria.")
+			return nil
+		}
+
+		ch := rec.Challenge
+		fmt.Printf("Next challenge: %s (%s)\n", ch.Title, ch.Slug)
+		fmt.Printf("Language: %s · Difficulty: %s", conceptLanguageName(ch.Language), ch.Difficulty)
+		if len(ch.ConceptTags) > 0 {
+			fmt.Printf(" · Concepts: %s", strings.Join(ch.ConceptTags, ", "))
+		}
+		fmt.Println()
+
+		fmt.Println("\nWhy this one:")
+		for _, reason := range rec.Reasons {
+			fmt.Printf("  • %s\n", reason)
+		}
+
+		fetch := ch.Slug
+		if len(ch.Variants) > 0 {
+			fetch += " --language " + ch.Language
+		}
+		fmt.Printf("\nStart it with: codequest fetch %s\n", fetch)
+		return nil
+	},
+}
+
+func init() {
+	nextCmd.Flags().StringP("language", "l", "", "Recommend a challenge in this language")
+	nextCmd.Flags().String("concept", "", "Recommend a challenge that exercises this concept")
+	rootCmd.AddCommand(nextCmd)
+}
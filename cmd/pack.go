@@ -0,0 +1,141 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/crisecheverria/codequest/internal/challenge"
+	"github.com/crisecheverria/codequest/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var packCmd = &cobra.Command{
+	Use:   "pack",
+	Short: "Manage challenge packs",
+	Long: `Challenge packs add challenges to the built-in set without rebuilding
+codequest. A pack is a directory containing a challenges.json file, or a
+challenges file itself, in the same format as the built-in challenges.
+
+Packs are listed in the config file and in CODEQUEST_PATH, separated like
+PATH. A pack's challenges are named <pack>/<slug>, where the pack name is the
+directory name, or the file name without its extension.`,
+}
+
+var packListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the challenge packs in use",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		envPaths := filepath.SplitList(os.Getenv(challenge.PackEnv))
+		if len(appConfig.Packs) == 0 && len(envPaths) == 0 {
+			fmt.Println("No challenge packs. Add one with 'codequest pack add <path>'.")
+			return nil
+		}
+
+		fmt.Printf("%-20s %-12s %-15s %s\n", "NAME", "CHALLENGES", "SOURCE", "PATH")
+		printPacks(appConfig.Packs, "config")
+		printPacks(envPaths, challenge.PackEnv)
+		return nil
+	},
+}
+
+// printPacks prints a row for each pack in paths, reporting packs that fail
+// to load instead of their challenge count.
+func printPacks(paths []string, source string) {
+	for _, path := range paths {
+		if path == "" {
+			continue
+		}
+		count := "-"
+		pack, err := challenge.LoadPack(path)
+		if err == nil {
+			count = fmt.Sprint(len(pack.Challenges))
+		}
+		fmt.Printf("%-20s %-12s %-15s %s\n", challenge.TruncateString(challenge.PackName(path), 20), count, source, path)
+		if err != nil {
+			fmt.Printf("  ⚠️  %v\n", err)
+		}
+	}
+}
+
+var packAddCmd = &cobra.Command{
+	Use:   "add <path>",
+	Short: "Add a challenge pack to the config file",
+	Long: `Add a challenge pack to the config file, which is created if it doesn't
+exist yet.`,
+	Args:        cobra.ExactArgs(1),
+	Annotations: map[string]string{createsConfig: "true"},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if configPath == "" {
+			return fmt.Errorf("no config file to add the pack to, use --config")
+		}
+		path, err := filepath.Abs(args[0])
+		if err != nil {
+			return err
+		}
+
+		for _, existing := range appConfig.Packs {
+			if existing == path {
+				fmt.Printf("Pack %s is already added\n", path)
+				return nil
+			}
+		}
+
+		// Load every pack to catch a broken pack or a name already in use
+		packs := append(append([]string{}, appConfig.Packs...), path)
+		loaded, err := challenge.LoadPacks(append(packs, filepath.SplitList(os.Getenv(challenge.PackEnv))...))
+		if err != nil {
+			return err
+		}
+
+		if err := config.SetPacks(configPath, packs); err != nil {
+			return err
+		}
+		for _, pack := range loaded {
+			if pack.Path == path {
+				fmt.Printf("✅ Added pack '%s' with %s\n", pack.Name, challengeCount(len(pack.Challenges)))
+			}
+		}
+		return nil
+	},
+}
+
+var packRemoveCmd = &cobra.Command{
+	Use:   "remove <name|path>",
+	Short: "Remove a challenge pack from the config file",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		target := args[0]
+		abs, _ := filepath.Abs(target)
+
+		var packs []string
+		removed := false
+		for _, path := range appConfig.Packs {
+			if path == abs || challenge.PackName(path) == target {
+				removed = true
+				continue
+			}
+			packs = append(packs, path)
+		}
+		if !removed {
+			for _, path := range filepath.SplitList(os.Getenv(challenge.PackEnv)) {
+				if envAbs, _ := filepath.Abs(path); path != "" && (envAbs == abs || challenge.PackName(path) == target) {
+					return fmt.Errorf("pack '%s' comes from %s, remove it there", target, challenge.PackEnv)
+				}
+			}
+			return fmt.Errorf("pack '%s' not found in %s", target, configPath)
+		}
+
+		if err := config.SetPacks(configPath, packs); err != nil {
+			return err
+		}
+		fmt.Printf("🗑️  Removed pack '%s'\n", target)
+		return nil
+	},
+}
+
+func init() {
+	packCmd.AddCommand(packListCmd, packAddCmd, packRemoveCmd)
+	rootCmd.AddCommand(packCmd)
+}
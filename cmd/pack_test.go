@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/crisecheverria/codequest/internal/config"
+)
+
+func TestPackAddCreatesConfig(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("CODEQUEST_PATH", "")
+
+	dir := t.TempDir()
+	packDir := filepath.Join(dir, "team")
+	if err := os.MkdirAll(packDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	pack := `[{"title": "Two Sum", "slug": "two-sum", "language": "go", "difficulty": "easy", "functionName": "twoSum"}]`
+	if err := os.WriteFile(filepath.Join(packDir, "challenges.json"), []byte(pack), 0644); err != nil {
+		t.Fatal(err)
+	}
+	configFile := filepath.Join(dir, "new-config.json")
+
+	rootCmd.SetArgs([]string{"pack", "add", packDir, "--config", configFile})
+	defer func() {
+		rootCmd.SetArgs(nil)
+		rootCmd.PersistentFlags().Set("config", "")
+		appConfig, configPath = &config.Config{}, ""
+	}()
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("pack add with a new config file failed: %v", err)
+	}
+
+	cfg, err := config.Load(configFile)
+	if err != nil {
+		t.Fatalf("Failed to load the created config: %v", err)
+	}
+	if len(cfg.Packs) != 1 || cfg.Packs[0] != packDir {
+		t.Errorf("Expected the pack in the config, got %v", cfg.Packs)
+	}
+}
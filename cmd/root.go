@@ -2,7 +2,12 @@ package cmd
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
 
+	"github.com/crisecheverria/codequest/internal/challenge"
+	"github.com/crisecheverria/codequest/internal/config"
+	"github.com/crisecheverria/codequest/internal/native"
 	"github.com/spf13/cobra"
 )
 
@@ -18,18 +23,76 @@ directly from your terminal with native language execution.
 
 Examples:
   codequest list                    # List available challenges
+  codequest next                    # Get a recommended challenge
   codequest fetch two-sum           # Fetch a specific challenge
-  codequest test                    # Test your solution locally`,
+  codequest test                    # Test your solution locally
+  codequest submit                  # Record and send your solution
+  codequest pack add ./team-pack    # Add a challenge pack
+  codequest validate ./team-pack    # Check a challenge pack for mistakes`,
+	// main prints the error that a command returns
+	SilenceErrors: true,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		return loadConfig(cmd)
+	},
 	Run: func(cmd *cobra.Command, args []string) {
 		fmt.Println("Welcome to CodeQuest CLI!")
 		fmt.Println("Use 'codequest --help' to see available commands.")
 	},
 }
 
+// appConfig is the loaded configuration file, empty if there is none.
+var appConfig = &config.Config{}
+
+// configPath is the config file in use, which may not exist.
+var configPath string
+
+// createsConfig annotates a command that writes the config file, so that
+// the file it is given with --config doesn't have to exist yet.
+const createsConfig = "createsConfig"
+
+// loadConfig registers the user-defined executors from the config file and
+// the challenge packs from the config file and CODEQUEST_PATH. A missing
+// default config file is not an error, and neither is a missing --config
+// file for a command that creates it.
+func loadConfig(cmd *cobra.Command) error {
+	path, _ := cmd.Flags().GetString("config")
+	explicit := path != ""
+	if !explicit {
+		defaultPath, err := config.DefaultPath()
+		if err != nil {
+			registerPacks()
+			return nil
+		}
+		path = defaultPath
+	}
+	configPath = path
+
+	cfg, err := config.Load(path)
+	if err != nil {
+		if os.IsNotExist(err) && (!explicit || cmd.Annotations[createsConfig] != "") {
+			registerPacks()
+			return nil
+		}
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	appConfig = cfg
+	registerPacks()
+	return native.RegisterConfig(cfg)
+}
+
+// registerPacks registers the packs from the config file followed by those
+// in CODEQUEST_PATH.
+func registerPacks() {
+	paths := append([]string{}, appConfig.Packs...)
+	paths = append(paths, filepath.SplitList(os.Getenv(challenge.PackEnv))...)
+	challenge.RegisterPacks(paths)
+}
+
 func Execute() error {
 	return rootCmd.Execute()
 }
 
 func init() {
-	rootCmd.PersistentFlags().StringP("config", "c", "", "config file (default is $HOME/.codequest.yaml)")
+	rootCmd.PersistentFlags().StringP("config", "c", "", "config file (default is $HOME/.codequest.json)")
 }
@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/crisecheverria/codequest/internal/challenge"
+	"github.com/crisecheverria/codequest/internal/progress"
+	"github.com/spf13/cobra"
+)
+
+var statsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show your progress across challenges",
+	Long: `Show how many challenges you have solved and attempted by language,
+difficulty and concept, your daily practice streak, how often you solve a
+challenge on the first try and how many runs a solution takes on average.
+
+Progress is recorded by every 'codequest test' and 'codequest submit'.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		challenges, err := challenge.LoadChallenges()
+		if err != nil {
+			return fmt.Errorf("failed to load challenges: %w", err)
+		}
+
+		journal, err := progress.DefaultJournal()
+		if err != nil {
+			return err
+		}
+		events, err := journal.Events()
+		if err != nil {
+			return err
+		}
+
+		printStats(challenges, progress.MigrateSlugs(events, challenges), time.Now())
+		return nil
+	},
+}
+
+// printStats prints the progress recorded in events on challenges as of now.
+func printStats(challenges []challenge.Challenge, events []progress.Event, now time.Time) {
+	summary := progress.Summarize(events)
+	totals := summary.Totals(challenges)
+
+	fmt.Printf("Solved %d of %d challenges, %d attempted\n", totals.Solved, totals.Total, totals.Attempted)
+	if len(events) == 0 {
+		fmt.Println("\nNo runs recorded yet. Fetch a challenge and run 'codequest test' to get started.")
+		return
+	}
+
+	current, longest := progress.Streaks(events, now)
+	fmt.Printf("Current streak: %s (longest %s)\n", days(current), days(longest))
+	if firstTry, solved := summary.FirstTryRate(); solved > 0 {
+		fmt.Printf("First-try pass rate: %.0f%% (%d of %d)\n", 100*float64(firstTry)/float64(solved), firstTry, solved)
+		fmt.Printf("Average attempts to solve: %.1f\n", summary.AverageAttempts())
+	}
+
+	printTallies("LANGUAGE", summary.GroupBy(challenges, func(ch challenge.Challenge) []string {
+		return []string{ch.Language}
+	}), nil, false)
+	printTallies("DIFFICULTY", summary.GroupBy(challenges, func(ch challenge.Challenge) []string {
+		return []string{ch.Difficulty}
+	}), challenge.Difficulties, false)
+	printTallies("CONCEPT", summary.GroupBy(challenges, func(ch challenge.Challenge) []string {
+		return ch.ConceptTags
+	}), nil, true)
+}
+
+// printTallies prints one row per group, those in order first and the rest
+// sorted by name. With startedOnly, groups without a solved or attempted
+// challenge are left out.
+func printTallies(title string, tallies map[string]*progress.Tally, order []string, startedOnly bool) {
+	var groups []string
+	seen := map[string]bool{}
+	for _, group := range order {
+		if _, ok := tallies[group]; ok {
+			groups = append(groups, group)
+			seen[group] = true
+		}
+	}
+	var rest []string
+	for group := range tallies {
+		if !seen[group] {
+			rest = append(rest, group)
+		}
+	}
+	sort.Strings(rest)
+	groups = append(groups, rest...)
+
+	fmt.Printf("\n%-30s %8s %10s %8s\n", title, "SOLVED", "ATTEMPTED", "TOTAL")
+	for _, group := range groups {
+		tally := tallies[group]
+		if startedOnly && tally.Solved == 0 && tally.Attempted == 0 {
+			continue
+		}
+		fmt.Printf("%-30s %8d %10d %8d\n", challenge.TruncateString(group, 30), tally.Solved, tally.Attempted, tally.Total)
+	}
+}
+
+func days(n int) string {
+	if n == 1 {
+		return "1 day"
+	}
+	return fmt.Sprintf("%d days", n)
+}
+
+func init() {
+	rootCmd.AddCommand(statsCmd)
+}
@@ -0,0 +1,171 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"time"
+
+	"github.com/crisecheverria/codequest/internal/challenge"
+	"github.com/crisecheverria/codequest/internal/config"
+	"github.com/crisecheverria/codequest/internal/harness"
+	"github.com/crisecheverria/codequest/internal/native"
+	"github.com/crisecheverria/codequest/internal/progress"
+	"github.com/crisecheverria/codequest/internal/submission"
+	"github.com/spf13/cobra"
+)
+
+// submitTimeout bounds posting a submission to the endpoint.
+const submitTimeout = 30 * time.Second
+
+var submitCmd = &cobra.Command{
+	Use:   "submit",
+	Short: "Run every test case and record a signed submission",
+	Long: `Run your solution against every test case of the challenge and record the
+result as a signed submission in the codequest config directory.
+
+If a submission endpoint is set in the config file or with --endpoint, the
+submission is also posted to it. The token in the config file is only sent
+to the endpoint configured with it; give another endpoint its token with
+--token.
+
+A submission that fails a test case is still recorded and posted, and
+submit exits with a non-zero status.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		ch, solutionCode, err := loadWorkspace()
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Submitting solution for '%s'...\n\n", ch.Title)
+
+		sandbox, _ := cmd.Flags().GetBool("sandbox")
+		result, err := runSolution(ch, string(solutionCode), ch.TestCases, sandbox, false)
+		if err != nil {
+			return fmt.Errorf("failed to run solution: %w", err)
+		}
+		reportResults(ch.TestCases, nil, result)
+
+		record := newRecord(ch, solutionCode, result, time.Now())
+		dir, err := config.Dir()
+		if err != nil {
+			return err
+		}
+		key, err := submission.LoadOrCreateKey(filepath.Join(dir, "submit.key"))
+		if err != nil {
+			return err
+		}
+		envelope, err := submission.Sign(record, key)
+		if err != nil {
+			return err
+		}
+		path, err := submission.Save(filepath.Join(dir, "submissions"), envelope)
+		if err != nil {
+			return err
+		}
+
+		passed := countPassed(ch.TestCases, result)
+		recordProgress(progress.KindSubmit, ch, ch.TestCases, result)
+		fmt.Printf("📝 Submission recorded: %d/%d tests passed\n", passed, len(record.Tests))
+		fmt.Printf("   %s\n", path)
+
+		endpointFlag, _ := cmd.Flags().GetString("endpoint")
+		tokenFlag, _ := cmd.Flags().GetString("token")
+		endpoint, token := submitTarget(appConfig.Submit, endpointFlag, tokenFlag)
+		if local, _ := cmd.Flags().GetBool("local"); endpoint != "" && !local {
+			ctx, cancel := context.WithTimeout(context.Background(), submitTimeout)
+			defer cancel()
+			response, err := submission.Post(ctx, http.DefaultClient, endpoint, token, envelope)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("📤 Submitted to %s", endpoint)
+			if response.ID != "" {
+				fmt.Printf(" (id %s)", response.ID)
+			}
+			fmt.Println()
+			if response.Message != "" {
+				fmt.Printf("   %s\n", response.Message)
+			}
+		}
+		fmt.Println()
+
+		if !record.Passed {
+			fmt.Println("❌ Some tests failed. Please fix your solution and submit again.")
+			return testsFailed(passed, len(record.Tests))
+		}
+		fmt.Println("🎉 All tests passed!")
+		return nil
+	},
+}
+
+// submitTarget returns the endpoint to post a submission to and the token to
+// send with it. The token in cfg is only sent to the endpoint it is
+// configured for, so an endpoint given with --endpoint gets the --token
+// one instead.
+func submitTarget(cfg config.Submit, endpointFlag, tokenFlag string) (string, string) {
+	endpoint, token := cfg.Endpoint, cfg.Token
+	if endpointFlag != "" && endpointFlag != cfg.Endpoint {
+		endpoint, token = endpointFlag, ""
+	}
+	if tokenFlag != "" {
+		token = tokenFlag
+	}
+	return endpoint, token
+}
+
+// newRecord describes the run of solutionCode against ch's test cases that
+// produced result.
+func newRecord(ch challenge.Challenge, solutionCode []byte, result *native.ExecutionResult, submittedAt time.Time) submission.Record {
+	record := submission.Record{
+		Slug:           ch.Slug,
+		Language:       ch.Language,
+		SolutionSHA256: submission.HashSolution(solutionCode),
+		SubmittedAt:    submittedAt.UTC(),
+		ClientVersion:  Version,
+		Passed:         result.Success,
+		Status:         string(result.Status),
+		CompileTimeMs:  float64(result.CompileTime) / float64(time.Millisecond),
+		RunTimeMs:      float64(result.RunTime) / float64(time.Millisecond),
+		PeakMemory:     result.PeakMemory,
+		Tests:          make([]submission.TestResult, len(ch.TestCases)),
+	}
+
+	caseResults, _ := harness.ParseResults(result.Output)
+	byIndex := make(map[int]harness.CaseResult, len(caseResults))
+	for _, caseResult := range caseResults {
+		byIndex[caseResult.Index] = caseResult
+	}
+
+	for i := range ch.TestCases {
+		// The error of a hidden case can give away its input
+		test := submission.TestResult{Index: i, Hidden: ch.TestCases[i].Hidden}
+		if caseResult, ok := byIndex[i]; ok {
+			test.Passed = caseResult.Passed
+			test.DurationMs = caseResult.DurationMs
+			if !test.Hidden {
+				test.Error = caseResult.Error
+			}
+		} else if result.Status != native.StatusOK && result.Status != native.StatusRuntimeError {
+			test.Error = string(result.Status)
+		} else {
+			test.Error = "no result reported"
+		}
+		if !test.Passed {
+			record.Passed = false
+		}
+		record.Tests[i] = test
+	}
+
+	return record
+}
+
+func init() {
+	submitCmd.Flags().String("endpoint", "", "URL to post the submission to, overriding the config file")
+	submitCmd.Flags().String("token", "", "Bearer token to send to the endpoint, overriding the config file")
+	submitCmd.Flags().Bool("local", false, "Only record the submission locally")
+	submitCmd.Flags().Bool("sandbox", false, "Run the solution in Linux namespaces without network access and with a read-only filesystem")
+	rootCmd.AddCommand(submitCmd)
+}
@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/crisecheverria/codequest/internal/challenge"
+	"github.com/crisecheverria/codequest/internal/config"
+	"github.com/crisecheverria/codequest/internal/harness"
+	"github.com/crisecheverria/codequest/internal/native"
+)
+
+func TestNewRecord(t *testing.T) {
+	ch := challenge.Challenge{
+		Slug:     "double",
+		Language: "python",
+		TestCases: []challenge.TestCase{
+			{Input: []interface{}{float64(1)}, Expected: float64(2)},
+			{Input: []interface{}{float64(2)}, Expected: float64(4)},
+		},
+	}
+
+	result := &native.ExecutionResult{
+		Success:     true,
+		Status:      native.StatusOK,
+		CompileTime: 2 * time.Millisecond,
+		RunTime:     30 * time.Millisecond,
+		Output: harness.ResultPrefix + `{"index":0,"passed":true,"durationMs":1}` + "\n" +
+			harness.ResultPrefix + `{"index":1,"passed":true,"durationMs":2}` + "\n",
+	}
+	record := newRecord(ch, []byte("def double(x): return 2 * x"), result, time.Now())
+	if !record.Passed || len(record.Tests) != 2 || record.Tests[1].DurationMs != 2 || record.RunTimeMs != 30 {
+		t.Errorf("Unexpected record: %+v", record)
+	}
+	if len(record.SolutionSHA256) != 64 {
+		t.Errorf("Expected a SHA-256 hex digest, got %q", record.SolutionSHA256)
+	}
+
+	result = &native.ExecutionResult{
+		Success: false,
+		Status:  native.StatusTimeLimitExceeded,
+		Output:  harness.ResultPrefix + `{"index":0,"passed":true,"durationMs":1}` + "\n",
+	}
+	record = newRecord(ch, nil, result, time.Now())
+	if record.Passed || !record.Tests[0].Passed || record.Tests[1].Error != string(native.StatusTimeLimitExceeded) {
+		t.Errorf("Unexpected record: %+v", record)
+	}
+
+	// The error of a hidden case is left out
+	ch.TestCases[1].Hidden = true
+	result = &native.ExecutionResult{
+		Status: native.StatusRuntimeError,
+		Output: harness.ResultPrefix + `{"index":0,"passed":true,"durationMs":1}` + "\n" +
+			harness.ResultPrefix + `{"index":1,"passed":false,"durationMs":1,"error":"bad input 2"}` + "\n",
+	}
+	record = newRecord(ch, nil, result, time.Now())
+	if record.Passed || !record.Tests[1].Hidden || record.Tests[1].Error != "" {
+		t.Errorf("Unexpected hidden test result: %+v", record.Tests[1])
+	}
+}
+
+func TestSubmitTarget(t *testing.T) {
+	cfg := config.Submit{Endpoint: "https://example.com/submit", Token: "secret"}
+
+	tests := []struct {
+		name                    string
+		endpointFlag, tokenFlag string
+		endpoint, token         string
+	}{
+		{"config", "", "", "https://example.com/submit", "secret"},
+		{"same endpoint", "https://example.com/submit", "", "https://example.com/submit", "secret"},
+		{"other endpoint", "https://other.example.com", "", "https://other.example.com", ""},
+		{"other endpoint with token", "https://other.example.com", "mine", "https://other.example.com", "mine"},
+		{"token", "", "mine", "https://example.com/submit", "mine"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			endpoint, token := submitTarget(cfg, tt.endpointFlag, tt.tokenFlag)
+			if endpoint != tt.endpoint || token != tt.token {
+				t.Errorf("submitTarget() = %q, %q, want %q, %q", endpoint, token, tt.endpoint, tt.token)
+			}
+		})
+	}
+}
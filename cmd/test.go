@@ -4,10 +4,16 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/crisecheverria/codequest/internal/challenge"
+	"github.com/crisecheverria/codequest/internal/diff"
+	"github.com/crisecheverria/codequest/internal/harness"
 	"github.com/crisecheverria/codequest/internal/native"
+	"github.com/crisecheverria/codequest/internal/progress"
+	"github.com/crisecheverria/codequest/internal/report"
 	"github.com/spf13/cobra"
 )
 
@@ -16,378 +22,537 @@ type ChallengeMetadata struct {
 	Language     string `json:"language"`
 	FunctionName string `json:"functionName"`
 	SolutionFile string `json:"solutionFile"`
+	// FailedCases are the positions in the challenge's test cases of those
+	// that failed the last time they ran, which test --failed runs again
+	FailedCases []int `json:"failedCases,omitempty"`
+	// CasesFingerprint identifies the test cases, including the local
+	// ones, that FailedCases refers to
+	CasesFingerprint string `json:"casesFingerprint,omitempty"`
 }
 
 var testCmd = &cobra.Command{
 	Use:   "test",
 	Short: "Test your solution against the challenge test cases",
 	Long: `Execute your solution locally using native language runtimes and validate 
-against the challenge test cases. Requires Go and/or Node.js to be installed 
-depending on the challenge language.`,
+against the challenge test cases. Requires the runtime for the challenge
+language (Go, Node.js, a TypeScript toolchain, Python, PHP or rustc) to be installed.
+
+Only the sample test cases shown in the README run, unless --all is set. With
+--all, hidden test cases run too and only whether they pass is shown.
+
+With --sandbox the solution runs isolated from the network and the rest of
+the system using Linux namespaces, if the system supports them.
+
+--format json, tap or junit prints the results for tools instead: each
+case's status, duration, expected and actual values, output and why it
+failed. The command exits with a non-zero status when any test fails.
+
+With --watch the tests run again every time the solution file is saved, and
+a short summary of the results replaces the previous one.
+
+--case runs only some sample test cases, by the numbers the README gives
+them, and --match only those whose description matches a regular
+expression. --failed runs again the cases that failed the last time they
+ran, and --fail-fast stops at the first case that fails.
+
+Test cases added to tests.local.json in the workspace run along with the
+challenge's own, labeled as local, and alone with --only-local. They don't
+count towards solving the challenge.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		// Look for challenge metadata
-		metadataPath := ".challenge.json"
-		if _, err := os.Stat(metadataPath); os.IsNotExist(err) {
-			return fmt.Errorf("not in a challenge directory. Run 'codequest fetch <challenge>' first")
+		format, _ := cmd.Flags().GetString("format")
+		if format != "text" && !validFormat(format) {
+			return fmt.Errorf("unknown format %q, expected text, %s", format, strings.Join(report.Formats, ", "))
+		}
+		watchMode, _ := cmd.Flags().GetBool("watch")
+		if watchMode && format != "text" {
+			return fmt.Errorf("--watch only works with the text format")
+		}
+		cmd.SilenceUsage = true
+
+		all, _ := cmd.Flags().GetBool("all")
+		onlyFailed, _ := cmd.Flags().GetBool("failed")
+		failFast, _ := cmd.Flags().GetBool("fail-fast")
+		var selection caseSelection
+		if specs, _ := cmd.Flags().GetStringSlice("case"); len(specs) > 0 {
+			ranges, err := parseCaseRanges(specs)
+			if err != nil {
+				return err
+			}
+			selection.ranges = ranges
+		}
+		if pattern, _ := cmd.Flags().GetString("match"); pattern != "" {
+			selection.match = descriptionPattern(pattern)
+		}
+		selection.onlyLocal, _ = cmd.Flags().GetBool("only-local")
+		if all && (onlyFailed || !selection.empty()) {
+			return fmt.Errorf("--all can't be combined with --case, --match, --failed or --only-local")
+		}
+		if selection.onlyLocal && selection.ranges != nil {
+			return fmt.Errorf("--case picks the challenge's sample test cases and can't be combined with --only-local")
 		}
 
-		// Load metadata
-		metadata, err := loadChallengeMetadata(metadataPath)
+		ch, solutionCode, err := loadWorkspace()
+		if err != nil {
+			return err
+		}
+		metadata, err := loadChallengeMetadata(".challenge.json")
 		if err != nil {
 			return fmt.Errorf("failed to load challenge metadata: %w", err)
 		}
-
-		// Load the challenge from data
-		challenges, err := challenge.LoadChallenges()
+		local, err := challenge.LoadLocalTestCases(challenge.LocalTestsFile, ch)
 		if err != nil {
-			return fmt.Errorf("failed to load challenges: %w", err)
+			return fmt.Errorf("invalid local test cases:\n%w", err)
 		}
-
-		ch, found := challenge.FindBySlug(challenges, metadata.Slug)
-		if !found {
-			return fmt.Errorf("challenge '%s' not found", metadata.Slug)
+		if selection.onlyLocal && len(local) == 0 {
+			return fmt.Errorf("no local test cases, add some to %s", challenge.LocalTestsFile)
 		}
 
-		// Read solution file
-		solutionCode, err := os.ReadFile(metadata.SolutionFile)
-		if err != nil {
-			return fmt.Errorf("failed to read solution file '%s': %w", metadata.SolutionFile, err)
+		if onlyFailed {
+			if len(metadata.FailedCases) > 0 && metadata.CasesFingerprint != casesFingerprint(ch, local) {
+				fmt.Println("The test cases changed since they last ran. Run them all again to find the failed ones.")
+				return nil
+			}
+			if len(metadata.FailedCases) == 0 {
+				fmt.Println("No test cases failed the last time they ran.")
+				return nil
+			}
+			selection.failed = map[int]bool{}
+			for _, position := range metadata.FailedCases {
+				selection.failed[position] = true
+			}
+		}
+		run := selectCases(ch, local, all, selection)
+		if len(run.cases) == 0 {
+			return fmt.Errorf("no test cases match the selection")
 		}
 
-		// Create native executor
-		executor, err := native.NewExecutor()
-		if err != nil {
-			return fmt.Errorf("failed to create native executor: %w", err)
+		sandbox, _ := cmd.Flags().GetBool("sandbox")
+		if watchMode {
+			return watchTests(ch, metadata, run, sandbox, failFast)
 		}
-		defer executor.Close()
 
 		// Test the solution
-		fmt.Printf("Testing solution for '%s'...\n\n", ch.Title)
+		if format == "text" {
+			fmt.Printf("Testing solution for '%s'...\n", ch.Title)
+			if onlyFailed || !selection.empty() {
+				fmt.Printf("Running %d of the %d test cases.\n", len(run.cases), len(ch.TestCases)+len(local))
+			} else if hidden := len(ch.TestCases) + len(local) - len(run.cases); hidden > 0 {
+				fmt.Printf("Running the sample test cases; %d hidden ones run with --all and on submit.\n", hidden)
+			}
+			if len(local) > 0 && !selection.onlyLocal && selection.empty() {
+				fmt.Printf("Including %d local test cases from %s.\n", len(local), challenge.LocalTestsFile)
+			}
+			fmt.Println()
+		}
 
-		success := true
-		for i, testCase := range ch.TestCases {
-			fmt.Printf("Test %d: %s\n", i+1, testCase.Description)
+		result, err := runSolution(ch, string(solutionCode), run.cases, sandbox, failFast)
+		if err != nil {
+			return fmt.Errorf("execution error: %w", err)
+		}
 
-			// Create test code that calls the function with test inputs
-			testCode := generateTestCode(ch, string(solutionCode), testCase)
+		reached := run.reached(result)
+		passed := countPassed(reached.cases, result)
+		// Only a run of the whole set counts as an attempt; picking a few
+		// cases to debug would inflate the attempt counts in stats
+		if !onlyFailed && selection.empty() {
+			recordProgress(progress.KindTest, ch, reached.cases, result)
+		}
+		rememberFailed(metadata, reached, result)
 
-			// Use longer timeout for Go due to compilation overhead
-			timeout := ch.TimeLimit
-			if ch.Language == "go" {
-				timeout = 15000 // 15 seconds for Go compilation + execution
+		if format != "text" {
+			r := report.New(ch, reached.cases, result)
+			if err := report.Write(os.Stdout, format, r); err != nil {
+				return err
 			}
-			result, err := executor.ExecuteCode(ch.Language, testCode, timeout)
-			if err != nil {
-				fmt.Printf("  ❌ Execution error: %v\n\n", err)
-				success = false
-				continue
+			if !r.Passed {
+				return testsFailed(passed, len(reached.cases))
 			}
-
-			if result.Success {
-				fmt.Printf("  ✅ Passed (%.2fms)\n", float64(result.Duration.Nanoseconds())/1e6)
-			} else {
-				fmt.Printf("  ❌ Failed\n")
-				if result.Error != "" {
-					fmt.Printf("     Error: %s\n", result.Error)
-				}
-				if result.Output != "" {
-					fmt.Printf("     Output: %s\n", result.Output)
-				}
-				success = false
-			}
-			fmt.Println()
+			return nil
 		}
 
+		success := reportResults(reached.cases, reached.numbers, result)
+		if skipped := len(run.cases) - len(reached.cases); skipped > 0 {
+			fmt.Printf("⏭️  Stopped at the first failure; %d more test cases did not run.\n\n", skipped)
+		}
 		if success {
 			fmt.Println("🎉 All tests passed! Run 'codequest submit' to submit your solution.")
-		} else {
-			fmt.Println("❌ Some tests failed. Please fix your solution and try again.")
+			return nil
 		}
-
-		return nil
+		fmt.Println("❌ Some tests failed. Please fix your solution and try again.")
+		return testsFailed(passed, len(reached.cases))
 	},
 }
 
-func loadChallengeMetadata(path string) (*ChallengeMetadata, error) {
-	data, err := os.ReadFile(path)
-	if err != nil {
-		return nil, err
-	}
-
-	var metadata ChallengeMetadata
-	if err := json.Unmarshal(data, &metadata); err != nil {
-		return nil, err
+// validFormat reports whether format is one of the report formats.
+func validFormat(format string) bool {
+	for _, f := range report.Formats {
+		if f == format {
+			return true
+		}
 	}
+	return false
+}
 
-	return &metadata, nil
+// testsFailed is the error of a run that didn't pass, which makes the
+// process exit with a non-zero status.
+func testsFailed(passed, total int) error {
+	return fmt.Errorf("tests failed: %d of %d passed", passed, total)
 }
 
-func generateTestCode(ch challenge.Challenge, solutionCode string, testCase challenge.TestCase) string {
-	switch ch.Language {
-	case "typescript":
-		return generateTypeScriptTestCode(ch, solutionCode, testCase)
-	case "javascript":
-		return generateJavaScriptTestCode(ch, solutionCode, testCase)
-	case "php":
-		return generatePHPTestCode(ch, solutionCode, testCase)
-	case "go":
-		return generateGoTestCode(ch, solutionCode, testCase)
-	default:
-		return solutionCode
+// countPassed returns the number of test cases result reports as passed.
+func countPassed(testCases []challenge.TestCase, result *native.ExecutionResult) int {
+	caseResults, _ := harness.ParseResults(result.Output)
+	passed := map[int]bool{}
+	for _, caseResult := range caseResults {
+		if caseResult.Passed && caseResult.Index >= 0 && caseResult.Index < len(testCases) {
+			passed[caseResult.Index] = true
+		}
 	}
+	return len(passed)
 }
 
-func generateTypeScriptTestCode(ch challenge.Challenge, solutionCode string, testCase challenge.TestCase) string {
-	// Convert TypeScript function to JavaScript by removing type annotations
-	jsCode := strings.ReplaceAll(solutionCode, ": number", "")
-	jsCode = strings.ReplaceAll(jsCode, ": string", "")
-	jsCode = strings.ReplaceAll(jsCode, ": boolean", "")
-
-	// Convert input array to function arguments
-	args := make([]string, len(testCase.Input))
-	for i, input := range testCase.Input {
-		switch v := input.(type) {
-		case string:
-			args[i] = fmt.Sprintf(`"%s"`, v)
-		case float64:
-			if v == float64(int(v)) {
-				args[i] = fmt.Sprintf("%d", int(v))
-			} else {
-				args[i] = fmt.Sprintf("%f", v)
-			}
+// recordProgress adds a run of ch against testCases to the progress
+// journal. Only a run of every test case, hidden ones included, can solve
+// the challenge. Local test cases are left out, and a local case that
+// failed doesn't keep the challenge from being solved. A run that can't be
+// recorded only prints a warning.
+func recordProgress(kind progress.Kind, ch challenge.Challenge, testCases []challenge.TestCase, result *native.ExecutionResult) {
+	caseResults, _ := harness.ParseResults(result.Output)
+	passed := map[int]bool{}
+	for _, caseResult := range caseResults {
+		passed[caseResult.Index] = caseResult.Passed
+	}
+	total, testsPassed, localFailed := 0, 0, false
+	for i, testCase := range testCases {
+		switch {
+		case testCase.Local:
+			localFailed = localFailed || !passed[i]
+		case passed[i]:
+			total++
+			testsPassed++
 		default:
-			args[i] = fmt.Sprintf("%v", v)
+			total++
 		}
 	}
+	if total == 0 {
+		return
+	}
 
-	expectedStr := fmt.Sprintf("%v", testCase.Expected)
-	if _, ok := testCase.Expected.(string); ok {
-		expectedStr = fmt.Sprintf(`"%v"`, testCase.Expected)
+	event := progress.Event{
+		Kind:        kind,
+		Slug:        ch.Slug,
+		Language:    ch.Language,
+		Time:        time.Now().UTC(),
+		Passed:      (result.Success || localFailed) && testsPassed == total && total == len(ch.TestCases),
+		Status:      string(result.Status),
+		TestsPassed: testsPassed,
+		TestsTotal:  total,
+	}
+	journal, err := progress.DefaultJournal()
+	if err == nil {
+		err = journal.Append(event)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️  Failed to record progress: %v\n", err)
+	}
+}
+
+// loadWorkspace loads the challenge fetched into the current directory and
+// the solution written for it.
+func loadWorkspace() (challenge.Challenge, []byte, error) {
+	// Look for challenge metadata
+	metadataPath := ".challenge.json"
+	if _, err := os.Stat(metadataPath); os.IsNotExist(err) {
+		return challenge.Challenge{}, nil, fmt.Errorf("not in a challenge directory. Run 'codequest fetch <challenge>' first")
 	}
 
-	argsStr := strings.Join(args, ", ")
+	// Load metadata
+	metadata, err := loadChallengeMetadata(metadataPath)
+	if err != nil {
+		return challenge.Challenge{}, nil, fmt.Errorf("failed to load challenge metadata: %w", err)
+	}
+
+	// Load the challenge from data
+	challenges, err := challenge.LoadChallenges()
+	if err != nil {
+		return challenge.Challenge{}, nil, fmt.Errorf("failed to load challenges: %w", err)
+	}
 
-	return fmt.Sprintf(`%s
+	ch, found := challenge.FindVariant(challenges, metadata.Slug, metadata.Language)
+	if !found {
+		return challenge.Challenge{}, nil, fmt.Errorf("challenge '%s' not found", metadata.Slug)
+	}
 
-const result = %s(%s);
-const expected = %s;
+	// Read solution file
+	solutionCode, err := os.ReadFile(metadata.SolutionFile)
+	if err != nil {
+		return challenge.Challenge{}, nil, fmt.Errorf("failed to read solution file '%s': %w", metadata.SolutionFile, err)
+	}
 
-if (JSON.stringify(result) === JSON.stringify(expected)) {
-  console.log("Test passed");
-  process.exit(0);
-} else {
-  console.log("Expected:", expected, "Got:", result);
-  process.exit(1);
-}`, jsCode, ch.FunctionName, argsStr, expectedStr)
+	return ch, solutionCode, nil
 }
 
-func generateJavaScriptTestCode(ch challenge.Challenge, solutionCode string, testCase challenge.TestCase) string {
-	return generateTypeScriptTestCode(ch, solutionCode, testCase) // Same logic for JS
+// runSolution runs solutionCode against testCases of ch under the
+// challenge's limits, in the sandbox if requested and available. With
+// failFast the program stops at the first failed case.
+func runSolution(ch challenge.Challenge, solutionCode string, testCases []challenge.TestCase, sandbox, failFast bool) (*native.ExecutionResult, error) {
+	// Create native executor
+	executor, err := native.NewExecutor()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create native executor: %w", err)
+	}
+	defer executor.Close()
+
+	limits, err := solutionLimits(ch, sandbox)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️  Sandbox unavailable, running without isolation: %v\n\n", err)
+	}
+	limits.FailFast = failFast
+	return runWith(executor, ch, solutionCode, testCases, limits)
 }
 
-func generatePHPTestCode(ch challenge.Challenge, solutionCode string, testCase challenge.TestCase) string {
-	args := make([]string, len(testCase.Input))
-	for i, input := range testCase.Input {
-		switch v := input.(type) {
-		case string:
-			args[i] = fmt.Sprintf(`"%s"`, v)
-		case float64:
-			args[i] = fmt.Sprintf("%v", v)
-		default:
-			args[i] = fmt.Sprintf("%v", v)
+// solutionLimits returns the limits to run solutions of ch under, in the
+// sandbox if requested. If the sandbox is unavailable, the limits are
+// returned without it along with the reason.
+func solutionLimits(ch challenge.Challenge, sandbox bool) (native.Limits, error) {
+	limits := native.LimitsFor(ch)
+	if sandbox {
+		if err := native.CheckSandbox(); err != nil {
+			return limits, err
 		}
+		limits.Sandbox = true
 	}
+	return limits, nil
+}
 
-	argsStr := strings.Join(args, ", ")
-
-	return fmt.Sprintf(`<?php
-%s
+// runWith runs solutionCode against testCases of ch with executor, which
+// can be reused between runs.
+func runWith(executor *native.Executor, ch challenge.Challenge, solutionCode string, testCases []challenge.TestCase, limits native.Limits) (*native.ExecutionResult, error) {
+	lang, ok := native.LookupLanguage(ch.Language)
+	if !ok {
+		return nil, fmt.Errorf("unsupported language: %s", ch.Language)
+	}
 
-$result = %s(%s);
-$expected = %v;
+	// Build one program that runs every test case
+	testCode, err := lang.Generate(ch, solutionCode, testCases, limits.FailFast)
+	if err != nil {
+		return nil, err
+	}
 
-if ($result === $expected) {
-    echo "Test passed\n";
-    exit(0);
-} else {
-    echo "Expected: " . print_r($expected, true) . " Got: " . print_r($result, true) . "\n";
-    exit(1);
+	// The time limit covers running the program; compiling has its own
+	// budget
+	return executor.ExecuteCode(ch.Language, testCode, ch.TimeLimit, limits)
 }
-?>`, solutionCode, ch.FunctionName, argsStr, testCase.Expected)
+
+// limitMessages describe the statuses of a program stopped for exceeding
+// one of its limits.
+var limitMessages = map[native.Status]string{
+	native.StatusTimeLimitExceeded:   "Time limit exceeded",
+	native.StatusMemoryLimitExceeded: "Memory limit exceeded",
+	native.StatusOutputLimitExceeded: "Output limit exceeded",
 }
 
-func generateGoTestCode(ch challenge.Challenge, solutionCode string, testCase challenge.TestCase) string {
-	args := make([]string, len(testCase.Input))
-	for i, input := range testCase.Input {
-		switch v := input.(type) {
-		case string:
-			args[i] = fmt.Sprintf(`"%s"`, v)
-		case float64:
-			if v == float64(int(v)) {
-				args[i] = fmt.Sprintf("%d", int(v))
+// reportResults prints one entry per sample test case from the harness
+// protocol lines in result, and how many hidden cases passed, and reports
+// whether every case passed. Nothing else about a hidden case is shown.
+// Sample cases are shown with their numbers, or numbered in order if
+// numbers is nil.
+func reportResults(testCases []challenge.TestCase, numbers []int, result *native.ExecutionResult) bool {
+	// A program that failed to compile or type check never ran any case
+	if result.Status == native.StatusCompileError {
+		fmt.Printf("❌ Compile error\n")
+		if output := strings.TrimSpace(result.Output); output != "" {
+			fmt.Printf("%s\n", output)
+		} else if result.Error != "" {
+			fmt.Printf("%s\n", result.Error)
+		}
+		fmt.Println()
+		return false
+	}
+
+	caseResults, output := harness.ParseResults(result.Output)
+	byIndex := make(map[int]harness.CaseResult, len(caseResults))
+	for _, caseResult := range caseResults {
+		byIndex[caseResult.Index] = caseResult
+	}
+
+	success := true
+	samples, hidden, hiddenPassed := 0, 0, 0
+	for i, testCase := range testCases {
+		caseResult, ok := byIndex[i]
+		if testCase.Hidden {
+			hidden++
+			if ok && caseResult.Passed {
+				hiddenPassed++
 			} else {
-				args[i] = fmt.Sprintf("%f", v)
+				success = false
 			}
+			continue
+		}
+
+		samples++
+		number := samples
+		if numbers != nil {
+			number = numbers[i]
+		}
+		fmt.Printf("%s: %s\n", caseLabel(testCase, number), testCase.Description)
+		switch {
+		case !ok:
+			fmt.Printf("  ❌ Failed\n")
+			fmt.Printf("     Error: no result reported\n")
+			success = false
+		case caseResult.Passed:
+			fmt.Printf("  ✅ Passed (%.2fms)\n", caseResult.DurationMs)
 		default:
-			args[i] = fmt.Sprintf("%v", v)
-		}
-	}
-
-	// Join args properly for function call
-	argsStr := ""
-	if len(args) > 0 {
-		argsStr = strings.Join(args, ", ")
-	}
-
-	// Clean user code by removing package declaration, imports, and main function
-	cleanedCode := cleanGoUserCode(solutionCode)
-
-	// Handle multiple return values by checking if expected is an array
-	var testLogic string
-	if expectedSlice, ok := testCase.Expected.([]interface{}); ok {
-		// Multiple return values - create variables to capture each return value
-		if len(expectedSlice) == 2 {
-			testLogic = fmt.Sprintf(`	result1, result2 := %s(%s)
-	expected1, expected2 := %v, %v
-	
-	if result1 == expected1 && result2 == expected2 {
-		fmt.Println("Test passed")
-		os.Exit(0)
-	} else {
-		fmt.Printf("Expected: [%%v, %%v] Got: [%%v, %%v]\n", expected1, expected2, result1, result2)
-		os.Exit(1)
-	}`, ch.FunctionName, argsStr, expectedSlice[0], expectedSlice[1])
-		} else {
-			// Fallback for other multiple return value counts
-			var expectedStr string
-			if _, ok := testCase.Expected.(string); ok {
-				expectedStr = fmt.Sprintf(`"%s"`, testCase.Expected)
+			fmt.Printf("  ❌ Failed\n")
+			fmt.Printf("     Input: %s\n", formatInput(testCase.Input))
+			if caseResult.Error != "" {
+				fmt.Printf("     Error: %s\n", caseResult.Error)
 			} else {
-				expectedStr = fmt.Sprintf("%v", testCase.Expected)
+				printDiff(testCase, caseResult.Actual, "     ")
 			}
-			
-			testLogic = fmt.Sprintf(`	result := %s(%s)
-	expected := %s
-	
-	if fmt.Sprintf("%%v", result) == fmt.Sprintf("%%v", expected) {
-		fmt.Println("Test passed")
-		os.Exit(0)
-	} else {
-		fmt.Printf("Expected: %%v Got: %%v\n", expected, result)
-		os.Exit(1)
-	}`, ch.FunctionName, argsStr, expectedStr)
-		}
-	} else {
-		// Single return value
-		var expectedStr string
-		if _, ok := testCase.Expected.(string); ok {
-			expectedStr = fmt.Sprintf(`"%s"`, testCase.Expected)
-		} else {
-			expectedStr = fmt.Sprintf("%v", testCase.Expected)
-		}
-		
-		testLogic = fmt.Sprintf(`	result := %s(%s)
-	expected := %s
-	
-	if result == expected {
-		fmt.Println("Test passed")
-		os.Exit(0)
-	} else {
-		fmt.Printf("Expected: %%v Got: %%v\n", expected, result)
-		os.Exit(1)
-	}`, ch.FunctionName, argsStr, expectedStr)
-	}
-
-	return fmt.Sprintf(`package main
-
-import (
-	"fmt"
-	"os"
-)
-
-%s
+			success = false
+		}
+		fmt.Println()
+	}
 
-func main() {
-%s
-}`, cleanedCode, testLogic)
-}
+	if hidden > 0 {
+		mark := "✅"
+		if hiddenPassed < hidden {
+			mark = "❌"
+		}
+		fmt.Printf("%s Hidden tests: %d/%d passed\n\n", mark, hiddenPassed, hidden)
+	}
 
-func cleanGoUserCode(code string) string {
-	lines := strings.Split(code, "\n")
-	var cleanedLines []string
+	// A program stopped for exceeding a limit only reports the cases it
+	// finished before it was stopped
+	if message, ok := limitMessages[result.Status]; ok {
+		fmt.Printf("❌ %s\n\n", message)
+		printUsage(result)
+		return false
+	}
 
-	inImportBlock := false
-	inMainFunction := false
-	braceCount := 0
+	// Output that isn't part of the protocol, such as what the solution
+	// prints while debugging a failed case or a crash before reporting
+	// every case, helps explain a failure. It can give away the input of a
+	// hidden case, so it's only shown without them
+	if len(caseResults) < len(testCases) && hidden > 0 {
+		fmt.Printf("The program stopped before reporting every test case. Its output is not shown because hidden test cases ran.\n\n")
+	} else if hidden == 0 && (!success || !result.Success) {
+		// A program stopped at its first failed case ended as asked
+		if result.Error != "" && result.Status != native.StatusStopped {
+			fmt.Printf("Error: %s\n", result.Error)
+		}
+		if output != "" {
+			fmt.Printf("Output:\n%s\n\n", output)
+		}
+	}
 
-	for _, line := range lines {
-		trimmed := strings.TrimSpace(line)
+	printUsage(result)
+	return success && result.Success
+}
 
-		// Skip package declarations
-		if strings.HasPrefix(trimmed, "package ") {
-			continue
+// formatInput returns the arguments of a test case as a call would list them.
+func formatInput(input []interface{}) string {
+	args := make([]string, len(input))
+	for i, arg := range input {
+		encoded, err := json.Marshal(arg)
+		if err != nil {
+			encoded = []byte(fmt.Sprint(arg))
 		}
+		args[i] = string(encoded)
+	}
+	return strings.Join(args, ", ")
+}
 
-		// Skip imports (we provide our own imports)
-		if strings.HasPrefix(trimmed, "import ") && !strings.Contains(trimmed, "(") {
-			continue
-		}
+// printDiff prints how actual differs from the expected result of
+// testCase, each line after indent.
+func printDiff(testCase challenge.TestCase, actual json.RawMessage, indent string) {
+	opts := diff.Options{Mode: testCase.CompareMode(), Epsilon: testCase.Tolerance(), Color: colorOutput()}
+	fmt.Printf("%sDiff (- expected, + actual):\n", indent)
+	for _, line := range diff.Lines(testCase.Expected, actual, opts) {
+		fmt.Printf("%s  %s\n", indent, line)
+	}
+}
 
-		if strings.HasPrefix(trimmed, "import (") {
-			inImportBlock = true
-			continue
-		}
+// colorOutput reports whether stdout is a terminal that wants colors, see
+// https://no-color.org.
+func colorOutput() bool {
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	info, err := os.Stdout.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
 
-		if inImportBlock {
-			if trimmed == ")" {
-				inImportBlock = false
-			}
-			continue
-		}
+// printUsage prints how long the program took to compile and run and the
+// memory it used.
+func printUsage(result *native.ExecutionResult) {
+	fmt.Printf("Compile time: %v, run time: %v\n", result.CompileTime.Round(time.Millisecond), result.RunTime.Round(time.Millisecond))
+	if result.PeakMemory > 0 {
+		fmt.Printf("Peak memory: %.1f MB\n", float64(result.PeakMemory)/(1<<20))
+	}
+	fmt.Println()
+}
 
-		// Skip user's main function
-		if strings.HasPrefix(trimmed, "func main(") {
-			inMainFunction = true
-			braceCount = 0
-			// Count opening brace on the same line
-			for _, r := range line {
-				if r == '{' {
-					braceCount++
-				}
-			}
-			continue
-		}
+func loadChallengeMetadata(path string) (*ChallengeMetadata, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
 
-		if inMainFunction {
-			// Count braces to know when main function ends
-			for _, r := range line {
-				if r == '{' {
-					braceCount++
-				} else if r == '}' {
-					braceCount--
-				}
-			}
+	var metadata ChallengeMetadata
+	if err := json.Unmarshal(data, &metadata); err != nil {
+		return nil, err
+	}
 
-			if braceCount <= 0 {
-				inMainFunction = false
-			}
-			continue
-		}
+	return &metadata, nil
+}
 
-		cleanedLines = append(cleanedLines, line)
+// saveChallengeMetadata writes metadata to path.
+func saveChallengeMetadata(path string, metadata *ChallengeMetadata) error {
+	data, err := json.MarshalIndent(metadata, "", "  ")
+	if err != nil {
+		return err
 	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
 
-	// Remove empty lines at the beginning and end
-	for len(cleanedLines) > 0 && strings.TrimSpace(cleanedLines[0]) == "" {
-		cleanedLines = cleanedLines[1:]
+// rememberFailed records in the workspace metadata, for test --failed, which
+// of the cases of run failed with result. Cases that didn't run keep their
+// state from earlier runs, unless the test cases changed since. A failure
+// to save them only prints a warning.
+func rememberFailed(metadata *ChallengeMetadata, run testRun, result *native.ExecutionResult) {
+	ran := map[int]bool{}
+	for _, position := range run.positions {
+		ran[position] = true
 	}
-	for len(cleanedLines) > 0 && strings.TrimSpace(cleanedLines[len(cleanedLines)-1]) == "" {
-		cleanedLines = cleanedLines[:len(cleanedLines)-1]
+	remembered := run.failedPositions(result)
+	if metadata.CasesFingerprint == run.fingerprint {
+		for _, position := range metadata.FailedCases {
+			if !ran[position] {
+				remembered = append(remembered, position)
+			}
+		}
 	}
+	sort.Ints(remembered)
 
-	return strings.Join(cleanedLines, "\n")
+	metadata.FailedCases = remembered
+	metadata.CasesFingerprint = run.fingerprint
+	if err := saveChallengeMetadata(".challenge.json", metadata); err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️  Failed to remember the failed test cases: %v\n", err)
+	}
 }
 
 func init() {
+	testCmd.Flags().Bool("all", false, "Also run the hidden test cases")
+	testCmd.Flags().StringSlice("case", nil, "Only run these sample test cases, by number or range such as 3 or 2-4")
+	testCmd.Flags().String("match", "", "Only run the sample test cases whose description matches this regular expression")
+	testCmd.Flags().Bool("fail-fast", false, "Stop at the first test case that fails")
+	testCmd.Flags().Bool("only-local", false, "Only run the test cases from tests.local.json")
+	testCmd.Flags().Bool("failed", false, "Only run the test cases that failed the last time they ran")
+	testCmd.Flags().Bool("sandbox", false, "Run the solution in Linux namespaces without network access and with a read-only filesystem")
+	testCmd.Flags().String("format", "text", "Output format: text, json, tap or junit")
+	testCmd.Flags().BoolP("watch", "w", false, "Run the tests again whenever the solution file is saved")
 	rootCmd.AddCommand(testCmd)
 }
@@ -1,233 +1,128 @@
 package cmd
 
 import (
+	"io"
+	"os"
 	"strings"
 	"testing"
 
 	"github.com/crisecheverria/codequest/internal/challenge"
+	"github.com/crisecheverria/codequest/internal/harness"
+	"github.com/crisecheverria/codequest/internal/native"
 )
 
-func TestGenerateGoTestCode(t *testing.T) {
-	ch := challenge.Challenge{
-		Language:     "go",
-		FunctionName: "add",
+func TestReportResults(t *testing.T) {
+	testCases := []challenge.TestCase{
+		{Input: []interface{}{float64(1)}, Expected: float64(2), Description: "first"},
+		{Input: []interface{}{float64(2)}, Expected: float64(4), Description: "second"},
 	}
 
-	userCode := `package main
-
-import "fmt"
-
-func add(a, b int) int {
-    return a + b
-}
-
-func main() {
-    fmt.Println("Hello")
-}`
-
-	testCase := challenge.TestCase{
-		Input:       []interface{}{float64(2), float64(3)},
-		Expected:    "5",
-		Description: "should add two numbers",
-	}
-
-	result := generateGoTestCode(ch, userCode, testCase)
-
-	// Check that the generated code has the correct structure
-	if !strings.Contains(result, "package main") {
-		t.Error("Generated code should contain 'package main'")
-	}
-
-	if !strings.Contains(result, "func add(a, b int) int") {
-		t.Error("Generated code should contain the user's function")
-	}
-
-	if !strings.Contains(result, "result := add(2, 3)") {
-		t.Error("Generated code should call the function with test inputs")
-	}
-
-	if !strings.Contains(result, "expected := \"5\"") {
-		t.Error("Generated code should set the expected value")
-	}
-
-	if strings.Contains(result, "func main() {") && strings.Count(result, "func main()") > 1 {
-		t.Error("Generated code should not contain multiple main functions")
+	allPassed := &native.ExecutionResult{
+		Success: true,
+		Output: harness.ResultPrefix + `{"index":0,"passed":true,"durationMs":1}` + "\n" +
+			harness.ResultPrefix + `{"index":1,"passed":true,"durationMs":1}` + "\n",
 	}
-
-	// Check that user's imports are removed
-	if strings.Contains(result, "import \"fmt\"") {
-		t.Error("Generated code should not contain user's imports")
-	}
-
-	// Check that user's original main function is removed
-	if strings.Contains(result, "fmt.Println(\"Hello\")") {
-		t.Error("Generated code should not contain user's main function content")
-	}
-}
-
-func TestGenerateTypeScriptTestCode(t *testing.T) {
-	ch := challenge.Challenge{
-		Language:     "typescript",
-		FunctionName: "multiply",
+	if !reportResults(testCases, nil, allPassed) {
+		t.Error("Expected success when every case passed")
 	}
 
-	userCode := `function multiply(a: number, b: number): number {
-    return a * b;
-}`
-
-	testCase := challenge.TestCase{
-		Input:       []interface{}{float64(4), float64(5)},
-		Expected:    float64(20),
-		Description: "should multiply two numbers",
+	missingCase := &native.ExecutionResult{
+		Success: false,
+		Error:   "exit status 2",
+		Output:  harness.ResultPrefix + `{"index":0,"passed":true,"durationMs":1}` + "\npanic: boom\n",
 	}
-
-	result := generateTypeScriptTestCode(ch, userCode, testCase)
-
-	// Check that type annotations are removed
-	if strings.Contains(result, ": number") {
-		t.Error("Generated code should not contain TypeScript type annotations")
+	if reportResults(testCases, nil, missingCase) {
+		t.Error("Expected failure when a case reported no result")
 	}
 
-	if !strings.Contains(result, "function multiply(a, b)") {
-		t.Error("Generated code should contain the function without type annotations")
+	failedCase := &native.ExecutionResult{
+		Success: false,
+		Output: harness.ResultPrefix + `{"index":0,"passed":true,"durationMs":1}` + "\n" +
+			"debug: doubling 2\n" +
+			harness.ResultPrefix + `{"index":1,"passed":false,"durationMs":1,"actual":3}` + "\n",
 	}
-
-	if !strings.Contains(result, "const result = multiply(4, 5)") {
-		t.Error("Generated code should call the function with test inputs")
+	var failed bool
+	output := captureStdout(t, func() {
+		failed = !reportResults(testCases, nil, failedCase)
+	})
+	if !failed {
+		t.Error("Expected failure when a case did not pass")
 	}
-
-	if !strings.Contains(result, "const expected = 20") {
-		t.Error("Generated code should set the expected value")
+	if want := "  ❌ Failed\n     Input: 2\n     Diff (- expected, + actual):\n       - 4\n       + 3\n"; !strings.Contains(output, want) {
+		t.Errorf("Expected the input and a diff of the failed case, got:\n%s", output)
 	}
-}
-
-func TestGeneratePHPTestCode(t *testing.T) {
-	ch := challenge.Challenge{
-		Language:     "php",
-		FunctionName: "divide",
+	if !strings.Contains(output, "Output:\ndebug: doubling 2\n") {
+		t.Errorf("Expected the program's own output with a failed case, got:\n%s", output)
 	}
 
-	userCode := `<?php
-function divide($a, $b) {
-    return $a / $b;
-}
-?>`
-
-	testCase := challenge.TestCase{
-		Input:       []interface{}{float64(10), float64(2)},
-		Expected:    float64(5),
-		Description: "should divide two numbers",
+	compileError := &native.ExecutionResult{
+		Success: false,
+		Status:  native.StatusCompileError,
+		Error:   "compilation failed (tsc): exit status 2",
+		Output:  "solution.ts(2,5): error TS2322: Type 'string' is not assignable to type 'number'.\n",
 	}
-
-	result := generatePHPTestCode(ch, userCode, testCase)
-
-	if !strings.Contains(result, "<?php") {
-		t.Error("Generated code should start with PHP opening tag")
+	if reportResults(testCases, nil, compileError) {
+		t.Error("Expected failure when the solution did not compile")
 	}
 
-	if !strings.Contains(result, "$result = divide(10, 2)") {
-		t.Error("Generated code should call the function with test inputs")
+	timeLimitExceeded := &native.ExecutionResult{
+		Success: false,
+		Status:  native.StatusTimeLimitExceeded,
+		Error:   "signal: killed",
+		Output:  harness.ResultPrefix + `{"index":0,"passed":true,"durationMs":1}` + "\n",
 	}
-
-	if !strings.Contains(result, "$expected = 5") {
-		t.Error("Generated code should set the expected value")
+	if reportResults(testCases, nil, timeLimitExceeded) {
+		t.Error("Expected failure when the solution exceeded its time limit")
 	}
 }
 
-func TestCleanGoUserCode(t *testing.T) {
-	userCode := `package main
-
-import (
-    "fmt"
-    "strconv"
-)
-
-import "os"
-
-func add(a, b int) int {
-    return a + b
-}
-
-func helper() string {
-    return "helper"
-}
-
-func main() {
-    fmt.Println("This should be removed")
-    if true {
-        fmt.Println("Nested content")
-    }
-}
-
-func anotherFunc() int {
-    return 42
-}`
-
-	cleaned := cleanGoUserCode(userCode)
-
-	// Should remove package declaration
-	if strings.Contains(cleaned, "package main") {
-		t.Error("Cleaned code should not contain package declaration")
-	}
-
-	// Should remove import statements
-	if strings.Contains(cleaned, "import") {
-		t.Error("Cleaned code should not contain import statements")
-	}
-
-	// Should remove main function
-	if strings.Contains(cleaned, "func main()") {
-		t.Error("Cleaned code should not contain main function")
-	}
-	if strings.Contains(cleaned, "This should be removed") {
-		t.Error("Cleaned code should not contain main function content")
+func TestReportResultsHidesHiddenCases(t *testing.T) {
+	testCases := []challenge.TestCase{
+		{Input: []interface{}{float64(1)}, Expected: float64(2), Description: "sample"},
+		{Input: []interface{}{float64(21)}, Expected: float64(42), Description: "secret case", Hidden: true},
 	}
-
-	// Should keep other functions
-	if !strings.Contains(cleaned, "func add(a, b int) int") {
-		t.Error("Cleaned code should contain the add function")
+	result := &native.ExecutionResult{
+		Success: false,
+		Status:  native.StatusRuntimeError,
+		Error:   "exit status 1",
+		Output: harness.ResultPrefix + `{"index":0,"passed":true,"durationMs":1}` + "\n" +
+			harness.ResultPrefix + `{"index":1,"passed":false,"durationMs":1,"actual":41}` + "\n",
 	}
 
-	if !strings.Contains(cleaned, "func helper() string") {
-		t.Error("Cleaned code should contain the helper function")
+	var success bool
+	output := captureStdout(t, func() {
+		success = reportResults(testCases, nil, result)
+	})
+	if success {
+		t.Error("Expected failure when a hidden case did not pass")
 	}
-
-	if !strings.Contains(cleaned, "func anotherFunc() int") {
-		t.Error("Cleaned code should contain the anotherFunc function")
+	if !strings.Contains(output, "Hidden tests: 0/1 passed") {
+		t.Errorf("Expected a hidden test count, got:\n%s", output)
 	}
-}
-
-func TestCleanGoUserCodeWithMainFunctionVariations(t *testing.T) {
-	// Test main function with opening brace on same line
-	userCode1 := `package main
-
-func add(a, b int) int {
-    return a + b
-}
-
-func main() {
-    fmt.Println("Hello")
-}`
-
-	cleaned1 := cleanGoUserCode(userCode1)
-	if strings.Contains(cleaned1, "func main()") || strings.Contains(cleaned1, "Hello") {
-		t.Error("Should remove main function with brace on same line")
+	for _, secret := range []string{"secret case", "42", "41"} {
+		if strings.Contains(output, secret) {
+			t.Errorf("Output gives away %q of a hidden case:\n%s", secret, output)
+		}
 	}
-
-	// Test main function with opening brace on next line
-	userCode2 := `package main
-
-func add(a, b int) int {
-    return a + b
 }
 
-func main()
-{
-    fmt.Println("Hello")
-}`
-
-	cleaned2 := cleanGoUserCode(userCode2)
-	if strings.Contains(cleaned2, "func main()") || strings.Contains(cleaned2, "Hello") {
-		t.Error("Should remove main function with brace on next line")
-	}
+// captureStdout returns what f prints to standard output.
+func captureStdout(t *testing.T, f func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Failed to create pipe: %v", err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+
+	done := make(chan string)
+	go func() {
+		data, _ := io.ReadAll(r)
+		done <- string(data)
+	}()
+	f()
+	w.Close()
+	return <-done
 }
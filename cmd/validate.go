@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/crisecheverria/codequest/internal/challenge"
+	"github.com/crisecheverria/codequest/internal/native"
+	"github.com/spf13/cobra"
+)
+
+var validateCmd = &cobra.Command{
+	Use:   "validate [file|dir]...",
+	Short: "Check challenge files for mistakes",
+	Long: `Check challenges files against the challenge schema and report every
+problem with its line and column: syntax errors, unknown fields, values of
+the wrong type, missing required fields, duplicate slugs, unknown languages,
+difficulties, concept tags and comparison modes, function names missing from
+the template, test case inputs that don't match parameterTypes and variants
+that repeat a language.
+
+Each argument is a challenges file or a pack directory containing a
+challenges.json file, the current directory by default. The command exits
+with an error if any problem is found, so it can run in a pre-commit hook.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		if len(args) == 0 {
+			args = []string{"."}
+		}
+
+		concepts, err := challenge.LoadConcepts()
+		if err != nil {
+			return fmt.Errorf("failed to load concepts: %w", err)
+		}
+		opts := challenge.ValidateOptions{
+			Language: func(id string) bool {
+				_, ok := native.LookupLanguage(id)
+				return ok
+			},
+			Concepts: concepts,
+		}
+
+		total := 0
+		for _, path := range args {
+			problems, err := challenge.ValidatePack(path, opts)
+			if err != nil {
+				return err
+			}
+			for _, problem := range problems {
+				fmt.Println(problem)
+			}
+			total += len(problems)
+		}
+
+		if total > 0 {
+			return fmt.Errorf("found %s", problemCount(total))
+		}
+		fmt.Println("✅ No problems found")
+		return nil
+	},
+}
+
+func problemCount(n int) string {
+	if n == 1 {
+		return "1 problem"
+	}
+	return fmt.Sprintf("%d problems", n)
+}
+
+func init() {
+	rootCmd.AddCommand(validateCmd)
+}
@@ -0,0 +1,152 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/crisecheverria/codequest/internal/challenge"
+	"github.com/crisecheverria/codequest/internal/harness"
+	"github.com/crisecheverria/codequest/internal/native"
+	"github.com/spf13/cobra"
+)
+
+var verifyCmd = &cobra.Command{
+	Use:   "verify [file|dir]...",
+	Short: "Check that reference solutions pass and templates fail",
+	Long: `Run the reference solution of every challenge against its test cases with
+the same executors and harnesses as 'codequest test', and check that the
+untouched template fails. A challenge fails verification if its reference
+solution fails any test case or its template passes them all.
+
+Each argument is a challenges file or a pack directory containing a
+challenges.json file, the current directory by default. Challenges without a
+reference solution only have their template checked, unless
+--require-reference is set. The command exits with an error if any challenge
+fails, so it can run in CI.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		if len(args) == 0 {
+			args = []string{"."}
+		}
+		sandbox, _ := cmd.Flags().GetBool("sandbox")
+		requireReference, _ := cmd.Flags().GetBool("require-reference")
+
+		total, failed := 0, 0
+		for _, path := range args {
+			challenges, err := challenge.ReadChallenges(path)
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("Verifying %d challenges in %s\n\n", len(challenges), path)
+			for _, ch := range challenges {
+				total++
+				problems := verifyChallenge(ch, sandbox)
+				if ch.ReferenceSolution == "" && requireReference {
+					problems = append(problems, "no reference solution")
+				}
+
+				switch {
+				case len(problems) > 0:
+					failed++
+					fmt.Printf("❌ %s\n", ch.Label())
+					for _, problem := range problems {
+						fmt.Printf("   %s\n", strings.ReplaceAll(problem, "\n", "\n   "))
+					}
+				case ch.ReferenceSolution == "":
+					fmt.Printf("⚠️  %s: no reference solution, template fails\n", ch.Label())
+				default:
+					fmt.Printf("✅ %s: reference solution passes, template fails\n", ch.Label())
+				}
+			}
+			fmt.Println()
+		}
+
+		if failed > 0 {
+			return fmt.Errorf("%d of %d challenges failed verification", failed, total)
+		}
+		fmt.Printf("🎉 All %d challenges verified\n", total)
+		return nil
+	},
+}
+
+// verifyChallenge runs ch's reference solution, if it has one, and its
+// template, and describes each way they disagree with the test cases.
+func verifyChallenge(ch challenge.Challenge, sandbox bool) []string {
+	var problems []string
+
+	if ch.ReferenceSolution != "" {
+		result, err := runSolution(ch, ch.ReferenceSolution, ch.TestCases, sandbox, false)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("reference solution: %v", err))
+		} else {
+			for _, failure := range failures(ch.TestCases, result) {
+				problems = append(problems, "reference solution: "+failure)
+			}
+		}
+	}
+
+	result, err := runSolution(ch, ch.Template, ch.TestCases, sandbox, false)
+	if err != nil {
+		problems = append(problems, fmt.Sprintf("template: %v", err))
+	} else if len(failures(ch.TestCases, result)) == 0 {
+		problems = append(problems, "template passes every test case")
+	}
+
+	return problems
+}
+
+// failures describes why result doesn't pass every test case, one entry per
+// failed case or for the whole program when it didn't run them all.
+func failures(testCases []challenge.TestCase, result *native.ExecutionResult) []string {
+	if result.Status == native.StatusCompileError {
+		output := strings.TrimSpace(result.Output)
+		if output == "" {
+			output = result.Error
+		}
+		return []string{"compile error: " + output}
+	}
+
+	caseResults, output := harness.ParseResults(result.Output)
+	byIndex := make(map[int]harness.CaseResult, len(caseResults))
+	for _, caseResult := range caseResults {
+		byIndex[caseResult.Index] = caseResult
+	}
+
+	var failures []string
+	for i, testCase := range testCases {
+		caseResult, ok := byIndex[i]
+		switch {
+		case !ok:
+			failures = append(failures, fmt.Sprintf("test %d (%s): no result reported", i+1, testCase.Description))
+		case caseResult.Error != "":
+			failures = append(failures, fmt.Sprintf("test %d (%s): %s", i+1, testCase.Description, caseResult.Error))
+		case !caseResult.Passed:
+			expected, _ := json.Marshal(testCase.Expected)
+			failures = append(failures, fmt.Sprintf("test %d (%s): expected %s, got %s", i+1, testCase.Description, expected, caseResult.Actual))
+		}
+	}
+
+	if message, ok := limitMessages[result.Status]; ok {
+		failures = append(failures, message)
+	} else if len(failures) > 0 && len(caseResults) < len(testCases) {
+		// Whatever stopped the program before it reported every case
+		if result.Error != "" {
+			failures = append(failures, result.Error)
+		}
+		if output != "" {
+			failures = append(failures, "output: "+output)
+		}
+	}
+	if len(failures) == 0 && !result.Success {
+		failures = append(failures, fmt.Sprintf("program ended with status %s", result.Status))
+	}
+	return failures
+}
+
+func init() {
+	verifyCmd.Flags().Bool("sandbox", false, "Run the solutions in Linux namespaces without network access and with a read-only filesystem")
+	verifyCmd.Flags().Bool("require-reference", false, "Fail challenges that have no reference solution")
+	rootCmd.AddCommand(verifyCmd)
+}
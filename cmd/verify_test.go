@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/crisecheverria/codequest/internal/challenge"
+	"github.com/crisecheverria/codequest/internal/harness"
+	"github.com/crisecheverria/codequest/internal/native"
+)
+
+func TestFailures(t *testing.T) {
+	testCases := []challenge.TestCase{
+		{Description: "one", Expected: float64(2)},
+		{Description: "two", Expected: float64(4)},
+		{Description: "three", Expected: float64(6)},
+	}
+
+	result := &native.ExecutionResult{
+		Status: native.StatusRuntimeError,
+		Error:  "exit status 1",
+		Output: harness.ResultPrefix + `{"index":0,"passed":true}` + "\n" +
+			harness.ResultPrefix + `{"index":1,"passed":false,"actual":5}` + "\n" +
+			"Traceback: boom\n",
+	}
+	got := failures(testCases, result)
+	want := []string{
+		"test 2 (two): expected 4, got 5",
+		"test 3 (three): no result reported",
+		"exit status 1",
+		"output: Traceback: boom",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("failures() = %q, want %q", got, want)
+	}
+
+	passed := &native.ExecutionResult{
+		Success: true,
+		Status:  native.StatusOK,
+		Output: harness.ResultPrefix + `{"index":0,"passed":true}` + "\n" +
+			harness.ResultPrefix + `{"index":1,"passed":true}` + "\n" +
+			harness.ResultPrefix + `{"index":2,"passed":true}` + "\n",
+	}
+	if got := failures(testCases, passed); len(got) != 0 {
+		t.Errorf("Expected no failures, got %q", got)
+	}
+
+	compileError := &native.ExecutionResult{Status: native.StatusCompileError, Output: "syntax error\n"}
+	if got := failures(testCases, compileError); len(got) != 1 || got[0] != "compile error: syntax error" {
+		t.Errorf("Unexpected compile error failures: %q", got)
+	}
+}
+
+func TestVerifyChallenge(t *testing.T) {
+	python, ok := native.LookupLanguage("python")
+	if !ok {
+		t.Fatal("python is not registered")
+	}
+	if err := python.NewExecutor(t.TempDir()).CheckAvailability(); err != nil {
+		t.Skipf("python not available: %v", err)
+	}
+
+	ch := challenge.Challenge{
+		Slug:           "double",
+		Language:       "python",
+		FunctionName:   "double",
+		ParameterTypes: []string{"int"},
+		Template:       "def double(n):\n    pass\n",
+		TestCases: []challenge.TestCase{
+			{Input: []interface{}{float64(1)}, Expected: float64(2), Description: "doubles one"},
+			{Input: []interface{}{float64(3)}, Expected: float64(6), Description: "doubles three"},
+		},
+		TimeLimit: 5000,
+	}
+
+	ch.ReferenceSolution = "def double(n):\n    return 2 * n\n"
+	if problems := verifyChallenge(ch, false); len(problems) != 0 {
+		t.Errorf("Expected a correct reference solution to verify, got %q", problems)
+	}
+
+	ch.ReferenceSolution = "def double(n):\n    return n + 1\n"
+	problems := verifyChallenge(ch, false)
+	if len(problems) != 1 || !strings.Contains(problems[0], "test 2 (doubles three): expected 6, got 4") {
+		t.Errorf("Expected the wrong reference solution to fail test 2, got %q", problems)
+	}
+
+	ch.ReferenceSolution = ""
+	ch.Template = "def double(n):\n    return 2 * n\n"
+	problems = verifyChallenge(ch, false)
+	if len(problems) != 1 || problems[0] != "template passes every test case" {
+		t.Errorf("Expected a passing template to fail verification, got %q", problems)
+	}
+}
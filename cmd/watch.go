@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/crisecheverria/codequest/internal/challenge"
+	"github.com/crisecheverria/codequest/internal/native"
+	"github.com/crisecheverria/codequest/internal/report"
+	"github.com/crisecheverria/codequest/internal/watch"
+)
+
+// clearScreen moves the cursor home and clears the terminal.
+const clearScreen = "\033[H\033[2J"
+
+// watchTests runs the cases of run whenever the solution file of the
+// workspace described by metadata is saved and redraws a summary of each
+// run, until interrupted. The executor, and the toolchain it found, are
+// kept between runs. Runs on save aren't recorded as attempts in the
+// progress journal.
+func watchTests(ch challenge.Challenge, metadata *ChallengeMetadata, run testRun, sandbox, failFast bool) error {
+	executor, err := native.NewExecutor()
+	if err != nil {
+		return fmt.Errorf("failed to create native executor: %w", err)
+	}
+	defer executor.Close()
+
+	limits, sandboxErr := solutionLimits(ch, sandbox)
+	limits.FailFast = failFast
+	solutionFile := metadata.SolutionFile
+
+	watcher, err := watch.New(solutionFile, watch.DefaultDelay)
+	if err != nil {
+		return err
+	}
+	defer watcher.Close()
+
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(interrupt)
+
+	test := func() {
+		code, err := os.ReadFile(solutionFile)
+		var result *native.ExecutionResult
+		if err == nil {
+			result, err = runWith(executor, ch, string(code), run.cases, limits)
+		}
+
+		fmt.Print(clearScreen)
+		fmt.Printf("%s (%s) · %s\n\n", ch.Title, ch.Language, time.Now().Format("15:04:05"))
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+		} else {
+			reached := run.reached(result)
+			rememberFailed(metadata, reached, result)
+			printSummary(report.New(ch, reached.cases, result), reached.cases, reached.numbers)
+			if skipped := len(run.cases) - len(reached.cases); skipped > 0 {
+				fmt.Printf("⏭️  Stopped at the first failure; %d more test cases did not run.\n", skipped)
+			}
+		}
+		if sandboxErr != nil {
+			fmt.Printf("⚠️  Sandbox unavailable, running without isolation: %v\n", sandboxErr)
+		}
+		fmt.Printf("\nWatching %s for changes. Press Ctrl+C to stop.\n", solutionFile)
+	}
+
+	test()
+	for {
+		select {
+		case <-watcher.Changes:
+			test()
+		case err := <-watcher.Errors:
+			return err
+		case <-interrupt:
+			return nil
+		}
+	}
+}
+
+// printSummary prints one line per sample case of r, with the input and a
+// diff of the wrong answers, a count of the hidden cases, and why the
+// program failed as a whole, if it did. r describes a run of testCases,
+// whose sample cases are shown with their numbers, or numbered in order if
+// numbers is nil.
+func printSummary(r *report.Report, testCases []challenge.TestCase, numbers []int) {
+	if r.Status == string(native.StatusCompileError) {
+		fmt.Printf("❌ Compile error\n%s\n", r.Error)
+		return
+	}
+
+	hidden, hiddenPassed, samples := 0, 0, 0
+	for _, c := range r.Cases {
+		if c.Hidden {
+			hidden++
+			if c.Passed {
+				hiddenPassed++
+			}
+			continue
+		}
+
+		samples++
+		number := samples
+		if numbers != nil {
+			number = numbers[c.Index]
+		}
+		if c.Passed {
+			fmt.Printf("✅ %d. %s (%.2fms)\n", number, c.Name(), c.DurationMs)
+		} else if c.Failure == report.FailureWrongAnswer {
+			fmt.Printf("❌ %d. %s\n", number, c.Name())
+			fmt.Printf("   Input: %s\n", formatInput(c.Input))
+			printDiff(testCases[c.Index], c.Actual, "   ")
+		} else {
+			fmt.Printf("❌ %d. %s: %s\n", number, c.Name(), c.Message())
+		}
+	}
+	if hidden > 0 {
+		mark := "✅"
+		if hiddenPassed < hidden {
+			mark = "❌"
+		}
+		fmt.Printf("%s Hidden tests: %d/%d passed\n", mark, hiddenPassed, hidden)
+	}
+
+	if message, ok := limitMessages[native.Status(r.Status)]; ok {
+		fmt.Printf("❌ %s\n", message)
+	} else if r.Output != "" {
+		fmt.Printf("\nOutput:\n%s\n", r.Output)
+	}
+
+	fmt.Println()
+	if r.Passed {
+		fmt.Printf("🎉 All %d tests passed", r.Tests)
+	} else {
+		fmt.Printf("%d/%d tests passed", r.TestsPassed, r.Tests)
+	}
+	fmt.Printf(" · compile %v · run %v\n", msDuration(r.CompileTimeMs), msDuration(r.RunTimeMs))
+}
+
+// msDuration rounds a duration in milliseconds to a whole millisecond.
+func msDuration(ms float64) time.Duration {
+	return time.Duration(ms * float64(time.Millisecond)).Round(time.Millisecond)
+}
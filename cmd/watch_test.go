@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/crisecheverria/codequest/internal/challenge"
+	"github.com/crisecheverria/codequest/internal/harness"
+	"github.com/crisecheverria/codequest/internal/native"
+	"github.com/crisecheverria/codequest/internal/report"
+)
+
+func TestPrintSummary(t *testing.T) {
+	ch := challenge.Challenge{Slug: "double", Title: "Double", Language: "python"}
+	testCases := []challenge.TestCase{
+		{Input: []interface{}{float64(1)}, Expected: float64(2), Description: "first"},
+		{Input: []interface{}{float64(2)}, Expected: float64(4), Description: "second"},
+		{Input: []interface{}{float64(21)}, Expected: float64(42), Description: "secret case", Hidden: true},
+	}
+	result := &native.ExecutionResult{
+		Status: native.StatusRuntimeError,
+		Output: harness.ResultPrefix + `{"index":0,"passed":true,"durationMs":1}` + "\n" +
+			harness.ResultPrefix + `{"index":1,"passed":false,"durationMs":1,"actual":5}` + "\n" +
+			harness.ResultPrefix + `{"index":2,"passed":true,"durationMs":1}` + "\n",
+	}
+
+	output := captureStdout(t, func() {
+		printSummary(report.New(ch, testCases, result), testCases, nil)
+	})
+	for _, want := range []string{
+		"✅ 1. first (1.00ms)\n",
+		"❌ 2. second\n   Input: 2\n   Diff (- expected, + actual):\n     - 4\n     + 5\n",
+		"✅ Hidden tests: 1/1 passed\n",
+		"2/3 tests passed",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Summary is missing %q:\n%s", want, output)
+		}
+	}
+	if strings.Contains(output, "secret case") {
+		t.Errorf("Summary gives away a hidden case:\n%s", output)
+	}
+
+	compileError := &native.ExecutionResult{Status: native.StatusCompileError, Output: "solution.go:3: syntax error\n"}
+	output = captureStdout(t, func() {
+		printSummary(report.New(ch, testCases, compileError), testCases, nil)
+	})
+	if output != "❌ Compile error\nsolution.go:3: syntax error\n" {
+		t.Errorf("Unexpected compile error summary:\n%s", output)
+	}
+}
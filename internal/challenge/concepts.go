@@ -0,0 +1,208 @@
+package challenge
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// ConceptLanguageAll is the language of concepts shared by every language.
+const ConceptLanguageAll = "all"
+
+// Resource links to learning material about a concept.
+type Resource struct {
+	Title string `json:"title"`
+	URL   string `json:"url"`
+	// Type is the kind of material, such as documentation or tutorial
+	Type string `json:"type"`
+}
+
+// Concept is a topic that challenges exercise through their ConceptTags.
+type Concept struct {
+	Name        string `json:"name"`
+	Slug        string `json:"slug"`
+	Description string `json:"description"`
+	Category    string `json:"category"`
+	// Language is the language the concept is taught in, or
+	// ConceptLanguageAll
+	Language string `json:"language"`
+	// Order is the concept's position in its language's learning path
+	Order int `json:"order"`
+	// Dependencies are the slugs of concepts to learn first
+	Dependencies []string   `json:"dependencies"`
+	Resources    []Resource `json:"resources"`
+}
+
+// LoadConcepts returns the concept graph, read from the embedded data or,
+// in development builds, from data/concepts.json.
+func LoadConcepts() ([]Concept, error) {
+	data := conceptsData
+	if len(data) == 0 {
+		var err error
+		if data, err = os.ReadFile("data/concepts.json"); err != nil {
+			return nil, fmt.Errorf("failed to read concepts file: %w", err)
+		}
+	}
+
+	var concepts []Concept
+	if err := json.Unmarshal(data, &concepts); err != nil {
+		return nil, fmt.Errorf("failed to parse concepts JSON: %w", err)
+	}
+	return concepts, nil
+}
+
+// FindConcept returns the concept with the given slug.
+func FindConcept(concepts []Concept, slug string) (Concept, bool) {
+	for _, concept := range concepts {
+		if concept.Slug == slug {
+			return concept, true
+		}
+	}
+	return Concept{}, false
+}
+
+// ValidateConcepts reports every duplicate concept slug, dependency on an
+// unknown concept, dependency cycle and challenge concept tag that doesn't
+// name a concept.
+func ValidateConcepts(concepts []Concept, challenges []Challenge) []error {
+	var problems []error
+
+	bySlug := make(map[string]Concept, len(concepts))
+	for _, concept := range concepts {
+		if _, ok := bySlug[concept.Slug]; ok {
+			problems = append(problems, fmt.Errorf("concept %q is defined more than once", concept.Slug))
+			continue
+		}
+		bySlug[concept.Slug] = concept
+	}
+
+	for _, concept := range concepts {
+		for _, dependency := range concept.Dependencies {
+			if _, ok := bySlug[dependency]; !ok {
+				problems = append(problems, fmt.Errorf("concept %q depends on unknown concept %q", concept.Slug, dependency))
+			}
+		}
+	}
+
+	// Depth-first search, reporting each cycle once from its first concept
+	const (
+		visiting = 1
+		done     = 2
+	)
+	state := map[string]int{}
+	var visit func(slug string, path []string)
+	visit = func(slug string, path []string) {
+		switch state[slug] {
+		case visiting:
+			for i, s := range path {
+				if s == slug {
+					cycle := append(append([]string{}, path[i:]...), slug)
+					problems = append(problems, fmt.Errorf("concept dependency cycle: %s", strings.Join(cycle, " -> ")))
+					break
+				}
+			}
+			return
+		case done:
+			return
+		}
+		state[slug] = visiting
+		for _, dependency := range bySlug[slug].Dependencies {
+			if _, ok := bySlug[dependency]; ok {
+				visit(dependency, append(path, slug))
+			}
+		}
+		state[slug] = done
+	}
+	for _, concept := range concepts {
+		visit(concept.Slug, nil)
+	}
+
+	for _, ch := range challenges {
+		for _, tag := range ch.ConceptTags {
+			if _, ok := bySlug[tag]; !ok {
+				problems = append(problems, fmt.Errorf("challenge %q has unknown concept tag %q", ch.Slug, tag))
+			}
+		}
+	}
+
+	return problems
+}
+
+// ConceptNode is a concept in a dependency tree, with the concepts that
+// build on it as children.
+type ConceptNode struct {
+	Concept Concept
+	// Requires lists the dependencies other than the parent node
+	Requires []string
+	Children []*ConceptNode
+}
+
+// ConceptTree arranges concepts into dependency trees sorted by order. A
+// concept with several dependencies appears once, under the one that comes
+// latest in the learning path, and lists the others in Requires.
+// Dependencies outside concepts are listed in Requires as well.
+func ConceptTree(concepts []Concept) []*ConceptNode {
+	sorted := append([]Concept(nil), concepts...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Order != sorted[j].Order {
+			return sorted[i].Order < sorted[j].Order
+		}
+		return sorted[i].Slug < sorted[j].Slug
+	})
+
+	bySlug := make(map[string]Concept, len(sorted))
+	for _, concept := range sorted {
+		bySlug[concept.Slug] = concept
+	}
+
+	parent := map[string]string{}
+	children := map[string][]Concept{}
+	for _, concept := range sorted {
+		for _, dependency := range concept.Dependencies {
+			candidate, ok := bySlug[dependency]
+			if !ok || dependency == concept.Slug {
+				continue
+			}
+			if current, ok := parent[concept.Slug]; !ok || candidate.Order >= bySlug[current].Order {
+				parent[concept.Slug] = dependency
+			}
+		}
+		if p, ok := parent[concept.Slug]; ok {
+			children[p] = append(children[p], concept)
+		}
+	}
+
+	visited := map[string]bool{}
+	var build func(concept Concept) *ConceptNode
+	build = func(concept Concept) *ConceptNode {
+		visited[concept.Slug] = true
+		node := &ConceptNode{Concept: concept}
+		for _, dependency := range concept.Dependencies {
+			if dependency != parent[concept.Slug] {
+				node.Requires = append(node.Requires, dependency)
+			}
+		}
+		for _, child := range children[concept.Slug] {
+			if !visited[child.Slug] {
+				node.Children = append(node.Children, build(child))
+			}
+		}
+		return node
+	}
+
+	var roots []*ConceptNode
+	for _, concept := range sorted {
+		if _, ok := parent[concept.Slug]; !ok && !visited[concept.Slug] {
+			roots = append(roots, build(concept))
+		}
+	}
+	// Concepts in a dependency cycle have no root to hang from
+	for _, concept := range sorted {
+		if !visited[concept.Slug] {
+			roots = append(roots, build(concept))
+		}
+	}
+	return roots
+}
@@ -0,0 +1,82 @@
+package challenge
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEmbeddedConceptsAreValid(t *testing.T) {
+	concepts, err := LoadConcepts()
+	if err != nil {
+		t.Fatalf("LoadConcepts() failed: %v", err)
+	}
+	challenges, err := LoadChallenges()
+	if err != nil {
+		t.Fatalf("LoadChallenges() failed: %v", err)
+	}
+
+	for _, problem := range ValidateConcepts(concepts, challenges) {
+		t.Error(problem)
+	}
+	if _, ok := FindConcept(concepts, "variables-go"); !ok {
+		t.Error("Expected to find the variables-go concept")
+	}
+}
+
+func TestValidateConcepts(t *testing.T) {
+	concepts := []Concept{
+		{Slug: "a", Dependencies: []string{"c"}},
+		{Slug: "b", Dependencies: []string{"a", "missing"}},
+		{Slug: "c", Dependencies: []string{"b"}},
+		{Slug: "a"},
+	}
+	challenges := []Challenge{{Slug: "sum", ConceptTags: []string{"a", "loops"}}}
+
+	var messages []string
+	for _, problem := range ValidateConcepts(concepts, challenges) {
+		messages = append(messages, problem.Error())
+	}
+	want := []string{
+		`concept "a" is defined more than once`,
+		`concept "b" depends on unknown concept "missing"`,
+		`concept dependency cycle: a -> c -> b -> a`,
+		`challenge "sum" has unknown concept tag "loops"`,
+	}
+	if strings.Join(messages, "\n") != strings.Join(want, "\n") {
+		t.Errorf("ValidateConcepts() =\n%s\nwant\n%s", strings.Join(messages, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestConceptTree(t *testing.T) {
+	concepts := []Concept{
+		{Slug: "functions", Order: 3, Dependencies: []string{"variables"}},
+		{Slug: "variables", Order: 1},
+		{Slug: "loops", Order: 2, Dependencies: []string{"variables"}},
+		{Slug: "recursion", Order: 4, Dependencies: []string{"loops", "functions"}},
+		{Slug: "memoization", Order: 5, Dependencies: []string{"recursion", "objects"}},
+	}
+
+	roots := ConceptTree(concepts)
+	if len(roots) != 1 || roots[0].Concept.Slug != "variables" {
+		t.Fatalf("Expected variables as the only root, got %+v", roots)
+	}
+	children := roots[0].Children
+	if len(children) != 2 || children[0].Concept.Slug != "loops" || children[1].Concept.Slug != "functions" {
+		t.Fatalf("Expected loops and functions under variables, got %+v", children)
+	}
+
+	// Recursion hangs from its latest prerequisite
+	recursion := children[1].Children
+	if len(recursion) != 1 || recursion[0].Concept.Slug != "recursion" || strings.Join(recursion[0].Requires, ",") != "loops" {
+		t.Fatalf("Expected recursion under functions requiring loops, got %+v", recursion)
+	}
+	memoization := recursion[0].Children
+	if len(memoization) != 1 || strings.Join(memoization[0].Requires, ",") != "objects" {
+		t.Errorf("Expected memoization to require objects from outside the tree, got %+v", memoization)
+	}
+
+	cyclic := ConceptTree([]Concept{{Slug: "a", Dependencies: []string{"b"}}, {Slug: "b", Dependencies: []string{"a"}}})
+	if len(cyclic) != 1 || len(cyclic[0].Children) != 1 {
+		t.Errorf("Expected a cycle to be shown once, got %+v", cyclic)
+	}
+}
@@ -1,17 +1,39 @@
 package challenge
 
 import (
-	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"strings"
 )
 
+// packWarnings is where LoadChallenges reports packs it leaves out.
+var packWarnings io.Writer = os.Stderr
+
+// LoadChallenges returns the built-in challenges followed by those of the
+// registered packs. A pack that fails to load is left out with a warning,
+// so that it can't make the built-in challenges unusable.
 func LoadChallenges() ([]Challenge, error) {
+	challenges, err := loadBuiltinChallenges()
+	if err != nil {
+		return nil, err
+	}
+
+	packs, err := LoadPacks(packPaths)
+	if err != nil {
+		fmt.Fprintf(packWarnings, "⚠️  Skipping challenge packs that failed to load:\n%v\n\n", err)
+	}
+	for _, pack := range packs {
+		challenges = append(challenges, pack.Challenges...)
+	}
+	return challenges, nil
+}
+
+func loadBuiltinChallenges() ([]Challenge, error) {
 	// First try to use embedded data (for production builds)
 	if len(challengesData) > 0 {
-		var challenges []Challenge
-		if err := json.Unmarshal(challengesData, &challenges); err != nil {
+		challenges, err := decodeChallenges("challenges.json", challengesData)
+		if err != nil {
 			return nil, fmt.Errorf("failed to parse embedded challenges JSON: %w", err)
 		}
 		return challenges, nil
@@ -43,8 +65,8 @@ func LoadChallenges() ([]Challenge, error) {
 		return nil, fmt.Errorf("failed to read challenges file: %w", err)
 	}
 
-	var challenges []Challenge
-	if err := json.Unmarshal(data, &challenges); err != nil {
+	challenges, err := decodeChallenges(dataPath, data)
+	if err != nil {
 		return nil, fmt.Errorf("failed to parse challenges JSON: %w", err)
 	}
 
@@ -60,6 +82,40 @@ func FindBySlug(challenges []Challenge, slug string) (Challenge, bool) {
 	return Challenge{}, false
 }
 
+// FindVariants returns the challenges with slug, one per language for a
+// multi-language challenge. A variant is also found by the slug it had
+// before its challenge was merged into a multi-language one, with the
+// language as a suffix or prefix, such as add-two-numbers-typescript or
+// rust-add-two-numbers.
+func FindVariants(challenges []Challenge, slug string) []Challenge {
+	var found []Challenge
+	for _, ch := range challenges {
+		if ch.Slug == slug {
+			found = append(found, ch)
+		}
+	}
+	if len(found) > 0 {
+		return found
+	}
+
+	for _, ch := range challenges {
+		if len(ch.Variants) > 0 && (slug == ch.Slug+"-"+ch.Language || slug == ch.Language+"-"+ch.Slug) {
+			found = append(found, ch)
+		}
+	}
+	return found
+}
+
+// FindVariant returns the challenge with slug in language.
+func FindVariant(challenges []Challenge, slug, language string) (Challenge, bool) {
+	for _, ch := range FindVariants(challenges, slug) {
+		if strings.EqualFold(ch.Language, language) {
+			return ch, true
+		}
+	}
+	return Challenge{}, false
+}
+
 func FilterChallenges(challenges []Challenge, language, difficulty string) []Challenge {
 	var filtered []Challenge
 
@@ -88,20 +144,28 @@ func FilterByLanguage(challenges []Challenge, language string) []Challenge {
 	return filtered
 }
 
-func DisplayChallengeList(challenges []Challenge) {
+// DisplayChallengeList prints a table of challenges with the progress
+// status returned by status for each one.
+func DisplayChallengeList(challenges []Challenge, status func(Challenge) string) {
 	if len(challenges) == 0 {
 		fmt.Println("No challenges found matching the criteria.")
 		return
 	}
 
-	fmt.Printf("%-40s %-12s %-10s %s\n", "TITLE", "LANGUAGE", "DIFFICULTY", "SLUG")
-	fmt.Println(strings.Repeat("-", 80))
+	fmt.Printf("%-40s %-12s %-10s %-10s %-12s %s\n", "TITLE", "LANGUAGE", "DIFFICULTY", "STATUS", "PACK", "SLUG")
+	fmt.Println(strings.Repeat("-", 104))
 
 	for _, ch := range challenges {
-		fmt.Printf("%-40s %-12s %-10s %s\n",
-			truncateString(ch.Title, 40),
+		pack := ch.Pack
+		if pack == "" {
+			pack = "built-in"
+		}
+		fmt.Printf("%-40s %-12s %-10s %-10s %-12s %s\n",
+			TruncateString(ch.Title, 40),
 			ch.Language,
 			ch.Difficulty,
+			status(ch),
+			TruncateString(pack, 12),
 			ch.Slug,
 		)
 	}
@@ -109,7 +173,9 @@ func DisplayChallengeList(challenges []Challenge) {
 	fmt.Printf("\nTotal: %d challenges\n", len(challenges))
 }
 
-func truncateString(s string, maxLen int) string {
+// TruncateString shortens s to maxLen bytes, ending it with "..." if it
+// was cut.
+func TruncateString(s string, maxLen int) string {
 	if len(s) <= maxLen {
 		return s
 	}
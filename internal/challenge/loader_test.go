@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"reflect"
 	"testing"
 )
 
@@ -99,6 +100,61 @@ func TestFindBySlug(t *testing.T) {
 	}
 }
 
+func TestFindVariants(t *testing.T) {
+	data := `[
+  {"title": "Add", "slug": "add", "difficulty": "easy",
+   "testCases": [{"input": [1, 2], "expected": 3}],
+   "conceptTags": ["math"],
+   "variants": [
+     {"language": "go", "functionName": "add", "parameterTypes": ["int", "int"], "template": "func add(a, b int) int {}", "conceptTags": ["variables-go"]},
+     {"language": "python", "functionName": "add", "parameterTypes": ["int", "int"], "template": "def add(a, b):"}
+   ]},
+  {"title": "Other", "slug": "other", "language": "go", "difficulty": "easy", "functionName": "other", "template": "func other() {}",
+   "testCases": [{"input": [], "expected": 1}]}
+]`
+	challenges, err := decodeChallenges("x.json", []byte(data))
+	if err != nil {
+		t.Fatalf("decodeChallenges() failed: %v", err)
+	}
+	if len(challenges) != 3 {
+		t.Fatalf("Expected a challenge per variant and 3 in all, got %d", len(challenges))
+	}
+
+	variants := FindVariants(challenges, "add")
+	if len(variants) != 2 || variants[0].Language != "go" || variants[1].Language != "python" {
+		t.Fatalf("Expected the go and python variants, got %v", variants)
+	}
+	goVariant := variants[0]
+	if goVariant.Template != "func add(a, b int) int {}" || len(goVariant.TestCases) != 1 || goVariant.Label() != "add (go)" {
+		t.Errorf("Unexpected go variant: %+v", goVariant)
+	}
+	if !reflect.DeepEqual(goVariant.ConceptTags, []string{"math", "variables-go"}) {
+		t.Errorf("Go variant concept tags = %v, want the shared tags followed by its own", goVariant.ConceptTags)
+	}
+	if !reflect.DeepEqual(variants[1].ConceptTags, []string{"math"}) {
+		t.Errorf("Python variant concept tags = %v, want the shared tags", variants[1].ConceptTags)
+	}
+	if !reflect.DeepEqual(goVariant.Languages(), []string{"go", "python"}) {
+		t.Errorf("Languages() = %v, want [go python]", goVariant.Languages())
+	}
+
+	// Slugs from before the challenge had variants
+	for _, slug := range []string{"add-python", "python-add"} {
+		if ch, ok := FindVariant(challenges, slug, "python"); !ok || ch.Slug != "add" {
+			t.Errorf("Expected %s to find the python variant of add", slug)
+		}
+	}
+	if found := FindVariants(challenges, "add-rust"); len(found) != 0 {
+		t.Errorf("Expected no variant for add-rust, got %v", found)
+	}
+	if _, ok := FindVariant(challenges, "add", "rust"); ok {
+		t.Error("Expected no rust variant of add")
+	}
+	if ch, ok := FindVariant(challenges, "other", "go"); !ok || ch.Label() != "other" {
+		t.Errorf("Expected to find other in go, got %+v", ch)
+	}
+}
+
 func TestFilterByLanguage(t *testing.T) {
 	challenges := []Challenge{
 		{
@@ -130,3 +186,15 @@ func TestFilterByLanguage(t *testing.T) {
 		t.Errorf("Expected 0 PHP challenges, got %d", len(phpChallenges))
 	}
 }
+
+func TestBuiltinChallengesHaveReferenceSolutions(t *testing.T) {
+	challenges, err := loadBuiltinChallenges()
+	if err != nil {
+		t.Fatalf("loadBuiltinChallenges() failed: %v", err)
+	}
+	for _, ch := range challenges {
+		if ch.ReferenceSolution == "" {
+			t.Errorf("%s has no reference solution", ch.Label())
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package challenge
+
+import (
+	"errors"
+	"fmt"
+	"os"
+)
+
+// LocalTestsFile is the file in a workspace where users add their own test
+// cases.
+const LocalTestsFile = "tests.local.json"
+
+// localTestCases is the format of LocalTestsFile.
+type localTestCases struct {
+	TestCases []TestCase `json:"testCases"`
+}
+
+// localTestsTemplate is the LocalTestsFile of a new workspace.
+const localTestsTemplate = `{
+  "testCases": []
+}
+`
+
+// ParseLocalTestCases strictly decodes the local test cases in data, read
+// from name, and checks them like the test cases of ch. The cases are
+// marked Local.
+func ParseLocalTestCases(name string, data []byte, ch Challenge) ([]TestCase, []Problem) {
+	f := &ChallengeFile{Name: name, data: data, offsets: map[string]int64{}, local: true}
+
+	var local localTestCases
+	problems := f.parse(&local)
+	for i, tc := range local.TestCases {
+		path := fmt.Sprintf("testCases[%d]", i)
+		problems = append(problems, f.validateTestCase(tc, path)...)
+		if _, ok := f.offsets[path+".input"]; ok && tc.TestFunction == "" && len(tc.Input) != len(ch.ParameterTypes) {
+			problems = append(problems, f.problem(path+".input", "input length %d does not match the %d parameterTypes", len(tc.Input), len(ch.ParameterTypes)))
+		}
+		if tc.Hidden {
+			problems = append(problems, f.problem(path+".hidden", "local test cases can't be hidden"))
+		}
+		local.TestCases[i].Local = true
+	}
+	return local.TestCases, problems
+}
+
+// LoadLocalTestCases reads the local test cases for ch from path, joining
+// every problem with them into the returned error. A missing file has no
+// test cases.
+func LoadLocalTestCases(path string, ch Challenge) ([]TestCase, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read local test cases: %w", err)
+	}
+
+	testCases, problems := ParseLocalTestCases(path, data, ch)
+	if len(problems) > 0 {
+		errs := make([]error, len(problems))
+		for i, problem := range problems {
+			errs[i] = problem
+		}
+		return nil, errors.Join(errs...)
+	}
+	return testCases, nil
+}
@@ -0,0 +1,78 @@
+package challenge
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseLocalTestCases(t *testing.T) {
+	ch := Challenge{ParameterTypes: []string{"int", "int"}}
+
+	data := []byte(`{
+  "testCases": [
+    {"input": [1, 2], "expected": 3, "description": "small numbers"},
+    {"input": [0.1, 0.2], "expected": 0.3, "compare": "epsilon"}
+  ]
+}`)
+	testCases, problems := ParseLocalTestCases(LocalTestsFile, data, ch)
+	if len(problems) > 0 {
+		t.Fatalf("Unexpected problems: %v", problems)
+	}
+	if len(testCases) != 2 || !testCases[0].Local || !testCases[1].Local || testCases[1].Tolerance() != DefaultEpsilon {
+		t.Errorf("Unexpected test cases: %+v", testCases)
+	}
+
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{"not an object", `[]`, `tests.local.json:1:1: expected an object with "testCases"`},
+		{"unknown field", `{"cases": []}`, `tests.local.json:1:2: cases: unknown field "cases"`},
+		{"unknown test case field", `{"testCases": [{"input": [1, 2], "expected": 3, "expect": 3}]}`, `testCases[0].expect: unknown field "expect"`},
+		{"missing expected", `{"testCases": [{"input": [1, 2]}]}`, "testCases[0].expected: expected is required"},
+		{"input length", "{\"testCases\": [\n  {\"input\": [1], \"expected\": 1}\n]}", "tests.local.json:2:13: testCases[0].input: input length 1 does not match the 2 parameterTypes"},
+		{"compare mode", `{"testCases": [{"input": [1, 2], "expected": 3, "compare": "close"}]}`, `compare "close" must be one of`},
+		{"hidden", `{"testCases": [{"input": [1, 2], "expected": 3, "hidden": true}]}`, "local test cases can't be hidden"},
+		{"wrong type", `{"testCases": [{"input": 1, "expected": 3}]}`, "testCases[0].input: cannot use number"},
+		{"syntax error", `{"testCases": [}`, "invalid character"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, problems := ParseLocalTestCases(LocalTestsFile, []byte(tt.data), ch)
+			var messages []string
+			for _, problem := range problems {
+				messages = append(messages, problem.Error())
+			}
+			if !strings.Contains(strings.Join(messages, "\n"), tt.want) {
+				t.Errorf("Expected a problem containing %q, got %q", tt.want, messages)
+			}
+		})
+	}
+}
+
+func TestLoadLocalTestCases(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, LocalTestsFile)
+
+	if testCases, err := LoadLocalTestCases(path, Challenge{}); err != nil || testCases != nil {
+		t.Errorf("Expected no test cases without the file, got %v, %v", testCases, err)
+	}
+
+	if err := os.WriteFile(path, []byte(localTestsTemplate), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if testCases, err := LoadLocalTestCases(path, Challenge{}); err != nil || len(testCases) != 0 {
+		t.Errorf("Expected the template to have no test cases, got %v, %v", testCases, err)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"testCases": [{"input": []}, {"expected": 1}]}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	_, err := LoadLocalTestCases(path, Challenge{})
+	if err == nil || !strings.Contains(err.Error(), "testCases[0].expected") || !strings.Contains(err.Error(), "testCases[1].input") {
+		t.Errorf("Expected every problem in the error, got %v", err)
+	}
+}
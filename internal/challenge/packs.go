@@ -0,0 +1,152 @@
+package challenge
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// PackEnv is the environment variable listing challenge pack paths,
+// separated like PATH.
+const PackEnv = "CODEQUEST_PATH"
+
+// PackFile is the file holding a pack's challenges in a pack directory.
+const PackFile = "challenges.json"
+
+// packSeparator joins a pack name and a challenge slug into the slug of a
+// pack challenge, such as "team/two-sum".
+const packSeparator = "/"
+
+// Pack is a set of challenges loaded from outside the binary.
+type Pack struct {
+	// Name namespaces the slugs of the pack's challenges
+	Name string
+	// Path is the pack directory or challenges file
+	Path       string
+	Challenges []Challenge
+}
+
+// packPaths are the packs LoadChallenges merges with the built-in
+// challenges.
+var packPaths []string
+
+// RegisterPacks sets the packs that LoadChallenges loads, each a directory
+// containing a challenges.json file or a challenges file itself.
+func RegisterPacks(paths []string) {
+	packPaths = paths
+}
+
+// PackName returns the name of the pack at path: the directory name for a
+// pack directory or a challenges.json file, otherwise the file name without
+// its extension.
+func PackName(path string) string {
+	path = filepath.Clean(path)
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		return filepath.Base(path)
+	}
+	if filepath.Base(path) == PackFile {
+		return filepath.Base(filepath.Dir(path))
+	}
+	return strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+}
+
+// LoadPack reads the pack at path. The slug of each challenge is prefixed
+// with the pack name so that it can't collide with a built-in challenge or
+// one from another pack. The pack name and the slugs must be lowercase
+// words separated by hyphens, as they name the challenge's workspace.
+func LoadPack(path string) (Pack, error) {
+	name := PackName(path)
+	if !slugPattern.MatchString(name) {
+		return Pack{}, fmt.Errorf("pack %s: pack name %q must be lowercase words separated by hyphens", path, name)
+	}
+
+	challenges, err := ReadChallenges(path)
+	if err != nil {
+		return Pack{}, fmt.Errorf("pack %s: %w", path, err)
+	}
+
+	for i := range challenges {
+		ch := &challenges[i]
+		if ch.Slug == "" {
+			return Pack{}, fmt.Errorf("pack %s: challenge %d has no slug", path, i+1)
+		}
+		if !slugPattern.MatchString(ch.Slug) {
+			return Pack{}, fmt.Errorf("pack %s: challenge %d: slug %q must be lowercase words separated by hyphens", path, i+1, ch.Slug)
+		}
+		ch.Slug = name + packSeparator + ch.Slug
+		ch.Pack = name
+	}
+
+	return Pack{Name: name, Path: path, Challenges: challenges}, nil
+}
+
+// ReadChallenges reads the challenges of the pack at path as written, without
+// namespacing their slugs.
+func ReadChallenges(path string) ([]Challenge, error) {
+	file, data, err := readPackFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return decodeChallenges(file, data)
+}
+
+// ValidatePack reports every problem in the challenges of the pack at path.
+// It only fails if the pack can't be read.
+func ValidatePack(path string, opts ValidateOptions) ([]Problem, error) {
+	file, data, err := readPackFile(path)
+	if err != nil {
+		return nil, err
+	}
+	f, problems := ParseChallengeFile(file, data)
+	return append(problems, f.Validate(opts)...), nil
+}
+
+// readPackFile returns the challenges file of the pack at path and its
+// contents.
+func readPackFile(path string) (string, []byte, error) {
+	file := path
+	if info, err := os.Stat(path); err != nil {
+		return "", nil, err
+	} else if info.IsDir() {
+		file = filepath.Join(path, PackFile)
+	}
+	data, err := os.ReadFile(file)
+	return file, data, err
+}
+
+// LoadPacks reads the packs at paths, skipping repeated paths. A pack that
+// fails to load, or has the name of a pack before it, is left out and its
+// error joined into the returned one, so the other packs stay usable.
+func LoadPacks(paths []string) ([]Pack, error) {
+	var packs []Pack
+	var errs []error
+	seen := map[string]bool{}
+	byName := map[string]string{}
+	for _, path := range paths {
+		if path == "" {
+			continue
+		}
+		if abs, err := filepath.Abs(path); err == nil {
+			path = abs
+		}
+		if seen[path] {
+			continue
+		}
+		seen[path] = true
+
+		pack, err := LoadPack(path)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if other, ok := byName[pack.Name]; ok {
+			errs = append(errs, fmt.Errorf("packs %s and %s are both named %q", other, path, pack.Name))
+			continue
+		}
+		byName[pack.Name] = path
+		packs = append(packs, pack)
+	}
+	return packs, errors.Join(errs...)
+}
@@ -0,0 +1,140 @@
+package challenge
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testPack = `[{"title": "Two Sum", "slug": "two-sum", "language": "go", "difficulty": "easy", "functionName": "twoSum"}]`
+
+func writePack(t *testing.T, path string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("Failed to create pack directory: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(testPack), 0644); err != nil {
+		t.Fatalf("Failed to write pack: %v", err)
+	}
+}
+
+func TestLoadPack(t *testing.T) {
+	dir := t.TempDir()
+	teamDir := filepath.Join(dir, "team")
+	writePack(t, filepath.Join(teamDir, PackFile))
+	extraFile := filepath.Join(dir, "extra.json")
+	writePack(t, extraFile)
+
+	for path, name := range map[string]string{
+		teamDir:                          "team",
+		filepath.Join(teamDir, PackFile): "team",
+		extraFile:                        "extra",
+	} {
+		pack, err := LoadPack(path)
+		if err != nil {
+			t.Fatalf("LoadPack(%s) failed: %v", path, err)
+		}
+		if pack.Name != name || len(pack.Challenges) != 1 {
+			t.Fatalf("Unexpected pack from %s: %+v", path, pack)
+		}
+		ch := pack.Challenges[0]
+		if ch.Slug != name+"/two-sum" || ch.Pack != name {
+			t.Errorf("Challenge from %s not namespaced: slug %q, pack %q", path, ch.Slug, ch.Pack)
+		}
+	}
+
+	if _, err := LoadPack(filepath.Join(dir, "missing")); err == nil {
+		t.Error("Expected an error for a missing pack")
+	}
+}
+
+func TestLoadPacks(t *testing.T) {
+	dir := t.TempDir()
+	first := filepath.Join(dir, "a", "team")
+	second := filepath.Join(dir, "b", "team")
+	writePack(t, filepath.Join(first, PackFile))
+	writePack(t, filepath.Join(second, PackFile))
+
+	// A repeated path is loaded once
+	packs, err := LoadPacks([]string{first, "", first})
+	if err != nil {
+		t.Fatalf("LoadPacks() failed: %v", err)
+	}
+	if len(packs) != 1 {
+		t.Errorf("Expected 1 pack, got %d", len(packs))
+	}
+
+	// A pack that fails to load is left out, keeping the others
+	packs, err = LoadPacks([]string{first, second, filepath.Join(dir, "missing")})
+	if err == nil || !strings.Contains(err.Error(), `both named "team"`) || !strings.Contains(err.Error(), "missing") {
+		t.Errorf("Expected a name collision and a missing pack error, got %v", err)
+	}
+	if len(packs) != 1 || packs[0].Path != first {
+		t.Errorf("Expected only the first pack, got %+v", packs)
+	}
+}
+
+func TestLoadPackRejectsUnsafeSlugs(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"escape.json":    `[{"title": "Escape", "slug": "../../escape", "language": "go", "functionName": "f"}]`,
+		"nested.json":    `[{"title": "Nested", "slug": "a/b", "language": "go", "functionName": "f"}]`,
+		"Team Pack.json": testPack,
+	} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadPack(path); err == nil || !strings.Contains(err.Error(), "lowercase words separated by hyphens") {
+			t.Errorf("Expected %s to be rejected, got %v", name, err)
+		}
+	}
+}
+
+func TestLoadChallengesWithPacks(t *testing.T) {
+	builtin, err := LoadChallenges()
+	if err != nil {
+		t.Fatalf("LoadChallenges() failed: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "team.json")
+	writePack(t, path)
+	RegisterPacks([]string{path})
+	defer RegisterPacks(nil)
+
+	challenges, err := LoadChallenges()
+	if err != nil {
+		t.Fatalf("LoadChallenges() failed: %v", err)
+	}
+	if len(challenges) != len(builtin)+1 {
+		t.Fatalf("Expected %d challenges, got %d", len(builtin)+1, len(challenges))
+	}
+	if _, ok := FindBySlug(challenges, "team/two-sum"); !ok {
+		t.Error("Pack challenge not found by its namespaced slug")
+	}
+}
+
+func TestLoadChallengesSkipsBrokenPacks(t *testing.T) {
+	builtin, err := LoadChallenges()
+	if err != nil {
+		t.Fatalf("LoadChallenges() failed: %v", err)
+	}
+
+	var warnings strings.Builder
+	packWarnings = &warnings
+	defer func() { packWarnings = os.Stderr }()
+	RegisterPacks([]string{filepath.Join(t.TempDir(), "missing")})
+	defer RegisterPacks(nil)
+
+	challenges, err := LoadChallenges()
+	if err != nil {
+		t.Fatalf("LoadChallenges() failed because of a broken pack: %v", err)
+	}
+	if len(challenges) != len(builtin) {
+		t.Errorf("Expected the %d built-in challenges, got %d", len(builtin), len(challenges))
+	}
+	if !strings.Contains(warnings.String(), "missing") {
+		t.Errorf("Expected a warning about the missing pack, got %q", warnings.String())
+	}
+}
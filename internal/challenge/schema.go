@@ -0,0 +1,459 @@
+package challenge
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Difficulties are the valid values of Challenge.Difficulty, from easiest
+// to hardest.
+var Difficulties = []string{"easy", "medium", "hard"}
+
+// CompareModes are the valid values of TestCase.Compare.
+var CompareModes = []string{CompareDeep, CompareExact, CompareUnordered, CompareEpsilon, CompareError, CompareRegex}
+
+var (
+	slugPattern          = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+	challengePath        = regexp.MustCompile(`^\[\d+\]$`)
+	testCasePath         = regexp.MustCompile(`^\[\d+\]\.testCases\[\d+\]$`)
+	variantPath          = regexp.MustCompile(`^\[\d+\]\.variants\[\d+\]$`)
+	challengeFields      = jsonFields(reflect.TypeOf(Challenge{}))
+	testCaseFields       = jsonFields(reflect.TypeOf(TestCase{}))
+	variantFields        = jsonFields(reflect.TypeOf(Variant{}))
+	errNotChallengeArray = errors.New("expected an array of challenges")
+
+	localTestCasePath     = regexp.MustCompile(`^testCases\[\d+\]$`)
+	localFields           = jsonFields(reflect.TypeOf(localTestCases{}))
+	errNotTestCasesObject = errors.New(`expected an object with "testCases"`)
+)
+
+// Problem is a mistake in a challenges file.
+type Problem struct {
+	File   string
+	Line   int
+	Column int
+	// Path locates the value in the file, such as [2].testCases[0].input
+	Path    string
+	Message string
+}
+
+func (p Problem) Error() string {
+	location := fmt.Sprintf("%s:%d:%d", p.File, p.Line, p.Column)
+	if p.Path == "" {
+		return location + ": " + p.Message
+	}
+	return location + ": " + p.Path + ": " + p.Message
+}
+
+// ChallengeFile is a parsed challenges file that remembers where each value
+// is, so that problems can point at it.
+type ChallengeFile struct {
+	Name       string
+	Challenges []Challenge
+	data       []byte
+	// offsets maps the path of every value to its byte offset
+	offsets map[string]int64
+	// local is set for a file of local test cases rather than challenges
+	local bool
+}
+
+// ParseChallengeFile strictly decodes the challenges file data read from
+// name. Syntax errors, values of the wrong type and fields that aren't part
+// of Challenge, Variant or TestCase are reported as problems. The returned file has
+// no challenges after a syntax or type error. Multi-language challenges are
+// left as written, with their variants.
+func ParseChallengeFile(name string, data []byte) (*ChallengeFile, []Problem) {
+	f := &ChallengeFile{Name: name, data: data, offsets: map[string]int64{}}
+	return f, f.parse(&f.Challenges)
+}
+
+// parse strictly decodes the file into v, which is left empty after a
+// syntax or type error.
+func (f *ChallengeFile) parse(v interface{}) []Problem {
+	var problems []Problem
+	dec := json.NewDecoder(bytes.NewReader(f.data))
+	if err := f.walk(dec, "", &problems); err != nil {
+		var syntaxErr *json.SyntaxError
+		switch {
+		case errors.As(err, &syntaxErr):
+			// Offset counts the byte that caused the error
+			return append(problems, f.problemAt(max(syntaxErr.Offset-1, 0), "", syntaxErr.Error()))
+		case errors.Is(err, errNotChallengeArray), errors.Is(err, errNotTestCasesObject):
+			return append(problems, f.problemAt(0, "", err.Error()))
+		default:
+			return append(problems, f.problemAt(dec.InputOffset(), "", "unexpected end of JSON input"))
+		}
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		message := "unexpected data after the challenges array"
+		if f.local {
+			message = "unexpected data after the test cases object"
+		}
+		return append(problems, f.problemAt(dec.InputOffset(), "", message))
+	}
+
+	if err := json.Unmarshal(f.data, v); err != nil {
+		reflect.ValueOf(v).Elem().SetZero()
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			return append(problems, f.problemAt(typeErr.Offset, fieldPath(typeErr.Field), fmt.Sprintf("cannot use %s as %s", typeErr.Value, typeErr.Type)))
+		}
+		return append(problems, f.problemAt(0, "", err.Error()))
+	}
+	return problems
+}
+
+// fieldPath converts the dotted field of a json.UnmarshalTypeError, such as
+// 0.testCases.1.input, to a path like [0].testCases[1].input.
+func fieldPath(field string) string {
+	var path strings.Builder
+	for _, segment := range strings.Split(field, ".") {
+		if _, err := strconv.Atoi(segment); err == nil {
+			path.WriteString("[" + segment + "]")
+		} else {
+			path.WriteString("." + segment)
+		}
+	}
+	return strings.TrimPrefix(path.String(), ".")
+}
+
+// decodeChallenges strictly decodes a challenges file, joining every problem
+// into the returned error. Multi-language challenges are expanded into one
+// challenge per variant.
+func decodeChallenges(name string, data []byte) ([]Challenge, error) {
+	f, problems := ParseChallengeFile(name, data)
+	if len(problems) > 0 {
+		errs := make([]error, len(problems))
+		for i, problem := range problems {
+			errs[i] = problem
+		}
+		return nil, errors.Join(errs...)
+	}
+	return expandVariants(f.Challenges), nil
+}
+
+// walk reads the value at path from dec, recording the offset of it and of
+// every value within it, and reports object fields that the schema doesn't
+// define.
+func (f *ChallengeFile) walk(dec *json.Decoder, path string, problems *[]Problem) error {
+	f.offsets[path] = f.skipSpace(dec.InputOffset())
+	token, err := dec.Token()
+	if err != nil {
+		return err
+	}
+
+	delim, ok := token.(json.Delim)
+	switch {
+	case path == "" && f.local && delim != '{':
+		return errNotTestCasesObject
+	case path == "" && !f.local && delim != '[':
+		return errNotChallengeArray
+	}
+	if !ok {
+		return nil
+	}
+
+	switch delim {
+	case '[':
+		for i := 0; dec.More(); i++ {
+			if err := f.walk(dec, fmt.Sprintf("%s[%d]", path, i), problems); err != nil {
+				return err
+			}
+		}
+	case '{':
+		var fields map[string]bool
+		switch {
+		case f.local && path == "":
+			fields = localFields
+		case f.local && localTestCasePath.MatchString(path):
+			fields = testCaseFields
+		case challengePath.MatchString(path):
+			fields = challengeFields
+		case testCasePath.MatchString(path):
+			fields = testCaseFields
+		case variantPath.MatchString(path):
+			fields = variantFields
+		}
+		for dec.More() {
+			offset := f.skipSpace(dec.InputOffset())
+			token, err := dec.Token()
+			if err != nil {
+				return err
+			}
+			key, _ := token.(string)
+			child := path + "." + key
+			if path == "" {
+				child = key
+			}
+			if fields != nil && !fields[key] {
+				*problems = append(*problems, f.problemAt(offset, child, fmt.Sprintf("unknown field %q", key)))
+			}
+			if err := f.walk(dec, child, problems); err != nil {
+				return err
+			}
+		}
+	}
+	// The closing delimiter
+	_, err = dec.Token()
+	return err
+}
+
+// skipSpace returns the offset of the first byte at or after offset that
+// isn't whitespace or a separator.
+func (f *ChallengeFile) skipSpace(offset int64) int64 {
+	for offset < int64(len(f.data)) && strings.IndexByte(" \t\r\n,:", f.data[offset]) >= 0 {
+		offset++
+	}
+	return offset
+}
+
+// problemAt reports message at the byte offset in the file.
+func (f *ChallengeFile) problemAt(offset int64, path, message string) Problem {
+	offset = min(offset, int64(len(f.data)))
+	before := f.data[:offset]
+	line := bytes.Count(before, []byte("\n")) + 1
+	column := int(offset) - bytes.LastIndexByte(before, '\n')
+	return Problem{File: f.Name, Line: line, Column: column, Path: path, Message: message}
+}
+
+// problem reports message at path, or at the closest enclosing value that
+// was in the file.
+func (f *ChallengeFile) problem(path, format string, args ...interface{}) Problem {
+	located := path
+	for {
+		if offset, ok := f.offsets[located]; ok {
+			return f.problemAt(offset, path, fmt.Sprintf(format, args...))
+		}
+		i := strings.LastIndexAny(located, ".[")
+		if i < 0 {
+			return f.problemAt(0, path, fmt.Sprintf(format, args...))
+		}
+		located = located[:i]
+	}
+}
+
+// ValidateOptions are the outside facts Validate checks challenges against.
+type ValidateOptions struct {
+	// Language reports whether a language id can be run. Nil accepts any.
+	Language func(id string) bool
+	// Concepts, if not nil, are the concepts that concept tags must name.
+	Concepts []Concept
+}
+
+// Validate reports every challenge that is missing a required field, has a
+// duplicate slug, an unknown language, difficulty, concept tag or
+// comparison mode, a function name that isn't in its template or reference
+// solution, or a test case whose input doesn't match the parameter types.
+// The language-specific fields of a multi-language challenge are checked in
+// each of its variants.
+func (f *ChallengeFile) Validate(opts ValidateOptions) []Problem {
+	var problems []Problem
+	report := func(path, format string, args ...interface{}) {
+		problems = append(problems, f.problem(path, format, args...))
+	}
+
+	var concepts map[string]bool
+	if opts.Concepts != nil {
+		concepts = map[string]bool{}
+		for _, concept := range opts.Concepts {
+			concepts[concept.Slug] = true
+		}
+	}
+	checkConcepts := func(path string, tags []string) {
+		for j, tag := range tags {
+			if concepts != nil && !concepts[tag] {
+				report(fmt.Sprintf("%s.conceptTags[%d]", path, j), "unknown concept %q", tag)
+			}
+		}
+	}
+
+	slugs := map[string]int{}
+	for i, ch := range f.Challenges {
+		path := fmt.Sprintf("[%d]", i)
+
+		// The language-specific fields are in the challenge itself, or in
+		// each variant of a multi-language challenge
+		type target struct {
+			path string
+			ch   Challenge
+			// of names the variant in messages about test cases
+			of string
+		}
+		targets := []target{{path: path, ch: ch}}
+		if len(ch.Variants) > 0 {
+			targets = nil
+			for j := range ch.Variants {
+				variant := ch.variant(j)
+				targets = append(targets, target{fmt.Sprintf("%s.variants[%d]", path, j), variant, fmt.Sprintf(" of the %s variant", variant.Language)})
+			}
+			for _, field := range []string{"language", "functionName", "parameterTypes", "returnType", "template", "referenceSolution"} {
+				if _, ok := f.offsets[path+"."+field]; ok {
+					report(path+"."+field, "%s is set in each variant of a multi-language challenge", field)
+				}
+			}
+		}
+
+		for _, r := range []struct{ field, value string }{{"title", ch.Title}, {"slug", ch.Slug}} {
+			if strings.TrimSpace(r.value) == "" {
+				report(path+"."+r.field, "%s is required", r.field)
+			}
+		}
+		for _, t := range targets {
+			required := []struct{ field, value string }{
+				{"language", t.ch.Language},
+				{"functionName", t.ch.FunctionName},
+				{"template", t.ch.Template},
+			}
+			for _, r := range required {
+				if strings.TrimSpace(r.value) == "" {
+					report(t.path+"."+r.field, "%s is required", r.field)
+				}
+			}
+		}
+
+		if ch.Slug != "" {
+			if !slugPattern.MatchString(ch.Slug) {
+				report(path+".slug", "slug %q must be lowercase words separated by hyphens", ch.Slug)
+			}
+			if first, ok := slugs[ch.Slug]; ok {
+				report(path+".slug", "slug %q is already used by challenge [%d]", ch.Slug, first)
+			} else {
+				slugs[ch.Slug] = i
+			}
+		}
+		languages := map[string]string{}
+		for _, t := range targets {
+			if t.ch.Language == "" {
+				continue
+			}
+			if opts.Language != nil && !opts.Language(t.ch.Language) {
+				report(t.path+".language", "unknown language %q", t.ch.Language)
+			}
+			if first, ok := languages[t.ch.Language]; ok {
+				report(t.path+".language", "language %q already has variant %s", t.ch.Language, strings.TrimPrefix(first, path+".variants"))
+			} else {
+				languages[t.ch.Language] = t.path
+			}
+		}
+		if !oneOf(Difficulties, ch.Difficulty) {
+			report(path+".difficulty", "difficulty %q must be one of %s", ch.Difficulty, strings.Join(Difficulties, ", "))
+		}
+		for _, t := range targets {
+			if t.ch.FunctionName != "" && t.ch.Template != "" && !declares(t.ch.Template, t.ch.FunctionName) {
+				report(t.path+".functionName", "function %q is not declared in the template", t.ch.FunctionName)
+			}
+			if t.ch.FunctionName != "" && t.ch.ReferenceSolution != "" && !declares(t.ch.ReferenceSolution, t.ch.FunctionName) {
+				report(t.path+".referenceSolution", "function %q is not declared in the reference solution", t.ch.FunctionName)
+			}
+		}
+		if ch.TimeLimit < 0 {
+			report(path+".timeLimit", "timeLimit must not be negative")
+		}
+		if ch.MemoryLimit < 0 {
+			report(path+".memoryLimit", "memoryLimit must not be negative")
+		}
+		checkConcepts(path, ch.ConceptTags)
+		for j, variant := range ch.Variants {
+			checkConcepts(fmt.Sprintf("%s.variants[%d]", path, j), variant.ConceptTags)
+		}
+
+		if len(ch.TestCases) == 0 {
+			report(path+".testCases", "at least one test case is required")
+		} else if len(ch.SampleCases()) == 0 {
+			report(path+".testCases", "at least one test case must not be hidden")
+		}
+		for j, tc := range ch.TestCases {
+			tcPath := fmt.Sprintf("%s.testCases[%d]", path, j)
+			problems = append(problems, f.validateTestCase(tc, tcPath)...)
+			if tc.TestFunction != "" && len(ch.Variants) > 0 {
+				report(tcPath+".testFunction", "testFunction is written in one language and can't be shared by the variants of a multi-language challenge")
+			}
+			if _, ok := f.offsets[tcPath+".input"]; !ok || tc.TestFunction != "" {
+				continue
+			}
+			for _, t := range targets {
+				if len(tc.Input) != len(t.ch.ParameterTypes) {
+					report(tcPath+".input", "input length %d does not match the %d parameterTypes%s", len(tc.Input), len(t.ch.ParameterTypes), t.of)
+				}
+			}
+		}
+	}
+	return problems
+}
+
+func (f *ChallengeFile) validateTestCase(tc TestCase, path string) []Problem {
+	var problems []Problem
+	report := func(path, format string, args ...interface{}) {
+		problems = append(problems, f.problem(path, format, args...))
+	}
+
+	if _, ok := f.offsets[path+".input"]; !ok {
+		report(path+".input", "input is required")
+	}
+	if _, ok := f.offsets[path+".expected"]; !ok {
+		report(path+".expected", "expected is required")
+	}
+
+	mode := tc.CompareMode()
+	if !oneOf(CompareModes, mode) {
+		report(path+".compare", "compare %q must be one of %s", tc.Compare, strings.Join(CompareModes, ", "))
+	}
+	if tc.Epsilon < 0 {
+		report(path+".epsilon", "epsilon must not be negative")
+	} else if tc.Epsilon > 0 && mode != CompareEpsilon {
+		report(path+".epsilon", "epsilon is only used with compare %q", CompareEpsilon)
+	}
+	switch mode {
+	case CompareRegex:
+		pattern, ok := tc.Expected.(string)
+		if !ok {
+			report(path+".expected", "must be a pattern string with compare %q", CompareRegex)
+		} else if _, err := regexp.Compile(pattern); err != nil {
+			report(path+".expected", "invalid pattern: %v", err)
+		}
+	case CompareError:
+		if _, ok := tc.Expected.(string); !ok && tc.Expected != nil {
+			report(path+".expected", "must be an error message or null with compare %q", CompareError)
+		}
+	}
+	return problems
+}
+
+// declares reports whether code mentions function. Associated functions
+// such as Stack::new are declared by their last segment.
+func declares(code, function string) bool {
+	segments := strings.FieldsFunc(function, func(r rune) bool { return r == ':' || r == '.' })
+	return len(segments) > 0 && strings.Contains(code, segments[len(segments)-1])
+}
+
+func oneOf(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonFields returns the JSON field names of struct type t.
+func jsonFields(t reflect.Type) map[string]bool {
+	fields := map[string]bool{}
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name == "-" || !t.Field(i).IsExported() {
+			continue
+		}
+		if name == "" {
+			name = t.Field(i).Name
+		}
+		fields[name] = true
+	}
+	return fields
+}
@@ -0,0 +1,182 @@
+package challenge
+
+import (
+	"encoding/json"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestEmbeddedChallengesAreValid(t *testing.T) {
+	f, problems := ParseChallengeFile("challenges.json", challengesData)
+	concepts, err := LoadConcepts()
+	if err != nil {
+		t.Fatalf("LoadConcepts() failed: %v", err)
+	}
+	problems = append(problems, f.Validate(ValidateOptions{Concepts: concepts})...)
+	for _, problem := range problems {
+		t.Error(problem)
+	}
+}
+
+func TestParseChallengeFile(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{"unknown field", "[\n  {\"slug\": \"a\", \"tittle\": \"A\"}\n]", `x.json:2:17: [0].tittle: unknown field "tittle"`},
+		{"unknown test case field", `[{"testCases": [{"input": [], "expectd": 1}]}]`, `x.json:1:31: [0].testCases[0].expectd: unknown field "expectd"`},
+		{"wrong type", `[{"testCases": [{"input": [], "epsilon": "small"}]}]`, "[0].testCases[0].epsilon: cannot use string as float64"},
+		{"syntax error", "[\n  {\"slug\": }\n]", "x.json:2:12: "},
+		{"not an array", `{"slug": "a"}`, "x.json:1:1: expected an array of challenges"},
+		{"truncated", `[{"slug": "a"`, "unexpected end of JSON input"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, problems := ParseChallengeFile("x.json", []byte(tt.data))
+			if len(problems) != 1 || !strings.Contains(problems[0].Error(), tt.want) {
+				t.Errorf("Expected one problem containing %q, got %v", tt.want, problems)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	data := `[
+  {"title": "Sum", "slug": "sum", "language": "go", "difficulty": "easy", "functionName": "sum",
+   "parameterTypes": ["int", "int"], "template": "func sum(a, b int) int {}", "conceptTags": ["loops"],
+   "testCases": [{"input": [1, 2], "expected": 3}, {"input": [1], "expected": 1}]},
+  {"title": "Sum", "slug": "sum", "language": "cobol", "difficulty": "trivial", "functionName": "add",
+   "template": "func sum() {}", "conceptTags": ["nope"],
+   "testCases": [{"input": [], "expected": "x(", "compare": "regex"}, {"input": [], "expected": 1, "compare": "fuzzy", "epsilon": 0.1}]},
+  {"slug": "Bad Slug", "difficulty": "easy", "testCases": []}
+]`
+	f, problems := ParseChallengeFile("x.json", []byte(data))
+	if len(problems) > 0 {
+		t.Fatalf("ParseChallengeFile() failed: %v", problems)
+	}
+	problems = f.Validate(ValidateOptions{
+		Language: func(id string) bool { return id == "go" },
+		Concepts: []Concept{{Slug: "loops"}},
+	})
+
+	var got []string
+	for _, problem := range problems {
+		got = append(got, problem.Path)
+	}
+	want := []string{
+		"[0].testCases[1].input",
+		"[1].slug",
+		"[1].language",
+		"[1].difficulty",
+		"[1].functionName",
+		"[1].conceptTags[0]",
+		"[1].testCases[0].expected",
+		"[1].testCases[1].compare",
+		"[1].testCases[1].epsilon",
+		"[2].title",
+		"[2].language",
+		"[2].functionName",
+		"[2].template",
+		"[2].slug",
+		"[2].testCases",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Problem paths = %v, want %v", got, want)
+	}
+
+	// Missing fields are reported at the challenge
+	if problems[9].Line != 8 || problems[9].Column != 3 {
+		t.Errorf("Missing title reported at %d:%d, want 8:3", problems[9].Line, problems[9].Column)
+	}
+}
+
+func TestValidateHiddenCases(t *testing.T) {
+	data := `[{"title": "A", "slug": "a", "language": "go", "difficulty": "easy", "functionName": "a", "template": "func a() {}",
+  "testCases": [{"input": [], "expected": 1, "hidden": true}]}]`
+	f, problems := ParseChallengeFile("x.json", []byte(data))
+	if len(problems) > 0 {
+		t.Fatalf("ParseChallengeFile() failed: %v", problems)
+	}
+	problems = f.Validate(ValidateOptions{})
+	if len(problems) != 1 || problems[0].Message != "at least one test case must not be hidden" {
+		t.Errorf("Expected a problem for a challenge without sample cases, got %v", problems)
+	}
+}
+
+func TestValidateVariants(t *testing.T) {
+	data := `[{"title": "Add", "slug": "add", "difficulty": "easy", "language": "go",
+  "testCases": [{"input": [1, 2], "expected": 3}, {"input": [1], "expected": 1, "testFunction": "def test(a): return a"}],
+  "variants": [
+    {"language": "go", "functionName": "add", "parameterTypes": ["int", "int"], "template": "func add(a, b int) int {}"},
+    {"language": "python", "functionName": "plus", "parameterTypes": ["int"], "template": "def add(a, b):", "conceptTags": ["nope"]},
+    {"language": "go", "template": "func add() {}"}
+  ]}]`
+	f, problems := ParseChallengeFile("x.json", []byte(data))
+	if len(problems) > 0 {
+		t.Fatalf("ParseChallengeFile() failed: %v", problems)
+	}
+	problems = f.Validate(ValidateOptions{Concepts: []Concept{}})
+
+	var got []string
+	for _, problem := range problems {
+		got = append(got, problem.Path+": "+problem.Message)
+	}
+	want := []string{
+		"[0].language: language is set in each variant of a multi-language challenge",
+		"[0].variants[2].functionName: functionName is required",
+		`[0].variants[2].language: language "go" already has variant [0]`,
+		`[0].variants[1].functionName: function "plus" is not declared in the template`,
+		`[0].variants[1].conceptTags[0]: unknown concept "nope"`,
+		"[0].testCases[0].input: input length 2 does not match the 1 parameterTypes of the python variant",
+		"[0].testCases[0].input: input length 2 does not match the 0 parameterTypes of the go variant",
+		"[0].testCases[1].testFunction: testFunction is written in one language and can't be shared by the variants of a multi-language challenge",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Problems = %q, want %q", got, want)
+	}
+}
+
+// The JSON Schema for editors must describe the same fields and values as
+// the types.
+func TestSchemaMatchesTypes(t *testing.T) {
+	data, err := os.ReadFile("../../data/challenges.schema.json")
+	if err != nil {
+		t.Fatalf("Failed to read schema: %v", err)
+	}
+	var schema struct {
+		Defs map[string]struct {
+			Properties map[string]struct {
+				Enum []string `json:"enum"`
+			} `json:"properties"`
+		} `json:"$defs"`
+	}
+	if err := json.Unmarshal(data, &schema); err != nil {
+		t.Fatalf("Failed to parse schema: %v", err)
+	}
+
+	for def, fields := range map[string]map[string]bool{"challenge": challengeFields, "testCase": testCaseFields, "variant": variantFields} {
+		var inSchema, inType []string
+		for name := range schema.Defs[def].Properties {
+			inSchema = append(inSchema, name)
+		}
+		for name := range fields {
+			inType = append(inType, name)
+		}
+		sort.Strings(inSchema)
+		sort.Strings(inType)
+		if !reflect.DeepEqual(inSchema, inType) {
+			t.Errorf("Schema %s properties = %v, want %v", def, inSchema, inType)
+		}
+	}
+
+	if got := schema.Defs["challenge"].Properties["difficulty"].Enum; !reflect.DeepEqual(got, Difficulties) {
+		t.Errorf("Schema difficulties = %v, want %v", got, Difficulties)
+	}
+	if got := schema.Defs["testCase"].Properties["compare"].Enum; !reflect.DeepEqual(got, CompareModes) {
+		t.Errorf("Schema compare modes = %v, want %v", got, CompareModes)
+	}
+}
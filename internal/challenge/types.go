@@ -1,9 +1,69 @@
 package challenge
 
+import "fmt"
+
+// Comparison modes for TestCase.Compare
+const (
+	// CompareDeep compares structurally: map key order is ignored, nil and
+	// empty collections are equal and numbers compare by value. It is the
+	// default when Compare is empty.
+	CompareDeep = "deep"
+	// CompareExact requires the result to match exactly, including its type.
+	CompareExact = "exact"
+	// CompareUnordered treats a list result as a multiset.
+	CompareUnordered = "unordered"
+	// CompareEpsilon compares numbers within TestCase.Epsilon.
+	CompareEpsilon = "epsilon"
+	// CompareError expects the function to fail with Expected as the error
+	// message, or to succeed when Expected is null. For Go the last return
+	// value must be an error.
+	CompareError = "error"
+	// CompareRegex matches a string result against the Expected pattern.
+	CompareRegex = "regex"
+)
+
+// DefaultEpsilon is the tolerance used by CompareEpsilon when a test case
+// does not set one.
+const DefaultEpsilon = 1e-9
+
 type TestCase struct {
 	Input       []interface{} `json:"input"`
 	Expected    interface{}   `json:"expected"`
 	Description string        `json:"description"`
+	Compare     string        `json:"compare,omitempty"`
+	Epsilon     float64       `json:"epsilon,omitempty"`
+	// TestFunction is source code in the challenge language defining a
+	// function named test, which is called with Input instead of the
+	// challenge's FunctionName.
+	TestFunction string `json:"testFunction,omitempty"`
+	// Hidden cases are left out of the README and only run by codequest
+	// submit and codequest test --all, which report whether they pass but
+	// not their input or result.
+	Hidden bool `json:"hidden,omitempty"`
+	// Local cases were written by the user in the workspace, see
+	// LoadLocalTestCases.
+	Local bool `json:"-"`
+}
+
+// CompareMode returns the comparison mode for the test case, defaulting to
+// CompareDeep.
+func (tc TestCase) CompareMode() string {
+	if tc.Compare == "" {
+		return CompareDeep
+	}
+	return tc.Compare
+}
+
+// Tolerance returns the numeric tolerance for the test case: Epsilon (or
+// DefaultEpsilon) in CompareEpsilon mode, zero otherwise.
+func (tc TestCase) Tolerance() float64 {
+	if tc.CompareMode() != CompareEpsilon {
+		return 0
+	}
+	if tc.Epsilon > 0 {
+		return tc.Epsilon
+	}
+	return DefaultEpsilon
 }
 
 type Challenge struct {
@@ -20,4 +80,89 @@ type Challenge struct {
 	TimeLimit      int        `json:"timeLimit"`
 	MemoryLimit    int        `json:"memoryLimit"`
 	Description    string     `json:"description,omitempty"`
+	// ReferenceSolution is a solution that passes every test case, run by
+	// codequest verify
+	ReferenceSolution string `json:"referenceSolution,omitempty"`
+	// Variants make the challenge multi-language. Each variant is loaded as
+	// a challenge of its own, with the slug, description and test cases
+	// shared with the others and the language, function and template of the
+	// variant. The loaded challenges keep the list of variants.
+	Variants []Variant `json:"variants,omitempty"`
+	// Pack is the name of the challenge pack the challenge was loaded
+	// from, or empty for a built-in challenge
+	Pack string `json:"-"`
+}
+
+// Variant is the part of a multi-language challenge that is specific to one
+// language.
+type Variant struct {
+	Language       string   `json:"language"`
+	FunctionName   string   `json:"functionName"`
+	ParameterTypes []string `json:"parameterTypes"`
+	ReturnType     string   `json:"returnType"`
+	Template       string   `json:"template"`
+	// ConceptTags are added to the tags of the challenge
+	ConceptTags       []string `json:"conceptTags,omitempty"`
+	ReferenceSolution string   `json:"referenceSolution,omitempty"`
+}
+
+// variant returns ch in the language of its i-th variant.
+func (ch Challenge) variant(i int) Challenge {
+	v := ch.Variants[i]
+	ch.Language = v.Language
+	ch.FunctionName = v.FunctionName
+	ch.ParameterTypes = v.ParameterTypes
+	ch.ReturnType = v.ReturnType
+	ch.Template = v.Template
+	ch.ReferenceSolution = v.ReferenceSolution
+	ch.ConceptTags = append(append([]string{}, ch.ConceptTags...), v.ConceptTags...)
+	return ch
+}
+
+// expandVariants replaces every multi-language challenge with one challenge
+// per variant.
+func expandVariants(challenges []Challenge) []Challenge {
+	expanded := make([]Challenge, 0, len(challenges))
+	for _, ch := range challenges {
+		if len(ch.Variants) == 0 {
+			expanded = append(expanded, ch)
+			continue
+		}
+		for i := range ch.Variants {
+			expanded = append(expanded, ch.variant(i))
+		}
+	}
+	return expanded
+}
+
+// Languages returns the languages the challenge is available in.
+func (ch Challenge) Languages() []string {
+	if len(ch.Variants) == 0 {
+		return []string{ch.Language}
+	}
+	languages := make([]string, len(ch.Variants))
+	for i, v := range ch.Variants {
+		languages[i] = v.Language
+	}
+	return languages
+}
+
+// Label returns the slug of the challenge, followed by its language if it is
+// a variant of a multi-language challenge, such as "add-two-numbers (go)".
+func (ch Challenge) Label() string {
+	if len(ch.Variants) == 0 {
+		return ch.Slug
+	}
+	return fmt.Sprintf("%s (%s)", ch.Slug, ch.Language)
+}
+
+// SampleCases returns the test cases that aren't hidden.
+func (ch Challenge) SampleCases() []TestCase {
+	var samples []TestCase
+	for _, testCase := range ch.TestCases {
+		if !testCase.Hidden {
+			samples = append(samples, testCase)
+		}
+	}
+	return samples
 }
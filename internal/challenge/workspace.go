@@ -1,21 +1,31 @@
 package challenge
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
 )
 
-func CreateWorkspace(ch Challenge) (string, error) {
+// workspaceMetadata is the .challenge.json file CreateWorkspace writes.
+type workspaceMetadata struct {
+	Slug         string `json:"slug"`
+	Language     string `json:"language"`
+	FunctionName string `json:"functionName"`
+	SolutionFile string `json:"solutionFile"`
+}
+
+// CreateWorkspace creates a directory for ch containing the template in
+// solutionFile, a README and the metadata read by codequest test.
+func CreateWorkspace(ch Challenge, solutionFile string) (string, error) {
 	// Create workspace directory
-	workDir := fmt.Sprintf("challenge-%s", ch.Slug)
+	workDir := workspaceDir(ch)
 	if err := os.MkdirAll(workDir, 0755); err != nil {
 		return "", fmt.Errorf("failed to create workspace directory: %w", err)
 	}
 
 	// Create solution file
-	solutionFile := getSolutionFileName(ch)
 	solutionPath := filepath.Join(workDir, solutionFile)
 	if err := os.WriteFile(solutionPath, []byte(ch.Template), 0644); err != nil {
 		return "", fmt.Errorf("failed to create solution file: %w", err)
@@ -30,32 +40,49 @@ func CreateWorkspace(ch Challenge) (string, error) {
 
 	// Create challenge metadata file
 	metaPath := filepath.Join(workDir, ".challenge.json")
-	metaContent := fmt.Sprintf(`{
-  "slug": "%s",
-  "language": "%s",
-  "functionName": "%s",
-  "solutionFile": "%s"
-}`, ch.Slug, ch.Language, ch.FunctionName, solutionFile)
-	if err := os.WriteFile(metaPath, []byte(metaContent), 0644); err != nil {
+	metaContent, err := json.MarshalIndent(workspaceMetadata{
+		Slug:         ch.Slug,
+		Language:     ch.Language,
+		FunctionName: ch.FunctionName,
+		SolutionFile: solutionFile,
+	}, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to encode metadata: %w", err)
+	}
+	if err := os.WriteFile(metaPath, metaContent, 0644); err != nil {
 		return "", fmt.Errorf("failed to create metadata file: %w", err)
 	}
 
+	// Create the file for the user's own test cases, keeping any that were
+	// written before the challenge was fetched again
+	localPath := filepath.Join(workDir, LocalTestsFile)
+	if _, err := os.Stat(localPath); os.IsNotExist(err) {
+		if err := os.WriteFile(localPath, []byte(localTestsTemplate), 0644); err != nil {
+			return "", fmt.Errorf("failed to create local test cases file: %w", err)
+		}
+	}
+
 	return workDir, nil
 }
 
-func getSolutionFileName(ch Challenge) string {
-	switch ch.Language {
-	case "typescript":
-		return "solution.ts"
-	case "javascript":
-		return "solution.js"
-	case "go":
-		return "solution.go"
-	case "python":
-		return "solution.py"
-	default:
-		return "solution.txt"
+// workspaceDir returns the directory CreateWorkspace creates for ch, such as
+// challenge-two-sum. Pack challenges are nested in a directory for their
+// pack, such as pack-team/challenge-two-sum, so that they can't collide with
+// a built-in challenge or one from another pack.
+func workspaceDir(ch Challenge) string {
+	slug := ch.Slug
+	if ch.Pack != "" {
+		slug = strings.TrimPrefix(slug, ch.Pack+packSeparator)
 	}
+	workDir := fmt.Sprintf("challenge-%s", slug)
+	// Each variant of a multi-language challenge gets its own directory
+	if len(ch.Variants) > 0 {
+		workDir += "-" + ch.Language
+	}
+	if ch.Pack != "" {
+		workDir = filepath.Join("pack-"+ch.Pack, workDir)
+	}
+	return workDir
 }
 
 func generateReadme(ch Challenge) string {
@@ -76,11 +103,28 @@ func generateReadme(ch Challenge) string {
 	builder.WriteString(fmt.Sprintf("- **Return Type:** `%s`\n\n", ch.ReturnType))
 
 	builder.WriteString("## Test Cases\n\n")
-	for i, testCase := range ch.TestCases {
+	samples := ch.SampleCases()
+	for i, testCase := range samples {
 		builder.WriteString(fmt.Sprintf("**Test %d:** %s\n", i+1, testCase.Description))
 		builder.WriteString(fmt.Sprintf("- Input: `%v`\n", testCase.Input))
 		builder.WriteString(fmt.Sprintf("- Expected: `%v`\n\n", testCase.Expected))
 	}
+	if hidden := len(ch.TestCases) - len(samples); hidden == 1 {
+		builder.WriteString("Your solution is also graded against 1 hidden test case, run by `codequest submit` and `codequest test --all`.\n\n")
+	} else if hidden > 1 {
+		builder.WriteString(fmt.Sprintf("Your solution is also graded against %d hidden test cases, run by `codequest submit` and `codequest test --all`.\n\n", hidden))
+	}
+
+	builder.WriteString("## Your Own Test Cases\n\n")
+	builder.WriteString(fmt.Sprintf("Add test cases to `%s` to try inputs of your own. They use the same format as the challenge's test cases and run with them, labeled as local; `codequest test --only-local` runs only them.\n\n", LocalTestsFile))
+	if len(samples) > 0 {
+		example, _ := json.MarshalIndent(localTestCases{TestCases: []TestCase{{
+			Input:       samples[0].Input,
+			Expected:    samples[0].Expected,
+			Description: "my own test case",
+		}}}, "", "  ")
+		builder.WriteString(fmt.Sprintf("```json\n%s\n```\n\n", example))
+	}
 
 	builder.WriteString("## Commands\n\n")
 	builder.WriteString("```bash\n")
@@ -1,6 +1,7 @@
 package challenge
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -149,6 +150,90 @@ func TestCreateWorkspacePHP(t *testing.T) {
 	}
 }
 
+func TestGenerateReadmeHidesHiddenCases(t *testing.T) {
+	ch := Challenge{
+		Title:        "Double",
+		FunctionName: "double",
+		TestCases: []TestCase{
+			{Input: []interface{}{5}, Expected: 10, Description: "should double the input"},
+			{Input: []interface{}{123}, Expected: 246, Description: "secret case", Hidden: true},
+		},
+	}
+
+	readme := generateReadme(ch)
+	if !contains(readme, "should double the input") {
+		t.Error("README does not contain the sample test case")
+	}
+	if contains(readme, "secret case") || contains(readme, "246") {
+		t.Error("README gives away a hidden test case")
+	}
+	if !contains(readme, "1 hidden test case,") {
+		t.Error("README does not mention the hidden test cases")
+	}
+}
+
+func TestCreateWorkspaceLocalTests(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	ch := Challenge{
+		Slug:           "double",
+		Language:       "python",
+		FunctionName:   "double",
+		ParameterTypes: []string{"int"},
+		Template:       "def double(n):\n    pass\n",
+		TestCases:      []TestCase{{Input: []interface{}{5}, Expected: 10, Description: "should double the input"}},
+	}
+	workDir, err := CreateWorkspace(ch, "solution.py")
+	if err != nil {
+		t.Fatalf("CreateWorkspace() failed: %v", err)
+	}
+	localPath := filepath.Join(workDir, LocalTestsFile)
+	if testCases, err := LoadLocalTestCases(localPath, ch); err != nil || len(testCases) != 0 {
+		t.Errorf("Expected an empty %s, got %v, %v", LocalTestsFile, testCases, err)
+	}
+	readme, err := os.ReadFile(filepath.Join(workDir, "README.md"))
+	if err != nil || !contains(string(readme), LocalTestsFile) || !contains(string(readme), `"description": "my own test case"`) {
+		t.Errorf("README does not explain the local test cases:\n%s", readme)
+	}
+
+	// Fetching the challenge again keeps the user's own test cases
+	own := `{"testCases": [{"input": [0], "expected": 0}]}`
+	if err := os.WriteFile(localPath, []byte(own), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := CreateWorkspace(ch, "solution.py"); err != nil {
+		t.Fatalf("CreateWorkspace() failed: %v", err)
+	}
+	if data, _ := os.ReadFile(localPath); string(data) != own {
+		t.Errorf("Expected the local test cases to be kept, got %s", data)
+	}
+}
+
+func TestWorkspaceDir(t *testing.T) {
+	tests := []struct {
+		ch   Challenge
+		want string
+	}{
+		{Challenge{Slug: "two-sum"}, "challenge-two-sum"},
+		{Challenge{Slug: "team-two-sum"}, "challenge-team-two-sum"},
+		{Challenge{Slug: "team/two-sum", Pack: "team"}, filepath.Join("pack-team", "challenge-two-sum")},
+		{Challenge{Slug: "add", Language: "go", Variants: []Variant{{Language: "go"}}}, "challenge-add-go"},
+		{Challenge{Slug: "team/add", Pack: "team", Language: "go", Variants: []Variant{{Language: "go"}}}, filepath.Join("pack-team", "challenge-add-go")},
+	}
+	for _, tt := range tests {
+		if got := workspaceDir(tt.ch); got != tt.want {
+			t.Errorf("workspaceDir(%q) = %q, want %q", tt.ch.Slug, got, tt.want)
+		}
+	}
+}
+
 // Helper function to check if a string contains a substring
 func contains(s, substr string) bool {
 	return len(s) >= len(substr) && (s == substr || len(substr) == 0 ||
@@ -167,6 +252,34 @@ func findSubstring(s, substr string) bool {
 }
 
 // Helper function to create workspace in a specific directory (for testing)
+func TestCreateWorkspaceMetadata(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	ch := Challenge{Slug: "quote", Language: "go", FunctionName: `say"hi\`, Template: "package main\n"}
+	workDir, err := CreateWorkspace(ch, "solution.go")
+	if err != nil {
+		t.Fatalf("CreateWorkspace() failed: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(workDir, ".challenge.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var metadata workspaceMetadata
+	if err := json.Unmarshal(data, &metadata); err != nil {
+		t.Fatalf("Invalid .challenge.json: %v\n%s", err, data)
+	}
+	if metadata.FunctionName != ch.FunctionName || metadata.SolutionFile != "solution.go" {
+		t.Errorf("Unexpected metadata: %+v", metadata)
+	}
+}
+
 func createWorkspaceInDir(workspaceDir string, ch Challenge) error {
 	// Create workspace directory
 	if err := os.MkdirAll(workspaceDir, 0755); err != nil {
@@ -0,0 +1,150 @@
+// Package config reads the optional codequest configuration file, which
+// declares extra languages run through external commands, challenge packs
+// and where submissions are sent.
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// FileName is the configuration file looked up in the home directory.
+const FileName = ".codequest.json"
+
+type Config struct {
+	Executors []Executor `json:"executors"`
+	Submit    Submit     `json:"submit"`
+	// Packs are challenge pack directories or files loaded alongside the
+	// built-in challenges
+	Packs []string `json:"packs,omitempty"`
+}
+
+// Submit configures where codequest submit posts submissions.
+type Submit struct {
+	// Endpoint is the URL submissions are posted to. Without one,
+	// submissions are only recorded locally.
+	Endpoint string `json:"endpoint,omitempty"`
+	// Token is sent as a bearer token, if set
+	Token string `json:"token,omitempty"`
+}
+
+// Executor declares a language that codequest runs with external commands.
+// Compile and Run are argument lists in which {file} is replaced with the
+// program file name and {dir} with the directory it is written to.
+type Executor struct {
+	// Language is the id matched against Challenge.Language
+	Language string `json:"language"`
+	Name     string `json:"name"`
+	// File is the solution file name in a workspace, which is also the name
+	// the generated program is written to
+	File string `json:"file"`
+	// Harness is the path of a text/template file that produces the test
+	// program, relative to the configuration file
+	Harness string   `json:"harness"`
+	Compile []string `json:"compile,omitempty"`
+	Run     []string `json:"run"`
+	// BuildTimeLimit is the time in milliseconds allowed for Compile,
+	// separate from the challenge's time limit
+	BuildTimeLimit int `json:"buildTimeLimit,omitempty"`
+}
+
+// DefaultPath returns the configuration file in the user's home directory.
+func DefaultPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find home directory: %w", err)
+	}
+	return filepath.Join(home, FileName), nil
+}
+
+// Dir returns the directory where codequest keeps its own data, such as
+// submissions and the key that signs them.
+func Dir() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find config directory: %w", err)
+	}
+	return filepath.Join(dir, "codequest"), nil
+}
+
+// Load reads the configuration at path. Relative harness and pack paths are
+// resolved against the directory containing the file.
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse config %s: %w", path, err)
+	}
+
+	for i := range cfg.Executors {
+		executor := &cfg.Executors[i]
+		if err := executor.Validate(); err != nil {
+			return nil, fmt.Errorf("config %s: executor %d: %w", path, i+1, err)
+		}
+		if !filepath.IsAbs(executor.Harness) {
+			executor.Harness = filepath.Join(filepath.Dir(path), executor.Harness)
+		}
+	}
+	for i, pack := range cfg.Packs {
+		if !filepath.IsAbs(pack) {
+			cfg.Packs[i] = filepath.Join(filepath.Dir(path), pack)
+		}
+	}
+
+	return &cfg, nil
+}
+
+// SetPacks replaces the packs in the configuration at path, creating the
+// file if it doesn't exist. Other settings are kept as written.
+func SetPacks(path string, packs []string) error {
+	settings := map[string]json.RawMessage{}
+	data, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		if err := json.Unmarshal(data, &settings); err != nil {
+			return fmt.Errorf("failed to parse config %s: %w", path, err)
+		}
+	case !os.IsNotExist(err):
+		return err
+	}
+
+	if len(packs) == 0 {
+		delete(settings, "packs")
+	} else {
+		encoded, err := json.Marshal(packs)
+		if err != nil {
+			return err
+		}
+		settings["packs"] = encoded
+	}
+
+	data, err = json.MarshalIndent(settings, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write config %s: %w", path, err)
+	}
+	return nil
+}
+
+// Validate reports the first missing required field.
+func (e Executor) Validate() error {
+	switch {
+	case e.Language == "":
+		return fmt.Errorf("language is required")
+	case e.File == "":
+		return fmt.Errorf("file is required for %s", e.Language)
+	case e.Harness == "":
+		return fmt.Errorf("harness is required for %s", e.Language)
+	case len(e.Run) == 0:
+		return fmt.Errorf("run is required for %s", e.Language)
+	}
+	return nil
+}
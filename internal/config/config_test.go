@@ -0,0 +1,115 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, FileName)
+	content := `{
+  "executors": [
+    {
+      "language": "ruby",
+      "name": "Ruby",
+      "file": "solution.rb",
+      "harness": "harness/ruby.tmpl",
+      "run": ["ruby", "{file}"]
+    }
+  ]
+}`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+
+	if len(cfg.Executors) != 1 {
+		t.Fatalf("Expected 1 executor, got %d", len(cfg.Executors))
+	}
+
+	executor := cfg.Executors[0]
+	if executor.Language != "ruby" || executor.File != "solution.rb" {
+		t.Errorf("Unexpected executor: %+v", executor)
+	}
+
+	// Relative harness paths are resolved against the config file
+	if want := filepath.Join(dir, "harness", "ruby.tmpl"); executor.Harness != want {
+		t.Errorf("Harness = %q, want %q", executor.Harness, want)
+	}
+}
+
+func TestLoadMissingFile(t *testing.T) {
+	_, err := Load(filepath.Join(t.TempDir(), FileName))
+	if !os.IsNotExist(err) {
+		t.Errorf("Expected a not-exist error, got %v", err)
+	}
+}
+
+func TestLoadInvalidExecutor(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	if err := os.WriteFile(path, []byte(`{"executors": [{"language": "ruby", "file": "solution.rb", "harness": "ruby.tmpl"}]}`), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	if _, err := Load(path); err == nil {
+		t.Error("Expected an error for an executor without a run command")
+	}
+}
+
+func TestExecutorValidate(t *testing.T) {
+	valid := Executor{Language: "ruby", File: "solution.rb", Harness: "ruby.tmpl", Run: []string{"ruby", "{file}"}}
+	if err := valid.Validate(); err != nil {
+		t.Errorf("Validate() failed: %v", err)
+	}
+
+	invalid := []Executor{
+		{File: "solution.rb", Harness: "ruby.tmpl", Run: []string{"ruby"}},
+		{Language: "ruby", Harness: "ruby.tmpl", Run: []string{"ruby"}},
+		{Language: "ruby", File: "solution.rb", Run: []string{"ruby"}},
+		{Language: "ruby", File: "solution.rb", Harness: "ruby.tmpl"},
+	}
+	for _, executor := range invalid {
+		if err := executor.Validate(); err == nil {
+			t.Errorf("Validate(%+v) should have failed", executor)
+		}
+	}
+}
+
+func TestSetPacks(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, FileName)
+
+	// The file is created when missing
+	if err := SetPacks(path, []string{"team"}); err != nil {
+		t.Fatalf("SetPacks() failed: %v", err)
+	}
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	// Relative pack paths are resolved against the config file
+	if len(cfg.Packs) != 1 || cfg.Packs[0] != filepath.Join(dir, "team") {
+		t.Errorf("Packs = %v", cfg.Packs)
+	}
+
+	content := `{"submit": {"endpoint": "https://example.com"}, "packs": ["/packs/a", "/packs/b"]}`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	if err := SetPacks(path, []string{"/packs/b"}); err != nil {
+		t.Fatalf("SetPacks() failed: %v", err)
+	}
+	cfg, err = Load(path)
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if len(cfg.Packs) != 1 || cfg.Packs[0] != "/packs/b" || cfg.Submit.Endpoint != "https://example.com" {
+		t.Errorf("Unexpected config after SetPacks(): %+v", cfg)
+	}
+}
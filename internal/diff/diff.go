@@ -0,0 +1,374 @@
+// Package diff describes how the actual result of a test case differs from
+// the expected one: element by element for arrays, key by key for objects
+// and character by character for strings.
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/crisecheverria/codequest/internal/challenge"
+)
+
+// MaxLines is how many differences are listed before the rest are counted.
+const MaxLines = 20
+
+// maxEdits bounds the size of the table used to diff two strings; longer
+// strings are shown as one changed run between their common prefix and
+// suffix.
+const maxEdits = 1 << 16
+
+const (
+	red       = "\033[31m"
+	green     = "\033[32m"
+	deleted   = "\033[9;31m"
+	inserted  = "\033[4;32m"
+	resetCode = "\033[0m"
+)
+
+var identifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Options control how two values are compared and shown.
+type Options struct {
+	// Mode is the compare mode of the test case, see challenge.CompareMode.
+	Mode string
+	// Epsilon is how far apart two numbers may be and still be equal.
+	Epsilon float64
+	// Color marks removed and added values with ANSI colors rather than
+	// brackets.
+	Color bool
+}
+
+// Lines returns one line per difference between expected and actual. A
+// line starting with "-" shows an expected value that is missing or
+// different, "+" the value found in its place and "~" a string with the
+// removed characters marked [-like this-] and the added ones {+like this+}.
+// Both values are compared as their JSON encodings.
+func Lines(expected, actual interface{}, opts Options) []string {
+	d := &differ{opts: opts}
+	expected, actual = normalize(expected), normalize(actual)
+
+	switch opts.Mode {
+	case challenge.CompareUnordered:
+		d.unordered(expected, actual)
+	case challenge.CompareRegex, challenge.CompareExact:
+		// A pattern isn't comparable with the string it should match, and an
+		// exact result can differ only in a type JSON doesn't keep
+		d.changed("", expected, actual)
+	default:
+		d.value("", expected, actual)
+	}
+	if len(d.lines) == 0 {
+		d.changed("", expected, actual)
+	}
+
+	if len(d.lines) > MaxLines {
+		more := len(d.lines) - MaxLines
+		d.lines = append(d.lines[:MaxLines], fmt.Sprintf("… and %d more", more))
+	}
+	return d.lines
+}
+
+type differ struct {
+	opts  Options
+	lines []string
+}
+
+// normalize turns value into the types json.Unmarshal decodes into, so
+// values from test cases and from the harness compare alike.
+func normalize(value interface{}) interface{} {
+	if raw, ok := value.(json.RawMessage); ok {
+		if len(raw) == 0 {
+			return nil
+		}
+		var decoded interface{}
+		if json.Unmarshal(raw, &decoded) == nil {
+			return decoded
+		}
+		return string(raw)
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return value
+	}
+	var decoded interface{}
+	if json.Unmarshal(encoded, &decoded) != nil {
+		return value
+	}
+	return decoded
+}
+
+func (d *differ) value(path string, expected, actual interface{}) {
+	switch e := expected.(type) {
+	case []interface{}:
+		if a, ok := actual.([]interface{}); ok {
+			d.array(path, e, a)
+			return
+		}
+	case map[string]interface{}:
+		if a, ok := actual.(map[string]interface{}); ok {
+			d.object(path, e, a)
+			return
+		}
+	case string:
+		if a, ok := actual.(string); ok {
+			if e != a {
+				d.str(path, e, a)
+			}
+			return
+		}
+	}
+	if !d.equal(expected, actual) {
+		d.changed(path, expected, actual)
+	}
+}
+
+func (d *differ) array(path string, expected, actual []interface{}) {
+	for i := 0; i < len(expected) || i < len(actual); i++ {
+		elementPath := fmt.Sprintf("%s[%d]", path, i)
+		switch {
+		case i >= len(actual):
+			d.removed(elementPath, expected[i])
+		case i >= len(expected):
+			d.added(elementPath, actual[i])
+		default:
+			d.value(elementPath, expected[i], actual[i])
+		}
+	}
+}
+
+func (d *differ) object(path string, expected, actual map[string]interface{}) {
+	keys := make([]string, 0, len(expected)+len(actual))
+	for key := range expected {
+		keys = append(keys, key)
+	}
+	for key := range actual {
+		if _, ok := expected[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		keyPath := path + "." + key
+		if !identifier.MatchString(key) {
+			keyPath = path + "[" + strconv.Quote(key) + "]"
+		}
+		e, inExpected := expected[key]
+		a, inActual := actual[key]
+		switch {
+		case !inActual:
+			d.removed(keyPath, e)
+		case !inExpected:
+			d.added(keyPath, a)
+		default:
+			d.value(keyPath, e, a)
+		}
+	}
+}
+
+// unordered lists the elements expected but not found, and those found but
+// not expected, counting repeated elements.
+func (d *differ) unordered(expected, actual interface{}) {
+	e, eok := expected.([]interface{})
+	a, aok := actual.([]interface{})
+	if !eok || !aok {
+		d.value("", expected, actual)
+		return
+	}
+
+	remaining := append([]interface{}(nil), e...)
+	var extra []interface{}
+	for _, item := range a {
+		found := false
+		for i, candidate := range remaining {
+			if d.equal(candidate, item) {
+				remaining = append(remaining[:i], remaining[i+1:]...)
+				found = true
+				break
+			}
+		}
+		if !found {
+			extra = append(extra, item)
+		}
+	}
+	for _, item := range remaining {
+		d.removed("", item)
+	}
+	for _, item := range extra {
+		d.added("", item)
+	}
+}
+
+func (d *differ) equal(expected, actual interface{}) bool {
+	if e, ok := expected.(float64); ok {
+		a, ok := actual.(float64)
+		return ok && (e == a || math.Abs(e-a) <= d.opts.Epsilon)
+	}
+	switch expected.(type) {
+	case []interface{}, map[string]interface{}:
+		if reflect.TypeOf(expected) != reflect.TypeOf(actual) {
+			return false
+		}
+		probe := &differ{opts: d.opts}
+		probe.value("", expected, actual)
+		return len(probe.lines) == 0
+	}
+	return expected == actual
+}
+
+func (d *differ) changed(path string, expected, actual interface{}) {
+	d.removed(path, expected)
+	d.added(path, actual)
+}
+
+func (d *differ) removed(path string, value interface{}) {
+	d.lines = append(d.lines, d.paint(red, "- "+label(path)+format(value)))
+}
+
+func (d *differ) added(path string, value interface{}) {
+	d.lines = append(d.lines, d.paint(green, "+ "+label(path)+format(value)))
+}
+
+// str shows both strings as one, with the characters to remove from
+// expected and add to it to get actual marked.
+func (d *differ) str(path, expected, actual string) {
+	var b strings.Builder
+	b.WriteString(`~ ` + label(path) + `"`)
+	for _, edit := range edits([]rune(expected), []rune(actual)) {
+		text := quoted(string(edit.text))
+		switch edit.kind {
+		case '-':
+			if d.opts.Color {
+				b.WriteString(deleted + text + resetCode)
+			} else {
+				b.WriteString("[-" + text + "-]")
+			}
+		case '+':
+			if d.opts.Color {
+				b.WriteString(inserted + text + resetCode)
+			} else {
+				b.WriteString("{+" + text + "+}")
+			}
+		default:
+			b.WriteString(text)
+		}
+	}
+	b.WriteString(`"`)
+	d.lines = append(d.lines, b.String())
+}
+
+func (d *differ) paint(color, line string) string {
+	if !d.opts.Color {
+		return line
+	}
+	return color + line + resetCode
+}
+
+func label(path string) string {
+	if path == "" {
+		return ""
+	}
+	return path + ": "
+}
+
+func format(value interface{}) string {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(encoded)
+}
+
+// quoted escapes s as it would appear between the quotes of a JSON-like
+// string literal.
+func quoted(s string) string {
+	q := strconv.Quote(s)
+	return q[1 : len(q)-1]
+}
+
+// edit is a run of characters that both strings share (kind '='), or that
+// is removed from the first ('-') or added to it ('+').
+type edit struct {
+	kind byte
+	text []rune
+}
+
+// edits returns the shortest runs of removed and added characters that
+// turn expected into actual, from their longest common subsequence.
+func edits(expected, actual []rune) []edit {
+	prefix := 0
+	for prefix < len(expected) && prefix < len(actual) && expected[prefix] == actual[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(expected)-prefix && suffix < len(actual)-prefix &&
+		expected[len(expected)-1-suffix] == actual[len(actual)-1-suffix] {
+		suffix++
+	}
+
+	var result []edit
+	push := func(kind byte, r rune) {
+		if n := len(result); n > 0 && result[n-1].kind == kind {
+			result[n-1].text = append(result[n-1].text, r)
+			return
+		}
+		result = append(result, edit{kind: kind, text: []rune{r}})
+	}
+	for _, r := range expected[:prefix] {
+		push('=', r)
+	}
+
+	e, a := expected[prefix:len(expected)-suffix], actual[prefix:len(actual)-suffix]
+	if len(e)*len(a) > maxEdits {
+		for _, r := range e {
+			push('-', r)
+		}
+		for _, r := range a {
+			push('+', r)
+		}
+	} else {
+		// common[i][j] is the length of the longest common subsequence of
+		// e[i:] and a[j:]
+		common := make([][]int, len(e)+1)
+		for i := range common {
+			common[i] = make([]int, len(a)+1)
+		}
+		for i := len(e) - 1; i >= 0; i-- {
+			for j := len(a) - 1; j >= 0; j-- {
+				if e[i] == a[j] {
+					common[i][j] = common[i+1][j+1] + 1
+				} else {
+					common[i][j] = max(common[i+1][j], common[i][j+1])
+				}
+			}
+		}
+
+		i, j := 0, 0
+		for i < len(e) || j < len(a) {
+			switch {
+			case i < len(e) && j < len(a) && e[i] == a[j]:
+				push('=', e[i])
+				i++
+				j++
+			case j >= len(a) || (i < len(e) && common[i+1][j] >= common[i][j+1]):
+				push('-', e[i])
+				i++
+			default:
+				push('+', a[j])
+				j++
+			}
+		}
+	}
+
+	for _, r := range expected[len(expected)-suffix:] {
+		push('=', r)
+	}
+	return result
+}
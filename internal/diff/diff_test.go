@@ -0,0 +1,137 @@
+package diff
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/crisecheverria/codequest/internal/challenge"
+)
+
+func TestLines(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected interface{}
+		actual   string
+		opts     Options
+		want     []string
+	}{
+		{
+			name:     "numbers",
+			expected: float64(4),
+			actual:   `5`,
+			want:     []string{"- 4", "+ 5"},
+		},
+		{
+			name:     "array elements",
+			expected: []interface{}{float64(1), float64(2), float64(3)},
+			actual:   `[1,5,3,4]`,
+			want:     []string{"- [1]: 2", "+ [1]: 5", "+ [3]: 4"},
+		},
+		{
+			name:     "missing array element",
+			expected: []interface{}{"a", "b"},
+			actual:   `["a"]`,
+			want:     []string{`- [1]: "b"`},
+		},
+		{
+			name: "object keys",
+			expected: map[string]interface{}{
+				"name":  "Ada",
+				"age":   float64(36),
+				"tags":  []interface{}{"x"},
+				"a key": true,
+			},
+			actual: `{"name":"Ada","age":37,"tags":["y"],"extra":null}`,
+			want: []string{
+				`- ["a key"]: true`,
+				"- .age: 36",
+				"+ .age: 37",
+				"+ .extra: null",
+				`~ .tags[0]: "[-x-]{+y+}"`,
+			},
+		},
+		{
+			name:     "characters",
+			expected: "hello world",
+			actual:   `"help wrld"`,
+			want:     []string{`~ "hel[-lo-]{+p+} w[-o-]rld"`},
+		},
+		{
+			name:     "escaped characters",
+			expected: "a\nb",
+			actual:   `"a\tb"`,
+			want:     []string{`~ "a[-\n-]{+\t+}b"`},
+		},
+		{
+			name:     "type mismatch",
+			expected: []interface{}{float64(1)},
+			actual:   `"1"`,
+			want:     []string{"- [1]", `+ "1"`},
+		},
+		{
+			name:     "unordered",
+			expected: []interface{}{float64(1), float64(2), float64(2)},
+			actual:   `[2,3,1]`,
+			opts:     Options{Mode: challenge.CompareUnordered},
+			want:     []string{"- 2", "+ 3"},
+		},
+		{
+			name:     "epsilon",
+			expected: []interface{}{0.1, 0.2},
+			actual:   `[0.1001,0.5]`,
+			opts:     Options{Mode: challenge.CompareEpsilon, Epsilon: 0.001},
+			want:     []string{"- [1]: 0.2", "+ [1]: 0.5"},
+		},
+		{
+			name:     "regex",
+			expected: "^a+$",
+			actual:   `"ab"`,
+			opts:     Options{Mode: challenge.CompareRegex},
+			want:     []string{`- "^a+$"`, `+ "ab"`},
+		},
+		{
+			name:     "equal under deep",
+			expected: float64(1),
+			actual:   `1`,
+			opts:     Options{Mode: challenge.CompareExact},
+			want:     []string{"- 1", "+ 1"},
+		},
+		{
+			name:     "color",
+			expected: []interface{}{"ab", float64(1)},
+			actual:   `["ac"]`,
+			opts:     Options{Color: true},
+			want:     []string{"~ [0]: \"a\033[9;31mb\033[0m\033[4;32mc\033[0m\"", "\033[31m- [1]: 1\033[0m"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Lines(tt.expected, json.RawMessage(tt.actual), tt.opts)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Lines() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
+
+func TestLinesLimit(t *testing.T) {
+	expected := make([]interface{}, MaxLines+5)
+	for i := range expected {
+		expected[i] = float64(i)
+	}
+	got := Lines(expected, json.RawMessage(`[]`), Options{})
+	if len(got) != MaxLines+1 || got[MaxLines] != "… and 5 more" {
+		t.Errorf("Expected %d lines and a count of the rest, got %q", MaxLines+1, got)
+	}
+}
+
+func TestEditsLongStrings(t *testing.T) {
+	expected := []rune("<" + strings.Repeat("a", 300) + ">")
+	actual := []rune("<" + strings.Repeat("b", 300) + ">")
+	got := edits(expected, actual)
+	if len(got) != 4 || got[1].kind != '-' || got[2].kind != '+' || string(got[3].text) != ">" {
+		t.Errorf("Expected one changed run between the common prefix and suffix, got %d edits", len(got))
+	}
+}
@@ -0,0 +1,544 @@
+package harness
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/crisecheverria/codequest/internal/challenge"
+	"github.com/crisecheverria/codequest/internal/literal"
+)
+
+// GenerateGo builds the harness for Go solutions as a main package.
+func GenerateGo(ch challenge.Challenge, solutionCode string, testCases []challenge.TestCase, failFast bool) (string, error) {
+	// Clean user code by removing package declaration, imports, and main function
+	cleanedCode := cleanGoUserCode(solutionCode)
+	imports := usedGoImports(goImports(solutionCode), cleanedCode)
+
+	var cases, testFunctions strings.Builder
+	for i, testCase := range testCases {
+		caseCode, testFunction, err := goCase(ch, i, testCase)
+		if err != nil {
+			return "", fmt.Errorf("test case %d: %w", i+1, err)
+		}
+		cases.WriteString(caseCode)
+		if testFunction != "" {
+			testFunctions.WriteString("\n" + testFunction + "\n")
+		}
+	}
+
+	var importBlock strings.Builder
+	for _, spec := range imports {
+		fmt.Fprintf(&importBlock, "\t%s\n", spec)
+	}
+
+	return fmt.Sprintf(goHarnessTemplate, importBlock.String(), cleanedCode, testFunctions.String(), ResultPrefix, cases.String(), failFast), nil
+}
+
+// goCase returns the closure that runs one test case, plus the renamed
+// test function it calls when the case provides one.
+func goCase(ch challenge.Challenge, index int, testCase challenge.TestCase) (string, string, error) {
+	mode := testCase.CompareMode()
+	if err := checkCompareMode(testCase); err != nil {
+		return "", "", err
+	}
+
+	argsStr, err := formatArgs(ch, testCase.Input, literal.Go)
+	if err != nil {
+		return "", "", err
+	}
+
+	callee, returnType, testFunction := ch.FunctionName, ch.ReturnType, ""
+	if testCase.TestFunction != "" {
+		callee = fmt.Sprintf("cqTest%d", index)
+		testFunction, returnType, err = renameGoTestFunction(testCase.TestFunction, callee)
+		if err != nil {
+			return "", "", err
+		}
+	}
+	call := fmt.Sprintf("%s(%s)", callee, argsStr)
+
+	var body strings.Builder
+	body.WriteString("\t\tfunc() (interface{}, bool) {\n")
+
+	returnTypes, isTuple := goTupleTypes(returnType)
+	if !isTuple {
+		returnTypes = []string{returnType}
+	}
+
+	switch {
+	case mode == challenge.CompareError:
+		// Only the trailing error is checked; other return values are ignored
+		if returnTypes[len(returnTypes)-1] != "error" {
+			return "", "", fmt.Errorf("compare mode %q requires a function returning error, got %q", mode, returnType)
+		}
+		results := make([]string, len(returnTypes))
+		for i := range results {
+			results[i] = "_"
+		}
+		results[len(results)-1] = "err"
+		fmt.Fprintf(&body, "\t\t\t%s := %s\n", strings.Join(results, ", "), call)
+
+		switch expected := testCase.Expected.(type) {
+		case nil:
+			body.WriteString("\t\t\tif err == nil {\n\t\t\t\treturn nil, true\n\t\t\t}\n")
+			body.WriteString("\t\t\treturn err.Error(), false\n")
+		case string:
+			body.WriteString("\t\t\tif err == nil {\n\t\t\t\treturn nil, false\n\t\t\t}\n")
+			fmt.Fprintf(&body, "\t\t\treturn err.Error(), err.Error() == %s\n", strconv.Quote(expected))
+		default:
+			return "", "", fmt.Errorf("compare mode %q expects an error message or null, got %T", mode, testCase.Expected)
+		}
+
+	case mode == challenge.CompareRegex:
+		pattern, _ := testCase.Expected.(string)
+		if _, err := regexp.Compile(pattern); err != nil {
+			return "", "", fmt.Errorf("invalid pattern: %w", err)
+		}
+		fmt.Fprintf(&body, "\t\t\tresult := %s\n", call)
+		fmt.Fprintf(&body, "\t\t\treturn result, cqMatch(%s, result)\n", strconv.Quote(pattern))
+
+	case isTuple:
+		// Multiple return values - capture each one and compare them in order
+		expectedSlice, ok := testCase.Expected.([]interface{})
+		if !ok || len(expectedSlice) != len(returnTypes) {
+			return "", "", fmt.Errorf("expected %d return values for %s", len(returnTypes), returnType)
+		}
+
+		results := make([]string, len(returnTypes))
+		checks := make([]string, len(returnTypes))
+		for i := range returnTypes {
+			results[i] = fmt.Sprintf("result%d", i+1)
+			checks[i] = goCheck(testCase, results[i], fmt.Sprintf("expected%d", i+1))
+		}
+
+		fmt.Fprintf(&body, "\t\t\t%s := %s\n", strings.Join(results, ", "), call)
+		for i, resultType := range returnTypes {
+			expected, err := literal.Go(resultType, expectedSlice[i])
+			if err != nil {
+				return "", "", fmt.Errorf("expected value %d: %w", i+1, err)
+			}
+			fmt.Fprintf(&body, "\t\t\tvar expected%d %s = %s\n", i+1, resultType, expected)
+		}
+		fmt.Fprintf(&body, "\t\t\treturn []interface{}{%s}, %s\n", strings.Join(results, ", "), strings.Join(checks, " && "))
+
+	default:
+		expected, err := literal.Go(returnType, testCase.Expected)
+		if err != nil {
+			return "", "", fmt.Errorf("expected value: %w", err)
+		}
+
+		fmt.Fprintf(&body, "\t\t\tresult := %s\n", call)
+		if returnType != "" {
+			fmt.Fprintf(&body, "\t\t\tvar expected %s = %s\n", returnType, expected)
+		} else {
+			fmt.Fprintf(&body, "\t\t\texpected := %s\n", expected)
+		}
+		fmt.Fprintf(&body, "\t\t\treturn result, %s\n", goCheck(testCase, "result", "expected"))
+	}
+
+	body.WriteString("\t\t},\n")
+	return body.String(), testFunction, nil
+}
+
+// goCheck returns the Go expression comparing actual with expected under
+// the test case's compare mode.
+func goCheck(testCase challenge.TestCase, actual, expected string) string {
+	switch testCase.CompareMode() {
+	case challenge.CompareExact:
+		return fmt.Sprintf("cqreflect.DeepEqual(%s, %s)", actual, expected)
+	case challenge.CompareUnordered:
+		return fmt.Sprintf("cqUnordered(%s, %s)", actual, expected)
+	default:
+		return fmt.Sprintf("cqEqual(%s, %s, %s)", actual, expected, strconv.FormatFloat(testCase.Tolerance(), 'g', -1, 64))
+	}
+}
+
+var goTestFuncPattern = regexp.MustCompile(`func\s+test\s*\(`)
+
+// renameGoTestFunction renames the test function declared in source to
+// name and returns its declared result type.
+func renameGoTestFunction(source, name string) (string, string, error) {
+	loc := goTestFuncPattern.FindStringIndex(source)
+	if loc == nil {
+		return "", "", fmt.Errorf("testFunction must declare func test(...)")
+	}
+
+	// Skip the parameter list, which may itself contain parentheses
+	depth := 1
+	i := loc[1]
+	for ; i < len(source) && depth > 0; i++ {
+		switch source[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		}
+	}
+	brace := strings.Index(source[i:], "{")
+	if depth != 0 || brace < 0 {
+		return "", "", fmt.Errorf("testFunction has an invalid func test signature")
+	}
+
+	returnType := strings.TrimSpace(source[i : i+brace])
+	renamed := source[:loc[0]] + "func " + name + "(" + source[loc[1]:]
+	return renamed, returnType, nil
+}
+
+const goHarnessTemplate = `package main
+
+import (
+%s	cqjson "encoding/json"
+	cqfmt "fmt"
+	cqmath "math"
+	cqos "os"
+	cqreflect "reflect"
+	cqregexp "regexp"
+	cqtime "time"
+)
+
+%s
+%s
+type cqResult struct {
+	Index      int               ` + "`json:\"index\"`" + `
+	Passed     bool              ` + "`json:\"passed\"`" + `
+	DurationMs float64           ` + "`json:\"durationMs\"`" + `
+	Actual     cqjson.RawMessage ` + "`json:\"actual,omitempty\"`" + `
+	Error      string            ` + "`json:\"error,omitempty\"`" + `
+}
+
+func cqIndirect(v cqreflect.Value) cqreflect.Value {
+	for v.IsValid() && v.Kind() == cqreflect.Interface && !v.IsNil() {
+		v = v.Elem()
+	}
+	return v
+}
+
+// cqNilOrEmpty treats nil and empty slices and maps alike
+func cqNilOrEmpty(v cqreflect.Value) bool {
+	if !v.IsValid() {
+		return true
+	}
+	switch v.Kind() {
+	case cqreflect.Slice, cqreflect.Map:
+		return v.IsNil() || v.Len() == 0
+	case cqreflect.Ptr, cqreflect.Interface, cqreflect.Func, cqreflect.Chan:
+		return v.IsNil()
+	}
+	return false
+}
+
+func cqNumber(v cqreflect.Value) (float64, bool) {
+	switch v.Kind() {
+	case cqreflect.Int, cqreflect.Int8, cqreflect.Int16, cqreflect.Int32, cqreflect.Int64:
+		return float64(v.Int()), true
+	case cqreflect.Uint, cqreflect.Uint8, cqreflect.Uint16, cqreflect.Uint32, cqreflect.Uint64, cqreflect.Uintptr:
+		return float64(v.Uint()), true
+	case cqreflect.Float32, cqreflect.Float64:
+		return v.Float(), true
+	}
+	return 0, false
+}
+
+func cqEqual(actual, expected interface{}, epsilon float64) bool {
+	return cqEqualValue(cqreflect.ValueOf(actual), cqreflect.ValueOf(expected), epsilon)
+}
+
+func cqEqualValue(a, b cqreflect.Value, epsilon float64) bool {
+	a, b = cqIndirect(a), cqIndirect(b)
+	if cqNilOrEmpty(a) || cqNilOrEmpty(b) {
+		return cqNilOrEmpty(a) && cqNilOrEmpty(b) && (!a.IsValid() || !b.IsValid() || a.Kind() == b.Kind())
+	}
+
+	if x, ok := cqNumber(a); ok {
+		if y, ok := cqNumber(b); ok {
+			return x == y || cqmath.Abs(x-y) <= epsilon
+		}
+	}
+	if a.Kind() != b.Kind() {
+		return false
+	}
+
+	switch a.Kind() {
+	case cqreflect.Ptr:
+		return cqEqualValue(a.Elem(), b.Elem(), epsilon)
+	case cqreflect.Slice, cqreflect.Array:
+		if a.Len() != b.Len() {
+			return false
+		}
+		for i := 0; i < a.Len(); i++ {
+			if !cqEqualValue(a.Index(i), b.Index(i), epsilon) {
+				return false
+			}
+		}
+		return true
+	case cqreflect.Map:
+		if a.Len() != b.Len() || a.Type().Key() != b.Type().Key() {
+			return false
+		}
+		for _, key := range a.MapKeys() {
+			other := b.MapIndex(key)
+			if !other.IsValid() || !cqEqualValue(a.MapIndex(key), other, epsilon) {
+				return false
+			}
+		}
+		return true
+	case cqreflect.Struct:
+		if a.Type() != b.Type() {
+			return false
+		}
+		for i := 0; i < a.NumField(); i++ {
+			if !cqEqualValue(a.Field(i), b.Field(i), epsilon) {
+				return false
+			}
+		}
+		return true
+	case cqreflect.String:
+		return a.String() == b.String()
+	case cqreflect.Bool:
+		return a.Bool() == b.Bool()
+	case cqreflect.Complex64, cqreflect.Complex128:
+		return a.Complex() == b.Complex()
+	}
+	return false
+}
+
+// cqUnordered compares two lists as multisets
+func cqUnordered(actual, expected interface{}) bool {
+	a, b := cqIndirect(cqreflect.ValueOf(actual)), cqIndirect(cqreflect.ValueOf(expected))
+	if cqNilOrEmpty(a) || cqNilOrEmpty(b) {
+		return cqNilOrEmpty(a) && cqNilOrEmpty(b)
+	}
+	isList := func(v cqreflect.Value) bool {
+		return v.Kind() == cqreflect.Slice || v.Kind() == cqreflect.Array
+	}
+	if !isList(a) || !isList(b) {
+		return cqEqualValue(a, b, 0)
+	}
+	if a.Len() != b.Len() {
+		return false
+	}
+
+	used := make([]bool, b.Len())
+	for i := 0; i < a.Len(); i++ {
+		found := false
+		for j := 0; j < b.Len(); j++ {
+			if !used[j] && cqEqualValue(a.Index(i), b.Index(j), 0) {
+				used[j] = true
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func cqMatch(pattern string, actual interface{}) bool {
+	v := cqIndirect(cqreflect.ValueOf(actual))
+	return v.IsValid() && v.Kind() == cqreflect.String && cqregexp.MustCompile(pattern).MatchString(v.String())
+}
+
+func cqRun(index int, run func() (interface{}, bool)) bool {
+	report := cqResult{Index: index}
+	start := cqtime.Now()
+	func() {
+		defer func() {
+			if r := recover(); r != nil {
+				report.Error = cqfmt.Sprintf("panic: %%v", r)
+			}
+		}()
+		actual, passed := run()
+		report.Passed = passed
+		if encoded, err := cqjson.Marshal(actual); err == nil {
+			report.Actual = encoded
+		} else {
+			report.Actual, _ = cqjson.Marshal(cqfmt.Sprintf("%%v", actual))
+		}
+	}()
+	report.DurationMs = float64(cqtime.Since(start).Nanoseconds()) / 1e6
+
+	line, _ := cqjson.Marshal(report)
+	cqfmt.Printf("%s%%s\n", line)
+	return report.Passed
+}
+
+func main() {
+	cases := []func() (interface{}, bool){
+%s	}
+	const failFast = %t
+
+	failed := false
+	for i, run := range cases {
+		if !cqRun(i, run) {
+			failed = true
+			if failFast {
+				break
+			}
+		}
+	}
+	if failed {
+		cqos.Exit(1)
+	}
+}
+`
+
+// goTupleTypes splits a multiple return type such as "(int, int)".
+func goTupleTypes(returnType string) ([]string, bool) {
+	returnType = strings.TrimSpace(returnType)
+	if !strings.HasPrefix(returnType, "(") || !strings.HasSuffix(returnType, ")") {
+		return nil, false
+	}
+
+	var types []string
+	for _, part := range strings.Split(returnType[1:len(returnType)-1], ",") {
+		fields := strings.Fields(part)
+		if len(fields) == 0 {
+			continue
+		}
+		// Named results like (q int, r int) keep only the type
+		types = append(types, fields[len(fields)-1])
+	}
+	return types, len(types) > 0
+}
+
+// goImports returns the import specs declared in the user's code, e.g.
+// `"strings"` or `str "strings"`.
+func goImports(code string) []string {
+	var specs []string
+	inImportBlock := false
+
+	for _, line := range strings.Split(code, "\n") {
+		trimmed := strings.TrimSpace(line)
+
+		if strings.HasPrefix(trimmed, "import (") {
+			inImportBlock = true
+			continue
+		}
+
+		if inImportBlock {
+			if trimmed == ")" {
+				inImportBlock = false
+				continue
+			}
+			if trimmed != "" && !strings.HasPrefix(trimmed, "//") {
+				specs = append(specs, trimmed)
+			}
+			continue
+		}
+
+		if strings.HasPrefix(trimmed, "import ") {
+			specs = append(specs, strings.TrimSpace(strings.TrimPrefix(trimmed, "import ")))
+		}
+	}
+
+	return specs
+}
+
+// usedGoImports drops imports that are no longer referenced once the user's
+// main function has been removed, since Go rejects unused imports.
+func usedGoImports(specs []string, code string) []string {
+	var used []string
+	for _, spec := range specs {
+		fields := strings.Fields(spec)
+		importPath, err := strconv.Unquote(fields[len(fields)-1])
+		if err != nil {
+			continue
+		}
+
+		name := path.Base(importPath)
+		if len(fields) > 1 {
+			name = fields[0]
+		}
+
+		if name == "_" || name == "." || strings.Contains(code, name+".") {
+			used = append(used, spec)
+		}
+	}
+	return used
+}
+
+func cleanGoUserCode(code string) string {
+	lines := strings.Split(code, "\n")
+	var cleanedLines []string
+
+	inImportBlock := false
+	inMainFunction := false
+	braceCount := 0
+
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+
+		// Skip package declarations
+		if strings.HasPrefix(trimmed, "package ") {
+			continue
+		}
+
+		// Skip imports (we provide our own imports)
+		if strings.HasPrefix(trimmed, "import ") && !strings.Contains(trimmed, "(") {
+			continue
+		}
+
+		if strings.HasPrefix(trimmed, "import (") {
+			inImportBlock = true
+			continue
+		}
+
+		if inImportBlock {
+			if trimmed == ")" {
+				inImportBlock = false
+			}
+			continue
+		}
+
+		// Skip user's main function
+		if strings.HasPrefix(trimmed, "func main(") {
+			inMainFunction = true
+			braceCount = 0
+			// Count braces on the same line, which may also close an empty main
+			for _, r := range line {
+				if r == '{' {
+					braceCount++
+				} else if r == '}' {
+					braceCount--
+				}
+			}
+			if strings.Contains(line, "{") && braceCount <= 0 {
+				inMainFunction = false
+			}
+			continue
+		}
+
+		if inMainFunction {
+			// Count braces to know when main function ends
+			for _, r := range line {
+				if r == '{' {
+					braceCount++
+				} else if r == '}' {
+					braceCount--
+				}
+			}
+
+			if braceCount <= 0 {
+				inMainFunction = false
+			}
+			continue
+		}
+
+		cleanedLines = append(cleanedLines, line)
+	}
+
+	// Remove empty lines at the beginning and end
+	for len(cleanedLines) > 0 && strings.TrimSpace(cleanedLines[0]) == "" {
+		cleanedLines = cleanedLines[1:]
+	}
+	for len(cleanedLines) > 0 && strings.TrimSpace(cleanedLines[len(cleanedLines)-1]) == "" {
+		cleanedLines = cleanedLines[:len(cleanedLines)-1]
+	}
+
+	return strings.Join(cleanedLines, "\n")
+}
@@ -0,0 +1,353 @@
+package harness
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/crisecheverria/codequest/internal/challenge"
+)
+
+func TestGenerateGo(t *testing.T) {
+	ch := challenge.Challenge{
+		Language:     "go",
+		FunctionName: "add",
+		ReturnType:   "int",
+	}
+
+	userCode := `package main
+
+import "fmt"
+
+func add(a, b int) int {
+    return a + b
+}
+
+func main() {
+    fmt.Println("Hello")
+}`
+
+	testCases := []challenge.TestCase{
+		{Input: []interface{}{float64(2), float64(3)}, Expected: float64(5), Description: "should add two numbers"},
+		{Input: []interface{}{float64(-1), float64(1)}, Expected: float64(0), Description: "should handle negatives"},
+	}
+
+	result, err := GenerateGo(ch, userCode, testCases, false)
+	if err != nil {
+		t.Fatalf("GenerateGo() failed: %v", err)
+	}
+
+	// Check that the generated code has the correct structure
+	if !strings.Contains(result, "package main") {
+		t.Error("Generated code should contain 'package main'")
+	}
+
+	if !strings.Contains(result, "func add(a, b int) int") {
+		t.Error("Generated code should contain the user's function")
+	}
+
+	if !strings.Contains(result, "result := add(2, 3)") || !strings.Contains(result, "result := add(-1, 1)") {
+		t.Error("Generated code should call the function once per test case")
+	}
+
+	if !strings.Contains(result, "var expected int = 5") || !strings.Contains(result, "return result, cqEqual(result, expected, 0)") {
+		t.Error("Generated code should set the expected value")
+	}
+
+	if strings.Count(result, "func main()") != 1 {
+		t.Error("Generated code should contain exactly one main function")
+	}
+
+	// The user's import is unused once their main is removed, so it must be dropped
+	if strings.Contains(result, "\t\"fmt\"") {
+		t.Error("Generated code should not contain unused user imports")
+	}
+
+	// Check that user's original main function is removed
+	if strings.Contains(result, "fmt.Println(\"Hello\")") {
+		t.Error("Generated code should not contain user's main function content")
+	}
+}
+
+func TestGenerateGoMultipleReturnValues(t *testing.T) {
+	ch := challenge.Challenge{
+		Language:     "go",
+		FunctionName: "divmod",
+		ReturnType:   "(int, int)",
+	}
+
+	testCases := []challenge.TestCase{
+		{Input: []interface{}{float64(10), float64(3)}, Expected: []interface{}{float64(3), float64(1)}},
+	}
+
+	result, err := GenerateGo(ch, "func divmod(a, b int) (int, int) {\n\treturn a / b, a % b\n}", testCases, false)
+	if err != nil {
+		t.Fatalf("GenerateGo() failed: %v", err)
+	}
+
+	if !strings.Contains(result, "result1, result2 := divmod(10, 3)") {
+		t.Error("Generated code should capture every return value")
+	}
+
+	if !strings.Contains(result, "var expected1 int = 3") || !strings.Contains(result, "var expected2 int = 1") {
+		t.Error("Generated code should set one typed expected value per return value")
+	}
+
+	// The number of expected values must match the declared return values
+	testCases[0].Expected = []interface{}{float64(3)}
+	if _, err := GenerateGo(ch, "", testCases, false); err == nil {
+		t.Error("Expected an error when the expected values do not match the return types")
+	}
+}
+
+func TestGenerateGoTypedValues(t *testing.T) {
+	ch := challenge.Challenge{
+		Language:       "go",
+		FunctionName:   "countWords",
+		ParameterTypes: []string{"[]string"},
+		ReturnType:     "map[string]int",
+	}
+
+	testCases := []challenge.TestCase{
+		{
+			Input:    []interface{}{[]interface{}{"say \"hi\"", "go"}},
+			Expected: map[string]interface{}{"go": float64(1), "say \"hi\"": float64(1)},
+		},
+	}
+
+	result, err := GenerateGo(ch, "func countWords(words []string) map[string]int {\n\treturn nil\n}", testCases, false)
+	if err != nil {
+		t.Fatalf("GenerateGo() failed: %v", err)
+	}
+
+	if !strings.Contains(result, `result := countWords([]string{"say \"hi\"", "go"})`) {
+		t.Error("Generated code should pass a typed, escaped slice literal")
+	}
+
+	if !strings.Contains(result, `var expected map[string]int = map[string]int{"go": 1, "say \"hi\"": 1}`) {
+		t.Error("Generated code should declare a typed map literal for the expected value")
+	}
+}
+
+func TestGoImports(t *testing.T) {
+	userCode := `package main
+
+import (
+    "fmt"
+    str "strings"
+)
+
+import "os"
+
+func shout(s string) string {
+    return str.ToUpper(s)
+}
+
+func main() {
+    fmt.Println(shout("hi"))
+    os.Exit(0)
+}`
+
+	specs := goImports(userCode)
+	if len(specs) != 3 {
+		t.Fatalf("Expected 3 import specs, got %d: %v", len(specs), specs)
+	}
+
+	used := usedGoImports(specs, cleanGoUserCode(userCode))
+	if len(used) != 1 || used[0] != `str "strings"` {
+		t.Errorf("Expected only the aliased strings import to be used, got %v", used)
+	}
+}
+
+func TestCleanGoUserCode(t *testing.T) {
+	userCode := `package main
+
+import (
+    "fmt"
+    "strconv"
+)
+
+import "os"
+
+func add(a, b int) int {
+    return a + b
+}
+
+func helper() string {
+    return "helper"
+}
+
+func main() {
+    fmt.Println("This should be removed")
+    if true {
+        fmt.Println("Nested content")
+    }
+}
+
+func anotherFunc() int {
+    return 42
+}`
+
+	cleaned := cleanGoUserCode(userCode)
+
+	// Should remove package declaration
+	if strings.Contains(cleaned, "package main") {
+		t.Error("Cleaned code should not contain package declaration")
+	}
+
+	// Should remove import statements
+	if strings.Contains(cleaned, "import") {
+		t.Error("Cleaned code should not contain import statements")
+	}
+
+	// Should remove main function
+	if strings.Contains(cleaned, "func main()") {
+		t.Error("Cleaned code should not contain main function")
+	}
+	if strings.Contains(cleaned, "This should be removed") {
+		t.Error("Cleaned code should not contain main function content")
+	}
+
+	// Should keep other functions
+	if !strings.Contains(cleaned, "func add(a, b int) int") {
+		t.Error("Cleaned code should contain the add function")
+	}
+
+	if !strings.Contains(cleaned, "func helper() string") {
+		t.Error("Cleaned code should contain the helper function")
+	}
+
+	if !strings.Contains(cleaned, "func anotherFunc() int") {
+		t.Error("Cleaned code should contain the anotherFunc function")
+	}
+}
+
+func TestCleanGoUserCodeWithMainFunctionVariations(t *testing.T) {
+	// Test main function with opening brace on same line
+	userCode1 := `package main
+
+func add(a, b int) int {
+    return a + b
+}
+
+func main() {
+    fmt.Println("Hello")
+}`
+
+	cleaned1 := cleanGoUserCode(userCode1)
+	if strings.Contains(cleaned1, "func main()") || strings.Contains(cleaned1, "Hello") {
+		t.Error("Should remove main function with brace on same line")
+	}
+
+	// Test main function with opening brace on next line
+	userCode2 := `package main
+
+func add(a, b int) int {
+    return a + b
+}
+
+func main()
+{
+    fmt.Println("Hello")
+}`
+
+	cleaned2 := cleanGoUserCode(userCode2)
+	if strings.Contains(cleaned2, "func main()") || strings.Contains(cleaned2, "Hello") {
+		t.Error("Should remove main function with brace on next line")
+	}
+
+	// Test empty main function on a single line
+	userCode3 := `package main
+
+func main() {}
+
+func add(a, b int) int {
+    return a + b
+}`
+
+	cleaned3 := cleanGoUserCode(userCode3)
+	if strings.Contains(cleaned3, "func main()") || !strings.Contains(cleaned3, "func add(a, b int) int") {
+		t.Error("Should remove a single-line main function and keep what follows")
+	}
+}
+
+func TestGenerateGoCompareModes(t *testing.T) {
+	ch := challenge.Challenge{
+		Language:       "go",
+		FunctionName:   "validateAge",
+		ParameterTypes: []string{"int"},
+		ReturnType:     "error",
+	}
+
+	testCases := []challenge.TestCase{
+		{Input: []interface{}{float64(25)}, Expected: nil, Compare: challenge.CompareError},
+		{Input: []interface{}{float64(-5)}, Expected: "Age -5: invalid age", Compare: challenge.CompareError},
+	}
+
+	result, err := GenerateGo(ch, "func validateAge(age int) error {\n\treturn nil\n}", testCases, false)
+	if err != nil {
+		t.Fatalf("GenerateGo() failed: %v", err)
+	}
+
+	if strings.Count(result, "err := validateAge(") != 2 {
+		t.Error("Generated code should capture the returned error")
+	}
+
+	if !strings.Contains(result, `return err.Error(), err.Error() == "Age -5: invalid age"`) {
+		t.Error("Generated code should compare the error message")
+	}
+
+	// Error mode needs a function that returns an error
+	ch.ReturnType = "int"
+	if _, err := GenerateGo(ch, "", testCases, false); err == nil {
+		t.Error("Expected an error when the function does not return an error")
+	}
+
+	ch = challenge.Challenge{
+		Language:       "go",
+		FunctionName:   "primes",
+		ParameterTypes: []string{"int"},
+		ReturnType:     "[]int",
+	}
+	testCases = []challenge.TestCase{
+		{Input: []interface{}{float64(5)}, Expected: []interface{}{float64(5), float64(3), float64(2)}, Compare: challenge.CompareUnordered},
+		{Input: []interface{}{float64(1)}, Expected: []interface{}{}, Compare: challenge.CompareExact},
+	}
+
+	result, err = GenerateGo(ch, "", testCases, false)
+	if err != nil {
+		t.Fatalf("GenerateGo() failed: %v", err)
+	}
+
+	if !strings.Contains(result, "return result, cqUnordered(result, expected)") {
+		t.Error("Generated code should compare unordered results as multisets")
+	}
+
+	if !strings.Contains(result, "return result, cqreflect.DeepEqual(result, expected)") {
+		t.Error("Generated code should compare exact results with reflect.DeepEqual")
+	}
+}
+
+func TestGenerateGoTestFunction(t *testing.T) {
+	ch := challenge.Challenge{
+		Language:     "go",
+		FunctionName: "newCounter",
+		ReturnType:   "*Counter",
+	}
+
+	testCases := []challenge.TestCase{
+		{Input: []interface{}{}, Expected: float64(0), TestFunction: "func test() int { c := newCounter(); return c.Value() }"},
+	}
+
+	result, err := GenerateGo(ch, "", testCases, false)
+	if err != nil {
+		t.Fatalf("GenerateGo() failed: %v", err)
+	}
+
+	if !strings.Contains(result, "func cqTest0() int { c := newCounter(); return c.Value() }") {
+		t.Error("Generated code should contain the renamed test function")
+	}
+
+	if !strings.Contains(result, "result := cqTest0()") || !strings.Contains(result, "var expected int = 0") {
+		t.Error("Generated code should call the test function and use its result type")
+	}
+}
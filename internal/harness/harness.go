@@ -0,0 +1,115 @@
+package harness
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/crisecheverria/codequest/internal/challenge"
+)
+
+// ResultPrefix marks a protocol line written by a generated harness. Each
+// line carries one JSON encoded CaseResult; anything else on stdout is the
+// solution's own output.
+const ResultPrefix = "__CODEQUEST_RESULT__ "
+
+// CaseResult is the per test case report emitted by a generated harness.
+type CaseResult struct {
+	Index      int             `json:"index"`
+	Passed     bool            `json:"passed"`
+	DurationMs float64         `json:"durationMs"`
+	Actual     json.RawMessage `json:"actual,omitempty"`
+	Error      string          `json:"error,omitempty"`
+	// Output is what the program printed between the previous result and
+	// this one, which is usually the solution's output for this case
+	Output string `json:"-"`
+}
+
+// Duration returns the time spent inside the solution for this case.
+func (r CaseResult) Duration() time.Duration {
+	return time.Duration(r.DurationMs * float64(time.Millisecond))
+}
+
+// Generator builds a single program that runs every test case against the
+// solution and reports one CaseResult line per case. With failFast the
+// program exits after reporting the first case that didn't pass.
+type Generator func(ch challenge.Challenge, solutionCode string, testCases []challenge.TestCase, failFast bool) (string, error)
+
+// ParseResults splits harness output into the reported case results and the
+// remaining output produced by the solution itself. That output is also
+// attributed to the case whose result follows it.
+func ParseResults(output string) ([]CaseResult, string) {
+	var results []CaseResult
+	var rest []string
+	pending := 0
+
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, ResultPrefix) {
+			rest = append(rest, line)
+			continue
+		}
+
+		var result CaseResult
+		if err := json.Unmarshal([]byte(strings.TrimPrefix(line, ResultPrefix)), &result); err != nil {
+			rest = append(rest, line)
+			continue
+		}
+		result.Output = strings.TrimSpace(strings.Join(rest[pending:], "\n"))
+		pending = len(rest)
+		results = append(results, result)
+	}
+
+	return results, strings.TrimSpace(strings.Join(rest, "\n"))
+}
+
+// checkCompareMode rejects unknown compare modes and expected values that
+// cannot work with the chosen mode.
+func checkCompareMode(testCase challenge.TestCase) error {
+	switch mode := testCase.CompareMode(); mode {
+	case challenge.CompareDeep, challenge.CompareExact, challenge.CompareUnordered, challenge.CompareEpsilon:
+		return nil
+	case challenge.CompareRegex:
+		if _, ok := testCase.Expected.(string); !ok {
+			return fmt.Errorf("compare mode %q expects a pattern string, got %T", mode, testCase.Expected)
+		}
+		return nil
+	case challenge.CompareError:
+		if _, ok := testCase.Expected.(string); !ok && testCase.Expected != nil {
+			return fmt.Errorf("compare mode %q expects an error message or null, got %T", mode, testCase.Expected)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown compare mode %q", mode)
+	}
+}
+
+// formatArgs serializes each input with the declared parameter type at the
+// same position, if any.
+func formatArgs(ch challenge.Challenge, inputs []interface{}, format func(string, interface{}) (string, error)) (string, error) {
+	args := make([]string, len(inputs))
+	for i, input := range inputs {
+		paramType := ""
+		if i < len(ch.ParameterTypes) {
+			paramType = ch.ParameterTypes[i]
+		}
+
+		arg, err := format(paramType, input)
+		if err != nil {
+			return "", fmt.Errorf("argument %d: %w", i+1, err)
+		}
+		args[i] = arg
+	}
+	return strings.Join(args, ", "), nil
+}
+
+// ignoreType adapts a serializer that does not use type information.
+func ignoreType(format func(interface{}) (string, error)) func(string, interface{}) (string, error) {
+	return func(_ string, value interface{}) (string, error) {
+		return format(value)
+	}
+}
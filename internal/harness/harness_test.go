@@ -0,0 +1,184 @@
+package harness
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/crisecheverria/codequest/internal/challenge"
+)
+
+func TestParseResults(t *testing.T) {
+	output := `debug line from the solution
+` + ResultPrefix + `{"index":0,"passed":true,"durationMs":1.5,"actual":5}
+` + ResultPrefix + `{"index":1,"passed":false,"durationMs":0.25,"actual":[1,2]}
+` + ResultPrefix + `{not json}
+` + ResultPrefix + `{"index":2,"passed":false,"durationMs":0,"error":"boom"}
+`
+
+	results, rest := ParseResults(output)
+
+	if len(results) != 3 {
+		t.Fatalf("Expected 3 results, got %d", len(results))
+	}
+
+	if !results[0].Passed || string(results[0].Actual) != "5" {
+		t.Errorf("Unexpected first result: %+v", results[0])
+	}
+
+	if results[1].Passed || string(results[1].Actual) != "[1,2]" {
+		t.Errorf("Unexpected second result: %+v", results[1])
+	}
+
+	if results[2].Error != "boom" {
+		t.Errorf("Expected error 'boom', got '%s'", results[2].Error)
+	}
+
+	if results[0].Duration().Microseconds() != 1500 {
+		t.Errorf("Expected duration of 1.5ms, got %v", results[0].Duration())
+	}
+
+	if !strings.Contains(rest, "debug line from the solution") || !strings.Contains(rest, "{not json}") {
+		t.Errorf("Expected non-protocol lines to be kept as output, got %q", rest)
+	}
+
+	if results[0].Output != "debug line from the solution" || results[1].Output != "" || results[2].Output != ResultPrefix+"{not json}" {
+		t.Errorf("Unexpected output attributed to the cases: %q, %q, %q", results[0].Output, results[1].Output, results[2].Output)
+	}
+}
+
+func TestGenerateNode(t *testing.T) {
+	ch := challenge.Challenge{
+		Language:     "typescript",
+		FunctionName: "multiply",
+	}
+
+	userCode := `function multiply(a: number, b: number): number {
+    return a * b;
+}`
+
+	testCases := []challenge.TestCase{
+		{Input: []interface{}{float64(4), float64(5)}, Expected: float64(20), Description: "should multiply two numbers"},
+		{Input: []interface{}{"x", 1.5}, Expected: []interface{}{"x"}, Description: "should format strings, floats and arrays"},
+	}
+
+	result, err := GenerateNode(ch, userCode, testCases, false)
+	if err != nil {
+		t.Fatalf("GenerateNode() failed: %v", err)
+	}
+
+	// Type annotations are left for the executor's TypeScript toolchain
+	if !strings.Contains(result, userCode) {
+		t.Error("Generated code should contain the solution unchanged")
+	}
+
+	if strings.Contains(result, "process.") && !strings.Contains(result, `globalThis["process"]`) {
+		t.Error("Generated code should not depend on Node's typings")
+	}
+
+	if !strings.Contains(result, `{ run: () => multiply(4, 5), expected: 20, compare: "deep", epsilon: 0 },`) {
+		t.Error("Generated code should contain the first test case")
+	}
+
+	if !strings.Contains(result, `{ run: () => multiply("x", 1.5), expected: ["x"], compare: "deep", epsilon: 0 },`) {
+		t.Error("Generated code should contain the second test case")
+	}
+}
+
+func TestGeneratePHP(t *testing.T) {
+	ch := challenge.Challenge{
+		Language:     "php",
+		FunctionName: "divide",
+	}
+
+	userCode := `<?php
+function divide($a, $b) {
+    return $a / $b;
+}
+?>`
+
+	testCases := []challenge.TestCase{
+		{Input: []interface{}{float64(10), float64(2)}, Expected: float64(5), Description: "should divide two numbers"},
+	}
+
+	result, err := GeneratePHP(ch, userCode, testCases, false)
+	if err != nil {
+		t.Fatalf("GeneratePHP() failed: %v", err)
+	}
+
+	if !strings.HasPrefix(result, "<?php") {
+		t.Error("Generated code should start with PHP opening tag")
+	}
+
+	if strings.Count(result, "<?php") != 1 || strings.Contains(result, "?>") {
+		t.Error("Generated code should embed the solution without its own PHP tags")
+	}
+
+	if !strings.Contains(result, `[fn() => divide(10, 2), 5, "deep", 0],`) {
+		t.Error("Generated code should pair the call with its expected value")
+	}
+}
+
+func TestCheckCompareMode(t *testing.T) {
+	valid := []challenge.TestCase{
+		{Expected: float64(1)},
+		{Expected: float64(1), Compare: challenge.CompareEpsilon},
+		{Expected: "^a+$", Compare: challenge.CompareRegex},
+		{Expected: "boom", Compare: challenge.CompareError},
+		{Expected: nil, Compare: challenge.CompareError},
+	}
+	for _, testCase := range valid {
+		if err := checkCompareMode(testCase); err != nil {
+			t.Errorf("checkCompareMode(%+v) failed: %v", testCase, err)
+		}
+	}
+
+	invalid := []challenge.TestCase{
+		{Expected: float64(1), Compare: "fuzzy"},
+		{Expected: float64(1), Compare: challenge.CompareRegex},
+		{Expected: []interface{}{}, Compare: challenge.CompareError},
+	}
+	for _, testCase := range invalid {
+		if err := checkCompareMode(testCase); err == nil {
+			t.Errorf("checkCompareMode(%+v) should have failed", testCase)
+		}
+	}
+}
+
+func TestGenerateFailFast(t *testing.T) {
+	testCases := []challenge.TestCase{
+		{Input: []interface{}{float64(1), float64(2)}, Expected: float64(3)},
+		{Input: []interface{}{float64(2), float64(2)}, Expected: float64(4)},
+	}
+
+	tests := []struct {
+		name     string
+		generate Generator
+		intType  string
+		off, on  string
+	}{
+		{"go", GenerateGo, "int", "const failFast = false", "const failFast = true"},
+		{"python", GeneratePython, "int", "_cq_fail_fast = False", "_cq_fail_fast = True"},
+		{"node", GenerateNode, "number", "const __cqFailFast = false;", "const __cqFailFast = true;"},
+		{"php", GeneratePHP, "int", "$__cqFailFast = false;", "$__cqFailFast = true;"},
+		{"rust", GenerateRust, "i32", "", "    if failed {\n        std::process::exit(1);\n    }\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ch := challenge.Challenge{FunctionName: "add", ParameterTypes: []string{tt.intType, tt.intType}, ReturnType: tt.intType}
+			withoutFailFast, err := tt.generate(ch, "", testCases, false)
+			if err != nil {
+				t.Fatalf("Generating the harness failed: %v", err)
+			}
+			withFailFast, err := tt.generate(ch, "", testCases, true)
+			if err != nil {
+				t.Fatalf("Generating the harness with fail-fast failed: %v", err)
+			}
+			if !strings.Contains(withoutFailFast, tt.off) || strings.Contains(withoutFailFast, tt.on) {
+				t.Errorf("Expected the harness to run every case:\n%s", withoutFailFast)
+			}
+			if !strings.Contains(withFailFast, tt.on) {
+				t.Errorf("Expected the harness to stop at the first failed case:\n%s", withFailFast)
+			}
+		})
+	}
+}
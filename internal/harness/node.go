@@ -0,0 +1,151 @@
+package harness
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/crisecheverria/codequest/internal/challenge"
+	"github.com/crisecheverria/codequest/internal/literal"
+)
+
+// GenerateNode builds the harness for JavaScript and TypeScript solutions.
+func GenerateNode(ch challenge.Challenge, solutionCode string, testCases []challenge.TestCase, failFast bool) (string, error) {
+	// The harness itself is plain JavaScript that also type checks as
+	// TypeScript, so TypeScript solutions are embedded unchanged and left to
+	// the executor's toolchain
+	var cases strings.Builder
+	for i, testCase := range testCases {
+		if err := checkCompareMode(testCase); err != nil {
+			return "", fmt.Errorf("test case %d: %w", i+1, err)
+		}
+		argsStr, err := formatArgs(ch, testCase.Input, ignoreType(literal.JavaScript))
+		if err != nil {
+			return "", fmt.Errorf("test case %d: %w", i+1, err)
+		}
+		expected, err := literal.JavaScript(testCase.Expected)
+		if err != nil {
+			return "", fmt.Errorf("test case %d: expected value: %w", i+1, err)
+		}
+
+		// A test function is scoped to its own case so several may declare test()
+		callee := ch.FunctionName
+		if testCase.TestFunction != "" {
+			callee = fmt.Sprintf("(() => {\n%s\n  return test;\n})()", testCase.TestFunction)
+		}
+
+		fmt.Fprintf(&cases, "  { run: () => %s(%s), expected: %s, compare: %s, epsilon: %s },\n",
+			callee, argsStr, expected, strconv.Quote(testCase.CompareMode()), strconv.FormatFloat(testCase.Tolerance(), 'g', -1, 64))
+	}
+
+	return fmt.Sprintf(`%s
+
+function __cqNormalize(value) {
+  if (value instanceof Map) {
+    return __cqNormalize(Object.fromEntries(value));
+  }
+  if (value instanceof Set) {
+    return __cqNormalize(Array.from(value));
+  }
+  if (Array.isArray(value)) {
+    return value.map(__cqNormalize);
+  }
+  if (value !== null && typeof value === "object") {
+    const normalized = {};
+    for (const key of Object.keys(value)) {
+      normalized[key] = __cqNormalize(value[key]);
+    }
+    return normalized;
+  }
+  return value;
+}
+
+function __cqEqual(actual, expected, epsilon) {
+  if (typeof actual === "number" && typeof expected === "number") {
+    return actual === expected || Math.abs(actual - expected) <= epsilon;
+  }
+  if (actual === null || expected === null || typeof actual !== "object" || typeof expected !== "object") {
+    return actual === expected;
+  }
+  if (Array.isArray(actual) !== Array.isArray(expected)) {
+    return false;
+  }
+  if (Array.isArray(actual)) {
+    return actual.length === expected.length && actual.every((item, i) => __cqEqual(item, expected[i], epsilon));
+  }
+  const keys = Object.keys(actual);
+  return keys.length === Object.keys(expected).length &&
+    keys.every((key) => Object.prototype.hasOwnProperty.call(expected, key) && __cqEqual(actual[key], expected[key], epsilon));
+}
+
+function __cqUnordered(actual, expected) {
+  if (!Array.isArray(actual) || !Array.isArray(expected)) {
+    return __cqEqual(actual, expected, 0);
+  }
+  const remaining = expected.slice();
+  for (const item of actual) {
+    const index = remaining.findIndex((candidate) => __cqEqual(item, candidate, 0));
+    if (index < 0) {
+      return false;
+    }
+    remaining.splice(index, 1);
+  }
+  return remaining.length === 0;
+}
+
+function __cqCheck(testCase) {
+  if (testCase.compare === "error") {
+    try {
+      testCase.run();
+    } catch (err) {
+      const message = err && err.message !== undefined ? err.message : String(err);
+      return [message, testCase.expected !== null && message === testCase.expected];
+    }
+    return [null, testCase.expected === null];
+  }
+
+  const raw = testCase.run();
+  const actual = __cqNormalize(raw);
+  switch (testCase.compare) {
+    case "exact":
+      return [actual, JSON.stringify(raw) === JSON.stringify(testCase.expected)];
+    case "unordered":
+      return [actual, __cqUnordered(actual, testCase.expected)];
+    case "regex":
+      return [actual, typeof actual === "string" && new RegExp(testCase.expected).test(actual)];
+    default:
+      return [actual, __cqEqual(actual, testCase.expected, testCase.epsilon)];
+  }
+}
+
+const __cqCases = [
+%s];
+const __cqFailFast = %t;
+
+// Looked up through globalThis so type checking does not need Node's typings
+const __cqProcess = globalThis["process"];
+
+let __cqFailed = false;
+__cqCases.forEach((testCase, index) => {
+  if (__cqFailed && __cqFailFast) {
+    return;
+  }
+  const report = { index, passed: false, durationMs: 0, actual: undefined, error: undefined };
+  const start = __cqProcess.hrtime.bigint();
+  try {
+    const [actual, passed] = __cqCheck(testCase);
+    report.actual = actual === undefined ? null : actual;
+    report.passed = passed;
+  } catch (err) {
+    report.error = err && err.stack ? err.stack : String(err);
+  }
+  report.durationMs = Number(__cqProcess.hrtime.bigint() - start) / 1e6;
+  if (!report.passed) {
+    __cqFailed = true;
+  }
+  __cqProcess.stdout.write(%q + JSON.stringify(report) + "\n");
+});
+
+__cqProcess.exitCode = __cqFailed ? 1 : 0;
+`, solutionCode, cases.String(), failFast, ResultPrefix), nil
+}
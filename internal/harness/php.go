@@ -0,0 +1,142 @@
+package harness
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/crisecheverria/codequest/internal/challenge"
+	"github.com/crisecheverria/codequest/internal/literal"
+)
+
+var phpTestFuncPattern = regexp.MustCompile(`function\s+test\s*\(`)
+
+// GeneratePHP builds the harness for PHP solutions.
+func GeneratePHP(ch challenge.Challenge, solutionCode string, testCases []challenge.TestCase, failFast bool) (string, error) {
+	var cases, testFunctions strings.Builder
+	for i, testCase := range testCases {
+		if err := checkCompareMode(testCase); err != nil {
+			return "", fmt.Errorf("test case %d: %w", i+1, err)
+		}
+		argsStr, err := formatArgs(ch, testCase.Input, literal.PHP)
+		if err != nil {
+			return "", fmt.Errorf("test case %d: %w", i+1, err)
+		}
+		expected, err := literal.PHP(ch.ReturnType, testCase.Expected)
+		if err != nil {
+			return "", fmt.Errorf("test case %d: expected value: %w", i+1, err)
+		}
+
+		// PHP functions are global, so each test function gets a unique name
+		callee := ch.FunctionName
+		if testCase.TestFunction != "" {
+			if !phpTestFuncPattern.MatchString(testCase.TestFunction) {
+				return "", fmt.Errorf("test case %d: testFunction must declare function test(...)", i+1)
+			}
+			callee = fmt.Sprintf("__cq_test_%d", i)
+			renamed := phpTestFuncPattern.ReplaceAllLiteralString(testCase.TestFunction, "function "+callee+"(")
+			fmt.Fprintf(&testFunctions, "\n%s\n", renamed)
+		}
+
+		fmt.Fprintf(&cases, "    [fn() => %s(%s), %s, %s, %s],\n",
+			callee, argsStr, expected, strconv.Quote(testCase.CompareMode()), strconv.FormatFloat(testCase.Tolerance(), 'f', -1, 64))
+	}
+
+	// Embed the solution inside our own PHP block so it may omit the tags
+	body := strings.TrimSpace(solutionCode)
+	body = strings.TrimPrefix(body, "<?php")
+	body = strings.TrimSuffix(body, "?>")
+
+	return fmt.Sprintf(`<?php
+%s
+%s
+function __cq_equal($actual, $expected, $epsilon) {
+    if ((is_int($actual) || is_float($actual)) && (is_int($expected) || is_float($expected))) {
+        return $actual == $expected || abs($actual - $expected) <= $epsilon;
+    }
+    if (is_array($actual) && is_array($expected)) {
+        if (count($actual) !== count($expected)) {
+            return false;
+        }
+        foreach ($actual as $key => $value) {
+            if (!array_key_exists($key, $expected) || !__cq_equal($value, $expected[$key], $epsilon)) {
+                return false;
+            }
+        }
+        return true;
+    }
+    return $actual === $expected;
+}
+
+function __cq_unordered($actual, $expected) {
+    if (!is_array($actual) || !is_array($expected)) {
+        return __cq_equal($actual, $expected, 0);
+    }
+    $remaining = array_values($expected);
+    foreach ($actual as $item) {
+        $found = false;
+        foreach ($remaining as $index => $candidate) {
+            if (__cq_equal($item, $candidate, 0)) {
+                array_splice($remaining, $index, 1);
+                $found = true;
+                break;
+            }
+        }
+        if (!$found) {
+            return false;
+        }
+    }
+    return count($remaining) === 0;
+}
+
+function __cq_check($run, $expected, $mode, $epsilon) {
+    if ($mode === "error") {
+        try {
+            $run();
+        } catch (\Throwable $e) {
+            return [$e->getMessage(), $expected !== null && $e->getMessage() === $expected];
+        }
+        return [null, $expected === null];
+    }
+
+    $actual = $run();
+    switch ($mode) {
+        case "exact":
+            return [$actual, $actual === $expected];
+        case "unordered":
+            return [$actual, __cq_unordered($actual, $expected)];
+        case "regex":
+            $pattern = "~" . str_replace("~", "\\~", $expected) . "~";
+            return [$actual, is_string($actual) && preg_match($pattern, $actual) === 1];
+        default:
+            return [$actual, __cq_equal($actual, $expected, $epsilon)];
+    }
+}
+
+$__cqCases = [
+%s];
+$__cqFailFast = %t;
+
+$__cqFailed = false;
+foreach ($__cqCases as $index => [$run, $expected, $mode, $epsilon]) {
+    $report = ["index" => $index, "passed" => false, "durationMs" => 0];
+    $start = hrtime(true);
+    try {
+        [$report["actual"], $report["passed"]] = __cq_check($run, $expected, $mode, $epsilon);
+    } catch (\Throwable $e) {
+        $report["error"] = (string) $e;
+    }
+    $report["durationMs"] = (hrtime(true) - $start) / 1e6;
+    if (!$report["passed"]) {
+        $__cqFailed = true;
+    }
+    echo %q . json_encode($report) . "\n";
+    if ($__cqFailed && $__cqFailFast) {
+        break;
+    }
+}
+
+exit($__cqFailed ? 1 : 0);
+`, body, testFunctions.String(), cases.String(), failFast, ResultPrefix), nil
+}
@@ -0,0 +1,154 @@
+package harness
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/crisecheverria/codequest/internal/challenge"
+	"github.com/crisecheverria/codequest/internal/literal"
+)
+
+// GeneratePython builds the harness for Python solutions.
+func GeneratePython(ch challenge.Challenge, solutionCode string, testCases []challenge.TestCase, failFast bool) (string, error) {
+	var cases, testFunctions strings.Builder
+	for i, testCase := range testCases {
+		if err := checkCompareMode(testCase); err != nil {
+			return "", fmt.Errorf("test case %d: %w", i+1, err)
+		}
+		argsStr, err := formatArgs(ch, testCase.Input, literal.Python)
+		if err != nil {
+			return "", fmt.Errorf("test case %d: %w", i+1, err)
+		}
+		expected, err := literal.Python(ch.ReturnType, testCase.Expected)
+		if err != nil {
+			return "", fmt.Errorf("test case %d: expected value: %w", i+1, err)
+		}
+
+		// A test function is scoped to its own case so several may define test()
+		callee := ch.FunctionName
+		if testCase.TestFunction != "" {
+			callee = fmt.Sprintf("_cq_test_%d()", i)
+			fmt.Fprintf(&testFunctions, "\n\ndef _cq_test_%d():\n%s\n    return test\n", i, indent(testCase.TestFunction, "    "))
+		}
+
+		fmt.Fprintf(&cases, "    (lambda: %s(%s), %s, %s, %s),\n",
+			callee, argsStr, expected, strconv.Quote(testCase.CompareMode()), strconv.FormatFloat(testCase.Tolerance(), 'g', -1, 64))
+	}
+
+	return fmt.Sprintf(`%s
+
+import json as _cq_json
+import re as _cq_re
+import sys as _cq_sys
+import time as _cq_time
+import traceback as _cq_traceback
+
+
+def _cq_normalize(value):
+    # Tuples and lists compare equal so that e.g. itertools results match JSON arrays
+    if isinstance(value, tuple):
+        value = list(value)
+    if isinstance(value, list):
+        return [_cq_normalize(item) for item in value]
+    if isinstance(value, dict):
+        return {key: _cq_normalize(item) for key, item in value.items()}
+    return value
+
+
+def _cq_equal(actual, expected, epsilon=0):
+    if isinstance(actual, bool) or isinstance(expected, bool):
+        return type(actual) is type(expected) and actual == expected
+    if isinstance(actual, (int, float)) and isinstance(expected, (int, float)):
+        return actual == expected or abs(actual - expected) <= epsilon
+    if isinstance(actual, list) and isinstance(expected, list):
+        return len(actual) == len(expected) and all(
+            _cq_equal(a, e, epsilon) for a, e in zip(actual, expected))
+    if isinstance(actual, dict) and isinstance(expected, dict):
+        return actual.keys() == expected.keys() and all(
+            _cq_equal(actual[key], expected[key], epsilon) for key in actual)
+    return actual == expected
+
+
+def _cq_unordered(actual, expected):
+    if isinstance(actual, (set, frozenset)):
+        actual = list(actual)
+    if isinstance(expected, (set, frozenset)):
+        expected = list(expected)
+    if not isinstance(actual, list) or not isinstance(expected, list):
+        return _cq_equal(actual, expected)
+    remaining = list(expected)
+    for item in actual:
+        for index, candidate in enumerate(remaining):
+            if _cq_equal(item, candidate):
+                del remaining[index]
+                break
+        else:
+            return False
+    return not remaining
+
+
+def _cq_check(call, expected, mode, epsilon):
+    if mode == "error":
+        try:
+            call()
+        except Exception as error:
+            message = str(error)
+            return message, expected is not None and message == expected
+        return None, expected is None
+
+    raw = call()
+    actual = _cq_normalize(raw)
+    if mode == "exact":
+        return actual, type(raw) is type(expected) and raw == expected
+    if mode == "unordered":
+        return actual, _cq_unordered(actual, _cq_normalize(expected))
+    if mode == "regex":
+        return actual, isinstance(actual, str) and _cq_re.search(expected, actual) is not None
+    return actual, _cq_equal(actual, _cq_normalize(expected), epsilon)
+%s
+
+_cq_cases = [
+%s]
+_cq_fail_fast = %s
+
+
+def _cq_run():
+    failed = False
+    for index, (call, expected, mode, epsilon) in enumerate(_cq_cases):
+        report = {"index": index, "passed": False}
+        start = _cq_time.perf_counter()
+        try:
+            report["actual"], report["passed"] = _cq_check(call, expected, mode, epsilon)
+        except Exception:
+            report["error"] = _cq_traceback.format_exc()
+        report["durationMs"] = (_cq_time.perf_counter() - start) * 1000
+        failed = failed or not report["passed"]
+        print(%s + _cq_json.dumps(report, default=repr), flush=True)
+        if failed and _cq_fail_fast:
+            break
+    _cq_sys.exit(1 if failed else 0)
+
+
+_cq_run()
+`, solutionCode, testFunctions.String(), cases.String(), pythonBool(failFast), strconv.Quote(ResultPrefix)), nil
+}
+
+// pythonBool returns the Python literal for b.
+func pythonBool(b bool) string {
+	if b {
+		return "True"
+	}
+	return "False"
+}
+
+// indent prefixes every non-empty line of code with prefix.
+func indent(code, prefix string) string {
+	lines := strings.Split(code, "\n")
+	for i, line := range lines {
+		if strings.TrimSpace(line) != "" {
+			lines[i] = prefix + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
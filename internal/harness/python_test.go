@@ -0,0 +1,99 @@
+package harness
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/crisecheverria/codequest/internal/challenge"
+)
+
+func TestGeneratePython(t *testing.T) {
+	ch := challenge.Challenge{
+		Language:       "python",
+		FunctionName:   "count_characters",
+		ParameterTypes: []string{"str"},
+		ReturnType:     "dict",
+	}
+
+	userCode := `def count_characters(text):
+    return {}`
+
+	testCases := []challenge.TestCase{
+		{
+			Input:       []interface{}{"say \"hi\""},
+			Expected:    map[string]interface{}{"a": float64(1), "s": float64(1)},
+			Description: "should count characters",
+		},
+		{
+			Input:       []interface{}{""},
+			Expected:    map[string]interface{}{},
+			Description: "should handle empty string",
+		},
+	}
+
+	result, err := GeneratePython(ch, userCode, testCases, false)
+	if err != nil {
+		t.Fatalf("GeneratePython() failed: %v", err)
+	}
+
+	if !strings.Contains(result, "def count_characters(text):") {
+		t.Error("Generated code should contain the user's function")
+	}
+
+	if !strings.Contains(result, `(lambda: count_characters("say \"hi\""), {"a": 1, "s": 1}, "deep", 0),`) {
+		t.Error("Generated code should pair escaped inputs with the expected Python dict")
+	}
+
+	if !strings.Contains(result, `(lambda: count_characters(""), {}, "deep", 0),`) {
+		t.Error("Generated code should contain one entry per test case")
+	}
+
+	if !strings.Contains(result, `print("`+ResultPrefix+`"`) {
+		t.Error("Generated code should report results using the harness protocol")
+	}
+}
+
+func TestGeneratePythonCompareModes(t *testing.T) {
+	ch := challenge.Challenge{
+		Language:       "python",
+		FunctionName:   "average",
+		ParameterTypes: []string{"List[float]"},
+		ReturnType:     "float",
+	}
+
+	testCases := []challenge.TestCase{
+		{Input: []interface{}{[]interface{}{float64(1), float64(2)}}, Expected: 1.5, Compare: challenge.CompareEpsilon, Epsilon: 0.01},
+		{Input: []interface{}{[]interface{}{}}, Expected: "division by zero", Compare: challenge.CompareError},
+		{
+			Input:        []interface{}{float64(3)},
+			Expected:     float64(3),
+			TestFunction: "def test(n):\n    return average([n])",
+		},
+	}
+
+	result, err := GeneratePython(ch, "def average(numbers):\n    return sum(numbers) / len(numbers)", testCases, false)
+	if err != nil {
+		t.Fatalf("GeneratePython() failed: %v", err)
+	}
+
+	if !strings.Contains(result, `(lambda: average([1.0, 2.0]), 1.5, "epsilon", 0.01),`) {
+		t.Error("Generated code should pass the epsilon compare mode and tolerance")
+	}
+
+	if !strings.Contains(result, `(lambda: average([]), "division by zero", "error", 0),`) {
+		t.Error("Generated code should pass the error compare mode")
+	}
+
+	if !strings.Contains(result, "def _cq_test_2():\n    def test(n):\n        return average([n])\n    return test") {
+		t.Error("Generated code should scope the test function to its case")
+	}
+
+	if !strings.Contains(result, `(lambda: _cq_test_2()(3), 3.0, "deep", 0),`) {
+		t.Error("Generated code should call the test function instead of the challenge function")
+	}
+
+	testCases[0].Compare = "fuzzy"
+	if _, err := GeneratePython(ch, "", testCases, false); err == nil {
+		t.Error("Expected an error for an unknown compare mode")
+	}
+}
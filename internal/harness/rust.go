@@ -0,0 +1,537 @@
+package harness
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/crisecheverria/codequest/internal/challenge"
+	"github.com/crisecheverria/codequest/internal/literal"
+)
+
+var (
+	rustTestFuncPattern = regexp.MustCompile(`fn\s+test\s*\(`)
+	rustMainPattern     = regexp.MustCompile(`(?m)^[ \t]*(pub\s+)?fn\s+main\s*\(\s*\)`)
+)
+
+// GenerateRust builds the harness for Rust solutions as a single crate
+// compiled with rustc, without Cargo.
+func GenerateRust(ch challenge.Challenge, solutionCode string, testCases []challenge.TestCase, failFast bool) (string, error) {
+	var cases, testFunctions strings.Builder
+	for i, testCase := range testCases {
+		caseCode, testFunction, err := rustCase(ch, i, testCase)
+		if err != nil {
+			return "", fmt.Errorf("test case %d: %w", i+1, err)
+		}
+		cases.WriteString(caseCode)
+		if failFast {
+			cases.WriteString("    if failed {\n        std::process::exit(1);\n    }\n")
+		}
+		if testFunction != "" {
+			testFunctions.WriteString(testFunction + "\n\n")
+		}
+	}
+
+	return fmt.Sprintf(rustHarnessTemplate, cleanRustUserCode(solutionCode), testFunctions.String(), cases.String(), strconv.Quote(ResultPrefix)), nil
+}
+
+// rustCase returns the block that runs one test case inside main, and the
+// renamed test function if the case has one.
+func rustCase(ch challenge.Challenge, index int, testCase challenge.TestCase) (string, string, error) {
+	mode := testCase.CompareMode()
+	if err := checkCompareMode(testCase); err != nil {
+		return "", "", err
+	}
+	if mode == challenge.CompareRegex {
+		return "", "", fmt.Errorf("compare mode %q is not supported for Rust", mode)
+	}
+
+	callee, paramTypes, returnType, testFunction := ch.FunctionName, ch.ParameterTypes, strings.TrimSpace(ch.ReturnType), ""
+	if testCase.TestFunction != "" {
+		// A test function declares its own parameter types
+		callee = fmt.Sprintf("cq_test_%d", index)
+		var err error
+		testFunction, paramTypes, returnType, err = renameRustTestFunction(testCase.TestFunction, callee)
+		if err != nil {
+			return "", "", err
+		}
+	}
+
+	var body strings.Builder
+	fmt.Fprintf(&body, "    failed |= !cq_run(%d, || {\n", index)
+
+	// Arguments are bound first so that borrowed temporaries such as
+	// &vec![...] live as long as a returned reference
+	args := make([]string, len(testCase.Input))
+	for i, input := range testCase.Input {
+		paramType := ""
+		if i < len(paramTypes) {
+			paramType = paramTypes[i]
+		}
+		arg, err := literal.Rust(paramType, input)
+		if err != nil {
+			return "", "", fmt.Errorf("argument %d: %w", i+1, err)
+		}
+		args[i] = fmt.Sprintf("cq_arg%d", i)
+		fmt.Fprintf(&body, "        let %s = %s;\n", args[i], arg)
+	}
+	call := fmt.Sprintf("%s(%s)", callee, strings.Join(args, ", "))
+
+	okType, isResult := rustResultType(returnType)
+	switch {
+	case mode == challenge.CompareError:
+		if !isResult {
+			return "", "", fmt.Errorf("compare mode %q requires a function returning Result, got %q", mode, returnType)
+		}
+		expected, _ := testCase.Expected.(string)
+		fmt.Fprintf(&body, "        match %s {\n", call)
+		fmt.Fprintf(&body, "            Ok(_) => (String::from(\"null\"), %t),\n", testCase.Expected == nil)
+		body.WriteString("            Err(err) => {\n")
+		body.WriteString("                let message = err.to_string();\n")
+		fmt.Fprintf(&body, "                (message.cq_json(), %t && message == %s)\n", testCase.Expected != nil, strconv.Quote(expected))
+		body.WriteString("            }\n")
+		body.WriteString("        }\n")
+
+	case returnType == "()" || (returnType == "" && testCase.Expected == nil):
+		fmt.Fprintf(&body, "        %s;\n", call)
+		fmt.Fprintf(&body, "        (String::from(\"null\"), %t)\n", testCase.Expected == nil)
+
+	default:
+		if isResult {
+			fmt.Fprintf(&body, "        let result = match %s {\n", call)
+			body.WriteString("            Ok(value) => value,\n")
+			body.WriteString("            Err(err) => panic!(\"returned an error: {:?}\", err),\n")
+			body.WriteString("        };\n")
+			returnType = okType
+		} else {
+			fmt.Fprintf(&body, "        let result = %s;\n", call)
+		}
+
+		// The expected value's type is inferred from the result's
+		expected, err := literal.Rust(returnType, testCase.Expected)
+		if err != nil {
+			return "", "", fmt.Errorf("expected value: %w", err)
+		}
+		fmt.Fprintf(&body, "        let expected = %s;\n", expected)
+
+		switch mode {
+		case challenge.CompareExact:
+			body.WriteString("        (result.cq_json(), result == expected)\n")
+		case challenge.CompareUnordered:
+			body.WriteString("        (result.cq_json(), result.cq_unordered(&expected))\n")
+		default:
+			fmt.Fprintf(&body, "        (result.cq_json(), result.cq_eq(&expected, %s))\n", rustEpsilon(testCase.Tolerance()))
+		}
+	}
+
+	body.WriteString("    });\n")
+	return body.String(), testFunction, nil
+}
+
+// rustResultType returns T for a Result<T, E> type.
+func rustResultType(returnType string) (string, bool) {
+	if !strings.HasPrefix(returnType, "Result<") || !strings.HasSuffix(returnType, ">") {
+		return "", false
+	}
+
+	return rustSplitTopLevel(returnType[len("Result<") : len(returnType)-1])[0], true
+}
+
+// rustSplitTopLevel splits s on commas that are not nested in generics,
+// tuples or arrays.
+func rustSplitTopLevel(s string) []string {
+	var parts []string
+	depth, start := 0, 0
+	for i, r := range s {
+		switch r {
+		case '<', '(', '[':
+			depth++
+		case '>', ')', ']':
+			depth--
+		case ',':
+			if depth == 0 {
+				parts = append(parts, strings.TrimSpace(s[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, strings.TrimSpace(s[start:]))
+}
+
+func rustEpsilon(epsilon float64) string {
+	s := strconv.FormatFloat(epsilon, 'g', -1, 64)
+	if !strings.ContainsAny(s, ".e") {
+		s += ".0"
+	}
+	return s
+}
+
+// renameRustTestFunction renames fn test in source to name and returns its
+// declared parameter and return types.
+func renameRustTestFunction(source, name string) (string, []string, string, error) {
+	loc := rustTestFuncPattern.FindStringIndex(source)
+	if loc == nil {
+		return "", nil, "", fmt.Errorf("testFunction must declare fn test(...)")
+	}
+
+	// Skip the parameter list, which may itself contain parentheses
+	depth := 1
+	i := loc[1]
+	for ; i < len(source) && depth > 0; i++ {
+		switch source[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		}
+	}
+	brace := strings.Index(source[i:], "{")
+	if depth != 0 || brace < 0 {
+		return "", nil, "", fmt.Errorf("testFunction has an invalid fn test signature")
+	}
+
+	// Parameters are declared as "name: Type"
+	var paramTypes []string
+	if params := strings.TrimSpace(source[loc[1] : i-1]); params != "" {
+		for _, param := range rustSplitTopLevel(params) {
+			_, paramType, _ := strings.Cut(param, ":")
+			paramTypes = append(paramTypes, strings.TrimSpace(paramType))
+		}
+	}
+
+	returnType := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(source[i:i+brace]), "->"))
+	renamed := source[:loc[0]] + "fn " + name + "(" + source[loc[1]:]
+	return renamed, paramTypes, returnType, nil
+}
+
+// cleanRustUserCode removes the solution's own main function, which the
+// harness replaces.
+func cleanRustUserCode(code string) string {
+	loc := rustMainPattern.FindStringIndex(code)
+	if loc == nil {
+		return code
+	}
+
+	open := strings.Index(code[loc[1]:], "{")
+	if open < 0 {
+		return code
+	}
+
+	depth := 0
+	for i := loc[1] + open; i < len(code); i++ {
+		switch code[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return code[:loc[0]] + strings.TrimLeft(code[i+1:], "\n")
+			}
+		}
+	}
+	return code
+}
+
+const rustHarnessTemplate = `#![allow(dead_code, unused_imports, unused_mut, unused_variables)]
+
+%s
+
+%s// Everything below is generated by codequest
+
+trait CqValue {
+    fn cq_json(&self) -> String;
+    fn cq_eq(&self, other: &Self, epsilon: f64) -> bool;
+    fn cq_unordered(&self, other: &Self) -> bool {
+        self.cq_eq(other, 0.0)
+    }
+}
+
+fn cq_json_string(s: &str) -> String {
+    let mut out = String::from("\"");
+    for c in s.chars() {
+        match c {
+            '"' => out.push_str("\\\""),
+            '\\' => out.push_str("\\\\"),
+            '\n' => out.push_str("\\n"),
+            '\r' => out.push_str("\\r"),
+            '\t' => out.push_str("\\t"),
+            c if (c as u32) < 0x20 => out.push_str(&format!("\\u{:04x}", c as u32)),
+            c => out.push(c),
+        }
+    }
+    out.push('"');
+    out
+}
+
+// Map keys must be JSON strings
+fn cq_json_key(json: String) -> String {
+    if json.starts_with('"') {
+        json
+    } else {
+        cq_json_string(&json)
+    }
+}
+
+fn cq_json_list<'a, T: CqValue + 'a>(items: impl Iterator<Item = &'a T>) -> String {
+    let parts: Vec<String> = items.map(|item| item.cq_json()).collect();
+    format!("[{}]", parts.join(","))
+}
+
+fn cq_eq_list<T: CqValue>(a: &[T], b: &[T], epsilon: f64) -> bool {
+    a.len() == b.len() && a.iter().zip(b.iter()).all(|(x, y)| x.cq_eq(y, epsilon))
+}
+
+// cq_unordered_list compares two lists as multisets
+fn cq_unordered_list<T: CqValue>(a: &[T], b: &[T]) -> bool {
+    if a.len() != b.len() {
+        return false;
+    }
+    let mut used = vec![false; b.len()];
+    'outer: for x in a {
+        for (i, y) in b.iter().enumerate() {
+            if !used[i] && x.cq_eq(y, 0.0) {
+                used[i] = true;
+                continue 'outer;
+            }
+        }
+        return false;
+    }
+    true
+}
+
+macro_rules! cq_exact {
+    ($($t:ty),*) => {$(
+        impl CqValue for $t {
+            fn cq_json(&self) -> String {
+                self.to_string()
+            }
+            fn cq_eq(&self, other: &Self, _epsilon: f64) -> bool {
+                self == other
+            }
+        }
+    )*};
+}
+cq_exact!(i8, i16, i32, i64, i128, isize, u8, u16, u32, u64, u128, usize, bool);
+
+macro_rules! cq_float {
+    ($($t:ty),*) => {$(
+        impl CqValue for $t {
+            fn cq_json(&self) -> String {
+                if self.is_finite() { format!("{}", self) } else { String::from("null") }
+            }
+            fn cq_eq(&self, other: &Self, epsilon: f64) -> bool {
+                self == other || ((*self as f64) - (*other as f64)).abs() <= epsilon
+            }
+        }
+    )*};
+}
+cq_float!(f32, f64);
+
+impl CqValue for char {
+    fn cq_json(&self) -> String {
+        cq_json_string(&self.to_string())
+    }
+    fn cq_eq(&self, other: &Self, _epsilon: f64) -> bool {
+        self == other
+    }
+}
+
+impl CqValue for String {
+    fn cq_json(&self) -> String {
+        cq_json_string(self)
+    }
+    fn cq_eq(&self, other: &Self, _epsilon: f64) -> bool {
+        self == other
+    }
+}
+
+impl<'a> CqValue for &'a str {
+    fn cq_json(&self) -> String {
+        cq_json_string(self)
+    }
+    fn cq_eq(&self, other: &Self, _epsilon: f64) -> bool {
+        self == other
+    }
+}
+
+impl CqValue for () {
+    fn cq_json(&self) -> String {
+        String::from("null")
+    }
+    fn cq_eq(&self, _other: &Self, _epsilon: f64) -> bool {
+        true
+    }
+}
+
+impl<T: CqValue> CqValue for Option<T> {
+    fn cq_json(&self) -> String {
+        match self {
+            Some(value) => value.cq_json(),
+            None => String::from("null"),
+        }
+    }
+    fn cq_eq(&self, other: &Self, epsilon: f64) -> bool {
+        match (self, other) {
+            (Some(a), Some(b)) => a.cq_eq(b, epsilon),
+            (None, None) => true,
+            _ => false,
+        }
+    }
+}
+
+impl<T: CqValue> CqValue for Box<T> {
+    fn cq_json(&self) -> String {
+        (**self).cq_json()
+    }
+    fn cq_eq(&self, other: &Self, epsilon: f64) -> bool {
+        (**self).cq_eq(&**other, epsilon)
+    }
+}
+
+impl<T: CqValue> CqValue for Vec<T> {
+    fn cq_json(&self) -> String {
+        cq_json_list(self.iter())
+    }
+    fn cq_eq(&self, other: &Self, epsilon: f64) -> bool {
+        cq_eq_list(self, other, epsilon)
+    }
+    fn cq_unordered(&self, other: &Self) -> bool {
+        cq_unordered_list(self, other)
+    }
+}
+
+impl<'a, T: CqValue> CqValue for &'a [T] {
+    fn cq_json(&self) -> String {
+        cq_json_list(self.iter())
+    }
+    fn cq_eq(&self, other: &Self, epsilon: f64) -> bool {
+        cq_eq_list(self, other, epsilon)
+    }
+    fn cq_unordered(&self, other: &Self) -> bool {
+        cq_unordered_list(self, other)
+    }
+}
+
+impl<T: CqValue, const N: usize> CqValue for [T; N] {
+    fn cq_json(&self) -> String {
+        cq_json_list(self.iter())
+    }
+    fn cq_eq(&self, other: &Self, epsilon: f64) -> bool {
+        cq_eq_list(self, other, epsilon)
+    }
+    fn cq_unordered(&self, other: &Self) -> bool {
+        cq_unordered_list(self, other)
+    }
+}
+
+impl<T: CqValue> CqValue for std::collections::VecDeque<T> {
+    fn cq_json(&self) -> String {
+        cq_json_list(self.iter())
+    }
+    fn cq_eq(&self, other: &Self, epsilon: f64) -> bool {
+        self.len() == other.len() && self.iter().zip(other.iter()).all(|(a, b)| a.cq_eq(b, epsilon))
+    }
+}
+
+impl<K: CqValue + Eq + std::hash::Hash, V: CqValue> CqValue for std::collections::HashMap<K, V> {
+    fn cq_json(&self) -> String {
+        let mut parts: Vec<String> = self
+            .iter()
+            .map(|(key, value)| format!("{}:{}", cq_json_key(key.cq_json()), value.cq_json()))
+            .collect();
+        parts.sort();
+        format!("{{{}}}", parts.join(","))
+    }
+    fn cq_eq(&self, other: &Self, epsilon: f64) -> bool {
+        self.len() == other.len()
+            && self.iter().all(|(key, value)| other.get(key).map_or(false, |o| value.cq_eq(o, epsilon)))
+    }
+}
+
+impl<K: CqValue + Ord, V: CqValue> CqValue for std::collections::BTreeMap<K, V> {
+    fn cq_json(&self) -> String {
+        let parts: Vec<String> = self
+            .iter()
+            .map(|(key, value)| format!("{}:{}", cq_json_key(key.cq_json()), value.cq_json()))
+            .collect();
+        format!("{{{}}}", parts.join(","))
+    }
+    fn cq_eq(&self, other: &Self, epsilon: f64) -> bool {
+        self.len() == other.len()
+            && self.iter().all(|(key, value)| other.get(key).map_or(false, |o| value.cq_eq(o, epsilon)))
+    }
+}
+
+impl<T: CqValue + Eq + std::hash::Hash> CqValue for std::collections::HashSet<T> {
+    fn cq_json(&self) -> String {
+        let mut parts: Vec<String> = self.iter().map(|item| item.cq_json()).collect();
+        parts.sort();
+        format!("[{}]", parts.join(","))
+    }
+    fn cq_eq(&self, other: &Self, _epsilon: f64) -> bool {
+        self == other
+    }
+}
+
+impl<T: CqValue + Ord> CqValue for std::collections::BTreeSet<T> {
+    fn cq_json(&self) -> String {
+        cq_json_list(self.iter())
+    }
+    fn cq_eq(&self, other: &Self, _epsilon: f64) -> bool {
+        self == other
+    }
+}
+
+macro_rules! cq_tuple {
+    ($($name:ident $index:tt),+) => {
+        impl<$($name: CqValue),+> CqValue for ($($name,)+) {
+            fn cq_json(&self) -> String {
+                let parts: Vec<String> = vec![$(self.$index.cq_json()),+];
+                format!("[{}]", parts.join(","))
+            }
+            fn cq_eq(&self, other: &Self, epsilon: f64) -> bool {
+                $(self.$index.cq_eq(&other.$index, epsilon))&&+
+            }
+        }
+    };
+}
+cq_tuple!(A 0);
+cq_tuple!(A 0, B 1);
+cq_tuple!(A 0, B 1, C 2);
+cq_tuple!(A 0, B 1, C 2, D 3);
+
+static CQ_PANIC: std::sync::Mutex<String> = std::sync::Mutex::new(String::new());
+
+// cq_run runs one case, reporting a panic as the case's error
+fn cq_run<F: FnOnce() -> (String, bool)>(index: usize, case: F) -> bool {
+    let start = std::time::Instant::now();
+    let outcome = std::panic::catch_unwind(std::panic::AssertUnwindSafe(case));
+    let duration_ms = start.elapsed().as_secs_f64() * 1000.0;
+
+    let (passed, detail) = match outcome {
+        Ok((actual, passed)) => (passed, format!("\"actual\":{}", actual)),
+        Err(_) => {
+            let message = CQ_PANIC.lock().map(|message| message.clone()).unwrap_or_default();
+            (false, format!("\"error\":{}", cq_json_string(&message)))
+        }
+    };
+    println!(
+        "{}{{\"index\":{},\"passed\":{},\"durationMs\":{},{}}}",
+        %[4]s, index, passed, duration_ms, detail
+    );
+    passed
+}
+
+fn main() {
+    std::panic::set_hook(Box::new(|info| {
+        if let Ok(mut message) = CQ_PANIC.lock() {
+            *message = info.to_string();
+        }
+    }));
+
+    let mut failed = false;
+%[3]s
+    std::process::exit(if failed { 1 } else { 0 });
+}
+`
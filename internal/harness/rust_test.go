@@ -0,0 +1,146 @@
+package harness
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/crisecheverria/codequest/internal/challenge"
+)
+
+func TestGenerateRust(t *testing.T) {
+	ch := challenge.Challenge{
+		Language:       "rust",
+		FunctionName:   "sum",
+		ParameterTypes: []string{"&[i64]"},
+		ReturnType:     "i64",
+	}
+
+	userCode := `fn sum(numbers: &[i64]) -> i64 {
+    numbers.iter().sum()
+}
+
+fn main() {
+    println!("{}", sum(&[1, 2]));
+}`
+
+	testCases := []challenge.TestCase{
+		{Input: []interface{}{[]interface{}{float64(1), float64(2)}}, Expected: float64(3)},
+		{Input: []interface{}{[]interface{}{}}, Expected: float64(0), Compare: challenge.CompareExact},
+	}
+
+	result, err := GenerateRust(ch, userCode, testCases, false)
+	if err != nil {
+		t.Fatalf("GenerateRust() failed: %v", err)
+	}
+
+	if !strings.Contains(result, "fn sum(numbers: &[i64]) -> i64") {
+		t.Error("Generated code should contain the user's function")
+	}
+
+	if !strings.Contains(result, "let cq_arg0 = &[1, 2];") || !strings.Contains(result, "let result = sum(cq_arg0);") {
+		t.Error("Generated code should bind the arguments and call the function")
+	}
+
+	if !strings.Contains(result, "(result.cq_json(), result.cq_eq(&expected, 0.0))") {
+		t.Error("Generated code should compare results deeply by default")
+	}
+
+	if !strings.Contains(result, "(result.cq_json(), result == expected)") {
+		t.Error("Generated code should compare exact results with ==")
+	}
+
+	if strings.Count(result, "fn main()") != 1 || strings.Contains(result, `println!("{}", sum(&[1, 2]))`) {
+		t.Error("Generated code should replace the user's main function")
+	}
+}
+
+func TestGenerateRustCompareModes(t *testing.T) {
+	ch := challenge.Challenge{
+		Language:       "rust",
+		FunctionName:   "parse_age",
+		ParameterTypes: []string{"&str"},
+		ReturnType:     "Result<u32, String>",
+	}
+
+	testCases := []challenge.TestCase{
+		{Input: []interface{}{"-5"}, Expected: "invalid age", Compare: challenge.CompareError},
+		{Input: []interface{}{"30"}, Expected: float64(30)},
+	}
+
+	result, err := GenerateRust(ch, "", testCases, false)
+	if err != nil {
+		t.Fatalf("GenerateRust() failed: %v", err)
+	}
+
+	if !strings.Contains(result, `(message.cq_json(), true && message == "invalid age")`) {
+		t.Error("Generated code should compare the error message")
+	}
+
+	if !strings.Contains(result, `Err(err) => panic!("returned an error: {:?}", err),`) || !strings.Contains(result, "let expected = 30;") {
+		t.Error("Generated code should unwrap Ok values outside error mode")
+	}
+
+	// Error mode needs a function that returns a Result
+	ch.ReturnType = "u32"
+	if _, err := GenerateRust(ch, "", testCases[:1], false); err == nil {
+		t.Error("Expected an error when the function does not return a Result")
+	}
+
+	testCases = []challenge.TestCase{
+		{Input: []interface{}{"x"}, Expected: "^x$", Compare: challenge.CompareRegex},
+	}
+	if _, err := GenerateRust(ch, "", testCases, false); err == nil {
+		t.Error("Expected an error for the unsupported regex mode")
+	}
+}
+
+func TestGenerateRustTestFunction(t *testing.T) {
+	ch := challenge.Challenge{
+		Language:       "rust",
+		FunctionName:   "Counter::new",
+		ParameterTypes: []string{"i32"},
+		ReturnType:     "Counter",
+	}
+
+	testCases := []challenge.TestCase{
+		{
+			Input:        []interface{}{"ab", float64(2)},
+			Expected:     float64(4),
+			TestFunction: "fn test(s: &str, times: usize) -> usize { s.repeat(times).len() }",
+		},
+	}
+
+	result, err := GenerateRust(ch, "", testCases, false)
+	if err != nil {
+		t.Fatalf("GenerateRust() failed: %v", err)
+	}
+
+	if !strings.Contains(result, "fn cq_test_0(s: &str, times: usize) -> usize { s.repeat(times).len() }") {
+		t.Error("Generated code should contain the renamed test function")
+	}
+
+	// Arguments follow the test function's parameter types, not the challenge's
+	if !strings.Contains(result, `let cq_arg0 = "ab";`) || !strings.Contains(result, "let result = cq_test_0(cq_arg0, cq_arg1);") {
+		t.Error("Generated code should call the test function with its own parameter types")
+	}
+}
+
+func TestRustResultType(t *testing.T) {
+	tests := []struct {
+		returnType string
+		okType     string
+		isResult   bool
+	}{
+		{"Result<u32, String>", "u32", true},
+		{"Result<HashMap<String, i32>, Box<dyn Error>>", "HashMap<String, i32>", true},
+		{"Result<(), String>", "()", true},
+		{"Option<u32>", "", false},
+	}
+
+	for _, tt := range tests {
+		okType, isResult := rustResultType(tt.returnType)
+		if okType != tt.okType || isResult != tt.isResult {
+			t.Errorf("rustResultType(%q) = %q, %t, want %q, %t", tt.returnType, okType, isResult, tt.okType, tt.isResult)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package harness
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/crisecheverria/codequest/internal/challenge"
+	"github.com/crisecheverria/codequest/internal/literal"
+)
+
+// TemplateData is passed to a harness template.
+type TemplateData struct {
+	Challenge    challenge.Challenge
+	Solution     string
+	Cases        []TemplateCase
+	ResultPrefix string
+	// FailFast asks the program to exit after the first case that didn't
+	// pass
+	FailFast bool
+}
+
+// TemplateCase is one test case as seen by a harness template.
+type TemplateCase struct {
+	Index        int
+	Description  string
+	Input        []interface{}
+	Expected     interface{}
+	Compare      string
+	Epsilon      float64
+	TestFunction string
+}
+
+var templateFuncs = template.FuncMap{
+	// json encodes a value as JSON
+	"json": literal.JavaScript,
+	// args encodes each input as JSON, separated by commas
+	"args": func(inputs []interface{}) (string, error) {
+		args := make([]string, len(inputs))
+		for i, input := range inputs {
+			arg, err := literal.JavaScript(input)
+			if err != nil {
+				return "", fmt.Errorf("argument %d: %w", i+1, err)
+			}
+			args[i] = arg
+		}
+		return strings.Join(args, ", "), nil
+	},
+	// quote returns a double quoted string literal with C style escapes
+	"quote": strconv.Quote,
+}
+
+// FromTemplate returns a Generator that executes a text/template harness.
+// The template must print one ResultPrefix line per case, like the built-in
+// harnesses.
+func FromTemplate(name, text string) (Generator, error) {
+	tmpl, err := template.New(name).Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse harness template: %w", err)
+	}
+
+	return func(ch challenge.Challenge, solutionCode string, testCases []challenge.TestCase, failFast bool) (string, error) {
+		data := TemplateData{
+			Challenge:    ch,
+			Solution:     solutionCode,
+			Cases:        make([]TemplateCase, len(testCases)),
+			ResultPrefix: ResultPrefix,
+			FailFast:     failFast,
+		}
+		for i, testCase := range testCases {
+			if err := checkCompareMode(testCase); err != nil {
+				return "", fmt.Errorf("test case %d: %w", i+1, err)
+			}
+			data.Cases[i] = TemplateCase{
+				Index:        i,
+				Description:  testCase.Description,
+				Input:        testCase.Input,
+				Expected:     testCase.Expected,
+				Compare:      testCase.CompareMode(),
+				Epsilon:      testCase.Tolerance(),
+				TestFunction: testCase.TestFunction,
+			}
+		}
+
+		var out strings.Builder
+		if err := tmpl.Execute(&out, data); err != nil {
+			return "", fmt.Errorf("failed to execute harness template: %w", err)
+		}
+		return out.String(), nil
+	}, nil
+}
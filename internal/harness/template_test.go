@@ -0,0 +1,50 @@
+package harness
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/crisecheverria/codequest/internal/challenge"
+)
+
+func TestFromTemplate(t *testing.T) {
+	generate, err := FromTemplate("ruby.tmpl", `{{.Solution}}
+{{range .Cases}}check({{$.Challenge.FunctionName}}({{args .Input}}), {{json .Expected}}, {{quote .Compare}})
+{{end}}puts {{quote .ResultPrefix}}`)
+	if err != nil {
+		t.Fatalf("FromTemplate() failed: %v", err)
+	}
+
+	ch := challenge.Challenge{Language: "ruby", FunctionName: "add"}
+	testCases := []challenge.TestCase{
+		{Input: []interface{}{float64(1), "a"}, Expected: []interface{}{"x"}},
+		{Input: []interface{}{}, Expected: nil, Compare: challenge.CompareError},
+	}
+
+	result, err := generate(ch, "def add(a, b) a + b end", testCases, false)
+	if err != nil {
+		t.Fatalf("Generator failed: %v", err)
+	}
+
+	for _, want := range []string{
+		"def add(a, b) a + b end",
+		`check(add(1, "a"), ["x"], "deep")`,
+		`check(add(), null, "error")`,
+		`puts "__CODEQUEST_RESULT__ "`,
+	} {
+		if !strings.Contains(result, want) {
+			t.Errorf("Generated code should contain %q, got:\n%s", want, result)
+		}
+	}
+
+	testCases[0].Compare = "fuzzy"
+	if _, err := generate(ch, "", testCases, false); err == nil {
+		t.Error("Expected an error for an unknown compare mode")
+	}
+}
+
+func TestFromTemplateInvalid(t *testing.T) {
+	if _, err := FromTemplate("broken.tmpl", "{{range .Cases}"); err == nil {
+		t.Error("Expected an error for an invalid template")
+	}
+}
@@ -0,0 +1,361 @@
+package literal
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Go returns a Go expression of type goType holding value. Supported types
+// are the basic types, slices, fixed size arrays, maps, pointers, named
+// struct types (from a JSON object), tuples such as "(int, int)" for
+// multiple return values, and "...T" for variadic arguments. An empty type,
+// "any" or "interface{}" infers a type from the value.
+func Go(goType string, value interface{}) (string, error) {
+	goType = strings.TrimSpace(goType)
+
+	switch {
+	case goType == "" || goType == "any" || goType == "interface{}":
+		return goInferred(value)
+
+	case strings.HasPrefix(goType, "(") && strings.HasSuffix(goType, ")"):
+		return goList(splitTopLevel(goType[1:len(goType)-1]), value)
+
+	case strings.HasPrefix(goType, "..."):
+		items, ok := value.([]interface{})
+		if !ok {
+			return "", fmt.Errorf("expected a list for variadic %s, got %T", goType, value)
+		}
+		elemTypes := make([]string, len(items))
+		for i := range items {
+			elemTypes[i] = goType[3:]
+		}
+		return goList(elemTypes, value)
+
+	case strings.HasPrefix(goType, "[]"):
+		if value == nil {
+			return "nil", nil
+		}
+		items, ok := value.([]interface{})
+		if !ok {
+			return "", fmt.Errorf("expected a list for %s, got %T", goType, value)
+		}
+		return goComposite(goType, goType[2:], items)
+
+	case strings.HasPrefix(goType, "["):
+		end := strings.Index(goType, "]")
+		if end < 0 {
+			return "", fmt.Errorf("invalid Go array type %q", goType)
+		}
+		size, err := strconv.Atoi(goType[1:end])
+		if err != nil {
+			return "", fmt.Errorf("invalid Go array type %q", goType)
+		}
+		items, ok := value.([]interface{})
+		if !ok {
+			return "", fmt.Errorf("expected a list for %s, got %T", goType, value)
+		}
+		if len(items) > size {
+			return "", fmt.Errorf("%d values do not fit in %s", len(items), goType)
+		}
+		return goComposite(goType, goType[end+1:], items)
+
+	case strings.HasPrefix(goType, "map["):
+		return goMap(goType, value)
+
+	case strings.HasPrefix(goType, "*"):
+		if value == nil {
+			return "nil", nil
+		}
+		elem, err := Go(goType[1:], value)
+		if err != nil {
+			return "", err
+		}
+		if _, isObject := value.(map[string]interface{}); !isObject {
+			return "", fmt.Errorf("cannot take the address of a %s literal", goType[1:])
+		}
+		return "&" + elem, nil
+
+	case strings.HasPrefix(goType, "func") || strings.HasPrefix(goType, "chan ") || goType == "error":
+		return "", fmt.Errorf("cannot serialize a value of Go type %s", goType)
+	}
+
+	if basic, ok := goBasic(goType, value); ok {
+		return basic()
+	}
+
+	return goNamed(goType, value)
+}
+
+func goList(types []string, value interface{}) (string, error) {
+	items, ok := value.([]interface{})
+	if !ok {
+		return "", fmt.Errorf("expected a list of %d values, got %T", len(types), value)
+	}
+	if len(items) != len(types) {
+		return "", fmt.Errorf("expected %d values, got %d", len(types), len(items))
+	}
+
+	parts := make([]string, len(items))
+	for i, item := range items {
+		part, err := Go(types[i], item)
+		if err != nil {
+			return "", err
+		}
+		parts[i] = part
+	}
+	return strings.Join(parts, ", "), nil
+}
+
+func goComposite(goType, elemType string, items []interface{}) (string, error) {
+	parts := make([]string, len(items))
+	for i, item := range items {
+		part, err := Go(elemType, item)
+		if err != nil {
+			return "", err
+		}
+		parts[i] = part
+	}
+	return goType + "{" + strings.Join(parts, ", ") + "}", nil
+}
+
+func goMap(goType string, value interface{}) (string, error) {
+	if value == nil {
+		return "nil", nil
+	}
+	m, ok := value.(map[string]interface{})
+	if !ok {
+		return "", fmt.Errorf("expected an object for %s, got %T", goType, value)
+	}
+
+	// Find the bracket that closes the key type, e.g. map[[2]int]string
+	depth := 0
+	end := -1
+	for i := len("map"); i < len(goType); i++ {
+		if goType[i] == '[' {
+			depth++
+		} else if goType[i] == ']' {
+			depth--
+			if depth == 0 {
+				end = i
+				break
+			}
+		}
+	}
+	if end < 0 {
+		return "", fmt.Errorf("invalid Go map type %q", goType)
+	}
+	keyType := goType[len("map["):end]
+	valueType := goType[end+1:]
+
+	parts := make([]string, 0, len(m))
+	for _, key := range sortedKeys(m) {
+		var keyValue interface{} = key
+		if keyType != "string" {
+			// JSON object keys are always strings; numeric Go keys are parsed back
+			number, err := strconv.ParseFloat(key, 64)
+			if err != nil {
+				return "", fmt.Errorf("map key %q is not a valid %s", key, keyType)
+			}
+			keyValue = number
+		}
+
+		keyLiteral, err := Go(keyType, keyValue)
+		if err != nil {
+			return "", err
+		}
+		valueLiteral, err := Go(valueType, m[key])
+		if err != nil {
+			return "", err
+		}
+		parts = append(parts, keyLiteral+": "+valueLiteral)
+	}
+
+	return goType + "{" + strings.Join(parts, ", ") + "}", nil
+}
+
+// goBasic handles Go's predeclared scalar types.
+func goBasic(goType string, value interface{}) (func() (string, error), bool) {
+	switch goType {
+	case "string":
+		return func() (string, error) {
+			s, ok := value.(string)
+			if !ok {
+				return "", fmt.Errorf("expected a string, got %T", value)
+			}
+			return strconv.Quote(s), nil
+		}, true
+
+	case "bool":
+		return func() (string, error) {
+			b, ok := value.(bool)
+			if !ok {
+				return "", fmt.Errorf("expected a bool, got %T", value)
+			}
+			return strconv.FormatBool(b), nil
+		}, true
+
+	case "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64", "byte", "uintptr":
+		return func() (string, error) {
+			n, ok := toFloat(value)
+			if !ok || !isWhole(n) {
+				return "", fmt.Errorf("expected an integer for %s, got %v", goType, value)
+			}
+			return strconv.FormatInt(int64(n), 10), nil
+		}, true
+
+	case "rune":
+		return func() (string, error) {
+			if s, ok := value.(string); ok && len([]rune(s)) == 1 {
+				return strconv.QuoteRune([]rune(s)[0]), nil
+			}
+			n, ok := toFloat(value)
+			if !ok || !isWhole(n) {
+				return "", fmt.Errorf("expected a character or code point for rune, got %v", value)
+			}
+			return strconv.FormatInt(int64(n), 10), nil
+		}, true
+
+	case "float32", "float64":
+		return func() (string, error) {
+			n, ok := toFloat(value)
+			if !ok {
+				return "", fmt.Errorf("expected a number for %s, got %T", goType, value)
+			}
+			if err := checkFinite(n); err != nil {
+				return "", err
+			}
+			return strconv.FormatFloat(n, 'g', -1, 64), nil
+		}, true
+	}
+
+	return nil, false
+}
+
+// goNamed builds a literal for a user declared type. Objects become struct
+// literals with untyped field values; scalars are converted to the type.
+func goNamed(goType string, value interface{}) (string, error) {
+	if !isGoIdentifier(goType) {
+		return "", fmt.Errorf("unsupported Go type %q", goType)
+	}
+
+	if m, ok := value.(map[string]interface{}); ok {
+		parts := make([]string, 0, len(m))
+		for _, key := range sortedKeys(m) {
+			if !isGoIdentifier(key) || strings.Contains(key, ".") {
+				return "", fmt.Errorf("%q is not a valid field name for %s", key, goType)
+			}
+			field, err := goInferred(m[key])
+			if err != nil {
+				return "", fmt.Errorf("field %s of %s: %w", key, goType, err)
+			}
+			parts = append(parts, key+": "+field)
+		}
+		return goType + "{" + strings.Join(parts, ", ") + "}", nil
+	}
+
+	inner, err := goInferred(value)
+	if err != nil {
+		return "", err
+	}
+	return goType + "(" + inner + ")", nil
+}
+
+// goInferred picks a Go type from the value alone. Lists and objects must be
+// homogeneous so that a concrete slice or map type can be chosen.
+func goInferred(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "nil", nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case string:
+		return strconv.Quote(v), nil
+	case []interface{}:
+		elemType, err := inferGoType(v)
+		if err != nil {
+			return "", err
+		}
+		return goComposite("[]"+elemType, elemType, v)
+	case map[string]interface{}:
+		values := make([]interface{}, 0, len(v))
+		for _, key := range sortedKeys(v) {
+			values = append(values, v[key])
+		}
+		elemType, err := inferGoType(values)
+		if err != nil {
+			return "", err
+		}
+		return goMap("map[string]"+elemType, v)
+	}
+
+	if n, ok := toFloat(value); ok {
+		if err := checkFinite(n); err != nil {
+			return "", err
+		}
+		if isWhole(n) {
+			return strconv.FormatInt(int64(n), 10), nil
+		}
+		return strconv.FormatFloat(n, 'g', -1, 64), nil
+	}
+
+	return "", fmt.Errorf("cannot serialize %T as Go", value)
+}
+
+func inferGoType(items []interface{}) (string, error) {
+	elemType := ""
+	for _, item := range items {
+		var itemType string
+		switch v := item.(type) {
+		case string:
+			itemType = "string"
+		case bool:
+			itemType = "bool"
+		case []interface{}:
+			inner, err := inferGoType(v)
+			if err != nil {
+				return "", err
+			}
+			itemType = "[]" + inner
+		default:
+			n, ok := toFloat(item)
+			if !ok {
+				return "", fmt.Errorf("cannot infer a Go type for %T; declare the type explicitly", item)
+			}
+			itemType = "int"
+			if !isWhole(n) {
+				itemType = "float64"
+			}
+		}
+
+		switch {
+		case elemType == "" || elemType == itemType:
+			elemType = itemType
+		case elemType == "int" && itemType == "float64", elemType == "float64" && itemType == "int":
+			elemType = "float64"
+		default:
+			return "", fmt.Errorf("cannot infer a Go type for mixed %s and %s values", elemType, itemType)
+		}
+	}
+
+	if elemType == "" {
+		return "interface{}", nil
+	}
+	return elemType, nil
+}
+
+func isGoIdentifier(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, r := range s {
+		if r == '.' && i > 0 {
+			continue
+		}
+		if r != '_' && !unicode.IsLetter(r) && (i == 0 || !unicode.IsDigit(r)) {
+			return false
+		}
+	}
+	return true
+}
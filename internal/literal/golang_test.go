@@ -0,0 +1,74 @@
+package literal
+
+import "testing"
+
+func TestGo(t *testing.T) {
+	tests := []struct {
+		goType   string
+		value    interface{}
+		expected string
+	}{
+		{"int", float64(42), "42"},
+		{"int64", -7, "-7"},
+		{"float64", 2.5, "2.5"},
+		{"float64", float64(5), "5"},
+		{"string", "say \"hi\"\n", `"say \"hi\"\n"`},
+		{"bool", true, "true"},
+		{"rune", "a", "'a'"},
+		{"[]int", []interface{}{float64(1), float64(2)}, "[]int{1, 2}"},
+		{"[]int", []interface{}{}, "[]int{}"},
+		{"[]int", nil, "nil"},
+		{"[]string", []interface{}{"a", "b"}, `[]string{"a", "b"}`},
+		{"[][]int", []interface{}{[]interface{}{float64(1)}, []interface{}{}}, "[][]int{[]int{1}, []int{}}"},
+		{"[5]int", []interface{}{float64(1), float64(5), float64(3), float64(2), float64(4)}, "[5]int{1, 5, 3, 2, 4}"},
+		{"map[string]int", map[string]interface{}{"b": float64(2), "a": float64(1)}, `map[string]int{"a": 1, "b": 2}`},
+		{"map[int]string", map[string]interface{}{"1": "one"}, `map[int]string{1: "one"}`},
+		{"map[string][]int", map[string]interface{}{"x": []interface{}{float64(1)}}, `map[string][]int{"x": []int{1}}`},
+		{"Person", map[string]interface{}{"Name": "Alice", "Age": float64(30)}, `Person{Age: 30, Name: "Alice"}`},
+		{"*Person", map[string]interface{}{"Name": "Bob"}, `&Person{Name: "Bob"}`},
+		{"*Person", nil, "nil"},
+		{"Celsius", 21.5, "Celsius(21.5)"},
+		{"(int, int)", []interface{}{float64(3), float64(1)}, "3, 1"},
+		{"(string, bool)", []interface{}{"x", false}, `"x", false`},
+		{"...int", []interface{}{float64(1), float64(2)}, "1, 2"},
+		{"interface{}", []interface{}{float64(1), 2.5}, "[]float64{1, 2.5}"},
+		{"any", map[string]interface{}{"a": "b"}, `map[string]string{"a": "b"}`},
+		{"", nil, "nil"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.goType+"/"+tt.expected, func(t *testing.T) {
+			result, err := Go(tt.goType, tt.value)
+			if err != nil {
+				t.Fatalf("Go(%q, %v) failed: %v", tt.goType, tt.value, err)
+			}
+			if result != tt.expected {
+				t.Errorf("Go(%q, %v) = %s, expected %s", tt.goType, tt.value, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestGoErrors(t *testing.T) {
+	tests := []struct {
+		goType string
+		value  interface{}
+	}{
+		{"int", 2.5},
+		{"int", "3"},
+		{"string", float64(1)},
+		{"[]int", "not a list"},
+		{"[2]int", []interface{}{float64(1), float64(2), float64(3)}},
+		{"map[int]string", map[string]interface{}{"x": "y"}},
+		{"(int, int)", []interface{}{float64(1)}},
+		{"func(int) bool", nil},
+		{"Person", map[string]interface{}{"bad key": float64(1)}},
+		{"", []interface{}{"a", float64(1)}},
+	}
+
+	for _, tt := range tests {
+		if result, err := Go(tt.goType, tt.value); err == nil {
+			t.Errorf("Go(%q, %v) = %s, expected an error", tt.goType, tt.value, result)
+		}
+	}
+}
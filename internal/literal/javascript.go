@@ -0,0 +1,20 @@
+package literal
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// JavaScript returns a JavaScript (and TypeScript) expression for value.
+// JSON is a subset of JavaScript expression syntax, so the value is encoded
+// as JSON without HTML escaping.
+func JavaScript(value interface{}) (string, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err != nil {
+		return "", fmt.Errorf("cannot serialize %T as JavaScript: %w", value, err)
+	}
+	return string(bytes.TrimRight(buf.Bytes(), "\n")), nil
+}
@@ -0,0 +1,89 @@
+// Package literal serializes decoded JSON test values (inputs and expected
+// results) into source code literals for each supported language, using the
+// challenge's declared parameter and return types where the target language
+// needs them.
+package literal
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// sortedKeys returns the keys of m in a stable order so that generated code
+// is deterministic.
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// splitTopLevel splits a type list such as "int, map[string]int" on commas
+// that are not nested inside brackets, braces, parentheses or generics.
+func splitTopLevel(s string) []string {
+	var parts []string
+	depth := 0
+	start := 0
+
+	for i, r := range s {
+		switch r {
+		case '(', '[', '{', '<':
+			depth++
+		case ')', ']', '}', '>':
+			depth--
+		case ',':
+			if depth == 0 {
+				parts = append(parts, strings.TrimSpace(s[start:i]))
+				start = i + 1
+			}
+		}
+	}
+
+	if last := strings.TrimSpace(s[start:]); last != "" || len(parts) > 0 {
+		parts = append(parts, last)
+	}
+	return parts
+}
+
+// unwrap returns the text between a prefix such as "List[" and the matching
+// closing bracket, reporting whether typeName has that shape.
+func unwrap(typeName, prefix, suffix string) (string, bool) {
+	if strings.HasPrefix(typeName, prefix) && strings.HasSuffix(typeName, suffix) {
+		return strings.TrimSpace(typeName[len(prefix) : len(typeName)-len(suffix)]), true
+	}
+	return "", false
+}
+
+func isWhole(v float64) bool {
+	return v == math.Trunc(v) && !math.IsInf(v, 0) && math.Abs(v) < 1<<53
+}
+
+func checkFinite(v float64) error {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return fmt.Errorf("cannot serialize non-finite number %v", v)
+	}
+	return nil
+}
+
+// toFloat normalizes the numeric types that can appear in decoded or
+// hand-built test values.
+func toFloat(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	default:
+		return 0, false
+	}
+}
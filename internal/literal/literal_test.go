@@ -0,0 +1,81 @@
+package literal
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestJavaScript(t *testing.T) {
+	tests := []struct {
+		value    interface{}
+		expected string
+	}{
+		{nil, "null"},
+		{float64(20), "20"},
+		{1.5, "1.5"},
+		{"a \"quoted\" <tag>", `"a \"quoted\" <tag>"`},
+		{[]interface{}{float64(1), "two", false}, `[1,"two",false]`},
+		{map[string]interface{}{"b": float64(2), "a": []interface{}{}}, `{"a":[],"b":2}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expected, func(t *testing.T) {
+			result, err := JavaScript(tt.value)
+			if err != nil {
+				t.Fatalf("JavaScript(%v) failed: %v", tt.value, err)
+			}
+			if result != tt.expected {
+				t.Errorf("JavaScript(%v) = %s, expected %s", tt.value, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestPHP(t *testing.T) {
+	tests := []struct {
+		phpType  string
+		value    interface{}
+		expected string
+	}{
+		{"", nil, "null"},
+		{"bool", true, "true"},
+		{"int", float64(5), "5"},
+		{"float", float64(5), "5.0"},
+		{"?float", 2.5, "2.5"},
+		{"string", `it's a \ path`, `'it\'s a \\ path'`},
+		{"array", []interface{}{float64(1), "a"}, "[1, 'a']"},
+		{"float[]", []interface{}{float64(1), float64(2)}, "[1.0, 2.0]"},
+		{"array<string, float>", map[string]interface{}{"b": float64(2), "a": float64(1)}, "['a' => 1.0, 'b' => 2.0]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.phpType+"/"+tt.expected, func(t *testing.T) {
+			result, err := PHP(tt.phpType, tt.value)
+			if err != nil {
+				t.Fatalf("PHP(%q, %v) failed: %v", tt.phpType, tt.value, err)
+			}
+			if result != tt.expected {
+				t.Errorf("PHP(%q, %v) = %s, expected %s", tt.phpType, tt.value, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSplitTopLevel(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{"int, int", []string{"int", "int"}},
+		{"map[string]int, error", []string{"map[string]int", "error"}},
+		{"Dict[str, int], Tuple[int, int]", []string{"Dict[str, int]", "Tuple[int, int]"}},
+		{"array<string, float>", []string{"array<string, float>"}},
+		{"", nil},
+	}
+
+	for _, tt := range tests {
+		if result := splitTopLevel(tt.input); !reflect.DeepEqual(result, tt.expected) {
+			t.Errorf("splitTopLevel(%q) = %q, expected %q", tt.input, result, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package literal
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// PHP returns a PHP literal for value. phpType is an optional type such as
+// "float", "?int", "float[]" or "array<string, float>"; it keeps whole
+// numbers as floats where a float is declared, which matters because the
+// harness compares with ===.
+func PHP(phpType string, value interface{}) (string, error) {
+	phpType = strings.TrimPrefix(strings.TrimSpace(phpType), "?")
+
+	switch v := value.(type) {
+	case nil:
+		return "null", nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case string:
+		return phpString(v), nil
+	case []interface{}:
+		elemType := phpElemType(phpType)
+		parts := make([]string, len(v))
+		for i, item := range v {
+			part, err := PHP(elemType, item)
+			if err != nil {
+				return "", err
+			}
+			parts[i] = part
+		}
+		return "[" + strings.Join(parts, ", ") + "]", nil
+	case map[string]interface{}:
+		elemType := phpElemType(phpType)
+		parts := make([]string, 0, len(v))
+		for _, key := range sortedKeys(v) {
+			part, err := PHP(elemType, v[key])
+			if err != nil {
+				return "", err
+			}
+			parts = append(parts, phpString(key)+" => "+part)
+		}
+		return "[" + strings.Join(parts, ", ") + "]", nil
+	}
+
+	n, ok := toFloat(value)
+	if !ok {
+		return "", fmt.Errorf("cannot serialize %T as PHP", value)
+	}
+	if err := checkFinite(n); err != nil {
+		return "", err
+	}
+	if isWhole(n) {
+		if phpType == "float" {
+			return strconv.FormatFloat(n, 'f', 1, 64), nil
+		}
+		return strconv.FormatInt(int64(n), 10), nil
+	}
+	return strconv.FormatFloat(n, 'g', -1, 64), nil
+}
+
+// phpElemType returns the element type of "T[]" or "array<V>" / "array<K, V>".
+func phpElemType(phpType string) string {
+	if strings.HasSuffix(phpType, "[]") {
+		return strings.TrimSuffix(phpType, "[]")
+	}
+	if inner, ok := unwrap(phpType, "array<", ">"); ok {
+		types := splitTopLevel(inner)
+		return types[len(types)-1]
+	}
+	return ""
+}
+
+// phpString returns a single quoted PHP string, where only backslashes and
+// single quotes need escaping and no variables are interpolated.
+func phpString(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `'`, `\'`)
+	return "'" + s + "'"
+}
@@ -0,0 +1,144 @@
+package literal
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Python returns a Python literal for value. pythonType is an optional type
+// hint such as "float", "List[float]", "Dict[str, int]", "Tuple[int, int]"
+// or "Optional[str]"; it keeps whole numbers as floats where a float is
+// declared, builds tuples and sets, and converts numeric dictionary keys.
+func Python(pythonType string, value interface{}) (string, error) {
+	pythonType = strings.TrimSpace(pythonType)
+
+	if inner, ok := unwrapPython(pythonType, "Optional"); ok {
+		return Python(inner, value)
+	}
+
+	switch v := value.(type) {
+	case nil:
+		return "None", nil
+	case bool:
+		if v {
+			return "True", nil
+		}
+		return "False", nil
+	case string:
+		return strconv.Quote(v), nil
+	case []interface{}:
+		return pythonSequence(pythonType, v)
+	case map[string]interface{}:
+		return pythonDict(pythonType, v)
+	}
+
+	n, ok := toFloat(value)
+	if !ok {
+		return "", fmt.Errorf("cannot serialize %T as Python", value)
+	}
+	if err := checkFinite(n); err != nil {
+		return "", err
+	}
+	if isWhole(n) {
+		if pythonType == "float" {
+			return strconv.FormatFloat(n, 'f', 1, 64), nil
+		}
+		return strconv.FormatInt(int64(n), 10), nil
+	}
+	return strconv.FormatFloat(n, 'g', -1, 64), nil
+}
+
+func pythonSequence(pythonType string, items []interface{}) (string, error) {
+	// Element types: List[T] and Set[T] repeat one type, Tuple[A, B] lists them
+	elemType := func(int) string { return "" }
+	open, close := "[", "]"
+
+	if inner, ok := unwrapPython(pythonType, "List"); ok {
+		elemType = func(int) string { return inner }
+	} else if inner, ok := unwrapPython(pythonType, "Set"); ok {
+		elemType = func(int) string { return inner }
+		open, close = "{", "}"
+	} else if inner, ok := unwrapPython(pythonType, "Tuple"); ok {
+		elemType = tupleElemType(splitTopLevel(inner))
+		open, close = "(", ")"
+	} else if inner, ok := unwrap(pythonType, "(", ")"); ok {
+		elemType = tupleElemType(splitTopLevel(inner))
+		open, close = "(", ")"
+	} else if pythonType == "tuple" {
+		open, close = "(", ")"
+	} else if pythonType == "set" {
+		open, close = "{", "}"
+	}
+
+	if open == "{" && len(items) == 0 {
+		return "set()", nil
+	}
+
+	parts := make([]string, len(items))
+	for i, item := range items {
+		part, err := Python(elemType(i), item)
+		if err != nil {
+			return "", err
+		}
+		parts[i] = part
+	}
+
+	joined := strings.Join(parts, ", ")
+	if open == "(" && len(parts) == 1 {
+		joined += ","
+	}
+	return open + joined + close, nil
+}
+
+func tupleElemType(types []string) func(int) string {
+	return func(i int) string {
+		// Tuple[int, ...] is a variable length tuple of ints
+		if len(types) == 2 && types[1] == "..." {
+			return types[0]
+		}
+		if i < len(types) {
+			return types[i]
+		}
+		return ""
+	}
+}
+
+func pythonDict(pythonType string, m map[string]interface{}) (string, error) {
+	keyType, valueType := "", ""
+	if inner, ok := unwrapPython(pythonType, "Dict"); ok {
+		if types := splitTopLevel(inner); len(types) == 2 {
+			keyType, valueType = types[0], types[1]
+		}
+	}
+
+	parts := make([]string, 0, len(m))
+	for _, key := range sortedKeys(m) {
+		keyLiteral := strconv.Quote(key)
+		if keyType == "int" || keyType == "float" {
+			// JSON object keys are always strings; numeric Python keys are parsed back
+			number, err := strconv.ParseFloat(key, 64)
+			if err != nil {
+				return "", fmt.Errorf("dict key %q is not a valid %s", key, keyType)
+			}
+			keyLiteral, _ = Python(keyType, number)
+		}
+
+		valueLiteral, err := Python(valueType, m[key])
+		if err != nil {
+			return "", err
+		}
+		parts = append(parts, keyLiteral+": "+valueLiteral)
+	}
+
+	return "{" + strings.Join(parts, ", ") + "}", nil
+}
+
+// unwrapPython matches both the typing module spelling (List[int]) and the
+// builtin generic spelling (list[int]).
+func unwrapPython(pythonType, name string) (string, bool) {
+	if inner, ok := unwrap(pythonType, name+"[", "]"); ok {
+		return inner, true
+	}
+	return unwrap(pythonType, strings.ToLower(name)+"[", "]")
+}
@@ -0,0 +1,45 @@
+package literal
+
+import "testing"
+
+func TestPython(t *testing.T) {
+	tests := []struct {
+		pythonType string
+		value      interface{}
+		expected   string
+	}{
+		{"", nil, "None"},
+		{"", true, "True"},
+		{"bool", false, "False"},
+		{"int", float64(42), "42"},
+		{"float", float64(5), "5.0"},
+		{"float", 2.5, "2.5"},
+		{"str", "it's \"quoted\"", `"it's \"quoted\""`},
+		{"list", []interface{}{float64(1), "a", nil}, `[1, "a", None]`},
+		{"list", []interface{}{[]interface{}{float64(1)}, []interface{}{}}, "[[1], []]"},
+		{"List[float]", []interface{}{float64(1), 2.5}, "[1.0, 2.5]"},
+		{"list[float]", []interface{}{float64(3)}, "[3.0]"},
+		{"dict", map[string]interface{}{"b": true, "a": []interface{}{}}, `{"a": [], "b": True}`},
+		{"Dict[str, float]", map[string]interface{}{"x": float64(1)}, `{"x": 1.0}`},
+		{"Dict[int, str]", map[string]interface{}{"2": "b", "1": "a"}, `{1: "a", 2: "b"}`},
+		{"Tuple[int, float]", []interface{}{float64(1), float64(2)}, "(1, 2.0)"},
+		{"Tuple[int, ...]", []interface{}{float64(1)}, "(1,)"},
+		{"(int, int)", []interface{}{float64(3), float64(1)}, "(3, 1)"},
+		{"Set[int]", []interface{}{}, "set()"},
+		{"set", []interface{}{"a"}, `{"a"}`},
+		{"Optional[float]", float64(1), "1.0"},
+		{"Optional[float]", nil, "None"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pythonType+"/"+tt.expected, func(t *testing.T) {
+			result, err := Python(tt.pythonType, tt.value)
+			if err != nil {
+				t.Fatalf("Python(%q, %v) failed: %v", tt.pythonType, tt.value, err)
+			}
+			if result != tt.expected {
+				t.Errorf("Python(%q, %v) = %s, expected %s", tt.pythonType, tt.value, result, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,336 @@
+package literal
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Rust returns a Rust expression of type rustType holding value. Supported
+// types are the integer and float primitives, bool, char, String, &str,
+// references (&T, &mut T, &[T]), Vec<T>, VecDeque<T>, [T; N], Option<T>,
+// Box<T>, HashMap<K, V>, BTreeMap<K, V>, HashSet<T>, BTreeSet<T> and tuples.
+// Collections are built with fully qualified std paths so that the harness
+// does not depend on the solution's use declarations. An empty type infers a
+// type from the value.
+func Rust(rustType string, value interface{}) (string, error) {
+	rustType = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(rustType), "std::collections::"))
+
+	switch {
+	case rustType == "":
+		return rustInferred(value)
+
+	case strings.HasPrefix(rustType, "&mut "):
+		inner, err := Rust(rustType[len("&mut "):], value)
+		if err != nil {
+			return "", err
+		}
+		return "&mut " + inner, nil
+
+	case rustType == "&str" || rustType == "&'static str":
+		s, ok := value.(string)
+		if !ok {
+			return "", fmt.Errorf("expected a string for &str, got %T", value)
+		}
+		return rustQuote(s), nil
+
+	case strings.HasPrefix(rustType, "&["):
+		items, ok := value.([]interface{})
+		if !ok {
+			return "", fmt.Errorf("expected a list for %s, got %T", rustType, value)
+		}
+		parts, err := rustItems(strings.TrimSuffix(rustType[2:], "]"), items)
+		if err != nil {
+			return "", err
+		}
+		return "&[" + parts + "]", nil
+
+	case strings.HasPrefix(rustType, "&"):
+		inner, err := Rust(rustType[1:], value)
+		if err != nil {
+			return "", err
+		}
+		return "&" + inner, nil
+
+	case rustType == "()":
+		if value != nil {
+			return "", fmt.Errorf("expected null for (), got %T", value)
+		}
+		return "()", nil
+
+	case strings.HasPrefix(rustType, "(") && strings.HasSuffix(rustType, ")"):
+		// A one-element tuple type is written with a trailing comma, (T,)
+		types := splitTopLevel(strings.TrimSuffix(strings.TrimSpace(rustType[1:len(rustType)-1]), ","))
+		items, ok := value.([]interface{})
+		if !ok || len(items) != len(types) {
+			return "", fmt.Errorf("expected a list of %d values for %s, got %v", len(types), rustType, value)
+		}
+		parts := make([]string, len(items))
+		for i, item := range items {
+			part, err := Rust(types[i], item)
+			if err != nil {
+				return "", err
+			}
+			parts[i] = part
+		}
+		if len(parts) == 1 {
+			return "(" + parts[0] + ",)", nil
+		}
+		return "(" + strings.Join(parts, ", ") + ")", nil
+
+	case strings.HasPrefix(rustType, "[") && strings.HasSuffix(rustType, "]"):
+		// The last semicolon separates the length, e.g. [[i64; 2]; 3]
+		elemType := rustType[1 : len(rustType)-1]
+		semicolon := strings.LastIndex(elemType, ";")
+		items, isList := value.([]interface{})
+		if !isList {
+			return "", fmt.Errorf("expected a list for %s, got %T", rustType, value)
+		}
+		if semicolon >= 0 {
+			size := elemType[semicolon+1:]
+			elemType = elemType[:semicolon]
+			n, err := strconv.Atoi(strings.TrimSpace(size))
+			if err != nil || n != len(items) {
+				return "", fmt.Errorf("%d values do not match %s", len(items), rustType)
+			}
+		}
+		parts, err := rustItems(elemType, items)
+		if err != nil {
+			return "", err
+		}
+		return "[" + parts + "]", nil
+	}
+
+	if inner, ok := unwrap(rustType, "Option<", ">"); ok {
+		if value == nil {
+			return "None", nil
+		}
+		some, err := Rust(inner, value)
+		if err != nil {
+			return "", err
+		}
+		return "Some(" + some + ")", nil
+	}
+
+	if inner, ok := unwrap(rustType, "Box<", ">"); ok {
+		boxed, err := Rust(inner, value)
+		if err != nil {
+			return "", err
+		}
+		return "Box::new(" + boxed + ")", nil
+	}
+
+	for _, prefix := range []string{"Vec<", "VecDeque<", "HashSet<", "BTreeSet<"} {
+		if inner, ok := unwrap(rustType, prefix, ">"); ok {
+			return rustSequence(prefix[:len(prefix)-1], inner, value)
+		}
+	}
+
+	for _, prefix := range []string{"HashMap<", "BTreeMap<"} {
+		if inner, ok := unwrap(rustType, prefix, ">"); ok {
+			return rustMap(prefix[:len(prefix)-1], inner, value)
+		}
+	}
+
+	return rustPrimitive(rustType, value)
+}
+
+func rustItems(elemType string, items []interface{}) (string, error) {
+	parts := make([]string, len(items))
+	for i, item := range items {
+		part, err := Rust(elemType, item)
+		if err != nil {
+			return "", err
+		}
+		parts[i] = part
+	}
+	return strings.Join(parts, ", "), nil
+}
+
+func rustSequence(kind, elemType string, value interface{}) (string, error) {
+	items, ok := value.([]interface{})
+	if !ok {
+		return "", fmt.Errorf("expected a list for %s<%s>, got %T", kind, elemType, value)
+	}
+	parts, err := rustItems(elemType, items)
+	if err != nil {
+		return "", err
+	}
+
+	switch {
+	case kind == "Vec":
+		return "vec![" + parts + "]", nil
+	case len(items) == 0:
+		return "std::collections::" + kind + "::new()", nil
+	default:
+		return "std::collections::" + kind + "::from([" + parts + "])", nil
+	}
+}
+
+func rustMap(kind, params string, value interface{}) (string, error) {
+	types := splitTopLevel(params)
+	if len(types) != 2 {
+		return "", fmt.Errorf("invalid Rust map type %s<%s>", kind, params)
+	}
+	m, ok := value.(map[string]interface{})
+	if !ok {
+		return "", fmt.Errorf("expected an object for %s<%s>, got %T", kind, params, value)
+	}
+	if len(m) == 0 {
+		return "std::collections::" + kind + "::new()", nil
+	}
+
+	keyType, valueType := types[0], types[1]
+	parts := make([]string, 0, len(m))
+	for _, key := range sortedKeys(m) {
+		var keyValue interface{} = key
+		if isRustNumber(keyType) {
+			// JSON object keys are always strings; numeric keys are parsed back
+			number, err := strconv.ParseFloat(key, 64)
+			if err != nil {
+				return "", fmt.Errorf("map key %q is not a valid %s", key, keyType)
+			}
+			keyValue = number
+		}
+
+		keyLiteral, err := Rust(keyType, keyValue)
+		if err != nil {
+			return "", err
+		}
+		valueLiteral, err := Rust(valueType, m[key])
+		if err != nil {
+			return "", err
+		}
+		parts = append(parts, "("+keyLiteral+", "+valueLiteral+")")
+	}
+
+	return "std::collections::" + kind + "::from([" + strings.Join(parts, ", ") + "])", nil
+}
+
+func isRustNumber(rustType string) bool {
+	switch rustType {
+	case "i8", "i16", "i32", "i64", "i128", "isize",
+		"u8", "u16", "u32", "u64", "u128", "usize", "f32", "f64":
+		return true
+	}
+	return false
+}
+
+func rustPrimitive(rustType string, value interface{}) (string, error) {
+	switch rustType {
+	case "String":
+		s, ok := value.(string)
+		if !ok {
+			return "", fmt.Errorf("expected a string, got %T", value)
+		}
+		return "String::from(" + rustQuote(s) + ")", nil
+
+	case "bool":
+		b, ok := value.(bool)
+		if !ok {
+			return "", fmt.Errorf("expected a bool, got %T", value)
+		}
+		return strconv.FormatBool(b), nil
+
+	case "char":
+		s, ok := value.(string)
+		if !ok || len([]rune(s)) != 1 {
+			return "", fmt.Errorf("expected a single character for char, got %v", value)
+		}
+		return "'" + rustEscape(s, '\'') + "'", nil
+
+	case "f32", "f64":
+		n, ok := toFloat(value)
+		if !ok {
+			return "", fmt.Errorf("expected a number for %s, got %T", rustType, value)
+		}
+		if err := checkFinite(n); err != nil {
+			return "", err
+		}
+		return rustFloat(n), nil
+	}
+
+	if !isRustNumber(rustType) {
+		return "", fmt.Errorf("unsupported Rust type %q", rustType)
+	}
+
+	n, ok := toFloat(value)
+	if !ok || !isWhole(n) {
+		return "", fmt.Errorf("expected an integer for %s, got %v", rustType, value)
+	}
+	if n < 0 && strings.HasPrefix(rustType, "u") {
+		return "", fmt.Errorf("%v does not fit in %s", value, rustType)
+	}
+	return strconv.FormatInt(int64(n), 10), nil
+}
+
+// rustInferred picks a Rust type from the value alone: whole numbers are
+// integers, strings are String and lists are Vec.
+func rustInferred(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "None", nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case string:
+		return "String::from(" + rustQuote(v) + ")", nil
+	case []interface{}:
+		parts, err := rustItems("", v)
+		if err != nil {
+			return "", err
+		}
+		return "vec![" + parts + "]", nil
+	case map[string]interface{}:
+		return "", fmt.Errorf("cannot infer a Rust type for an object; declare the type explicitly")
+	}
+
+	n, ok := toFloat(value)
+	if !ok {
+		return "", fmt.Errorf("cannot serialize %T as Rust", value)
+	}
+	if err := checkFinite(n); err != nil {
+		return "", err
+	}
+	if isWhole(n) {
+		return strconv.FormatInt(int64(n), 10), nil
+	}
+	return rustFloat(n), nil
+}
+
+// rustFloat formats n so that Rust always reads a float literal.
+func rustFloat(n float64) string {
+	s := strconv.FormatFloat(n, 'g', -1, 64)
+	if !strings.ContainsAny(s, ".e") {
+		s += ".0"
+	}
+	return s
+}
+
+func rustQuote(s string) string {
+	return `"` + rustEscape(s, '"') + `"`
+}
+
+// rustEscape escapes s for a Rust string or char literal delimited by
+// quote. Rust has no \a, \b, \f or \v escapes, so other control characters
+// use \u{...}.
+func rustEscape(s string, quote rune) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == quote || r == '\\':
+			b.WriteRune('\\')
+			b.WriteRune(r)
+		case r == '\n':
+			b.WriteString(`\n`)
+		case r == '\r':
+			b.WriteString(`\r`)
+		case r == '\t':
+			b.WriteString(`\t`)
+		case r < 0x20 || r == 0x7f:
+			fmt.Fprintf(&b, `\u{%x}`, r)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
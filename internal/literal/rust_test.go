@@ -0,0 +1,79 @@
+package literal
+
+import "testing"
+
+func TestRust(t *testing.T) {
+	tests := []struct {
+		rustType string
+		value    interface{}
+		expected string
+	}{
+		{"i32", float64(42), "42"},
+		{"i64", -7, "-7"},
+		{"usize", float64(3), "3"},
+		{"f64", float64(5), "5.0"},
+		{"f64", 2.5, "2.5"},
+		{"bool", true, "true"},
+		{"char", "a", "'a'"},
+		{"char", "'", `'\''`},
+		{"String", "say \"hi\"\n", `String::from("say \"hi\"\n")`},
+		{"&str", "bell\a", `"bell\u{7}"`},
+		{"Vec<i32>", []interface{}{float64(1), float64(2)}, "vec![1, 2]"},
+		{"Vec<Vec<i32>>", []interface{}{[]interface{}{float64(1)}, []interface{}{}}, "vec![vec![1], vec![]]"},
+		{"&[i64]", []interface{}{float64(1), float64(2)}, "&[1, 2]"},
+		{"&Vec<String>", []interface{}{"a"}, `&vec![String::from("a")]`},
+		{"&mut Vec<i32>", []interface{}{}, "&mut vec![]"},
+		{"[i32; 3]", []interface{}{float64(1), float64(2), float64(3)}, "[1, 2, 3]"},
+		{"[[u8; 2]; 1]", []interface{}{[]interface{}{float64(1), float64(2)}}, "[[1, 2]]"},
+		{"Option<i32>", nil, "None"},
+		{"Option<String>", "x", `Some(String::from("x"))`},
+		{"Box<i32>", float64(1), "Box::new(1)"},
+		{"(i32, char)", []interface{}{float64(1), "c"}, "(1, 'c')"},
+		{"(bool,)", []interface{}{false}, "(false,)"},
+		{"()", nil, "()"},
+		{"HashMap<String, i32>", map[string]interface{}{"b": float64(2), "a": float64(1)}, `std::collections::HashMap::from([(String::from("a"), 1), (String::from("b"), 2)])`},
+		{"std::collections::BTreeMap<u32, bool>", map[string]interface{}{"1": true}, "std::collections::BTreeMap::from([(1, true)])"},
+		{"HashMap<String, i32>", map[string]interface{}{}, "std::collections::HashMap::new()"},
+		{"HashSet<i32>", []interface{}{float64(1)}, "std::collections::HashSet::from([1])"},
+		{"VecDeque<i32>", []interface{}{}, "std::collections::VecDeque::new()"},
+		{"", []interface{}{float64(1), 2.5}, "vec![1, 2.5]"},
+		{"", "s", `String::from("s")`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.rustType+"/"+tt.expected, func(t *testing.T) {
+			result, err := Rust(tt.rustType, tt.value)
+			if err != nil {
+				t.Fatalf("Rust(%q, %v) failed: %v", tt.rustType, tt.value, err)
+			}
+			if result != tt.expected {
+				t.Errorf("Rust(%q, %v) = %s, expected %s", tt.rustType, tt.value, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRustErrors(t *testing.T) {
+	tests := []struct {
+		rustType string
+		value    interface{}
+	}{
+		{"i32", 2.5},
+		{"u32", float64(-1)},
+		{"&str", float64(1)},
+		{"char", "ab"},
+		{"Vec<i32>", "not a list"},
+		{"[i32; 2]", []interface{}{float64(1)}},
+		{"(i32, i32)", []interface{}{float64(1)}},
+		{"HashMap<i32, i32>", map[string]interface{}{"x": float64(1)}},
+		{"()", float64(1)},
+		{"Rc<i32>", float64(1)},
+		{"", map[string]interface{}{"a": float64(1)}},
+	}
+
+	for _, tt := range tests {
+		if result, err := Rust(tt.rustType, tt.value); err == nil {
+			t.Errorf("Rust(%q, %v) = %s, expected an error", tt.rustType, tt.value, result)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package native
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/crisecheverria/codequest/internal/config"
+	"github.com/crisecheverria/codequest/internal/harness"
+)
+
+// CommandExecutor runs a program with the commands of a user-defined
+// executor from the configuration file.
+type CommandExecutor struct {
+	file    string
+	compile []string
+	run     []string
+}
+
+// RegisterConfig registers every executor declared in cfg.
+func RegisterConfig(cfg *config.Config) error {
+	for _, executor := range cfg.Executors {
+		lang, err := commandLanguage(executor)
+		if err != nil {
+			return fmt.Errorf("executor %s: %w", executor.Language, err)
+		}
+		if err := Register(lang); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func commandLanguage(cfg config.Executor) (Language, error) {
+	text, err := os.ReadFile(cfg.Harness)
+	if err != nil {
+		return Language{}, fmt.Errorf("failed to read harness template: %w", err)
+	}
+	generate, err := harness.FromTemplate(filepath.Base(cfg.Harness), string(text))
+	if err != nil {
+		return Language{}, err
+	}
+
+	name := cfg.Name
+	if name == "" {
+		name = cfg.Language
+	}
+
+	return Language{
+		ID:             cfg.Language,
+		Name:           name,
+		SolutionFile:   cfg.File,
+		BuildTimeLimit: cfg.BuildTimeLimit,
+		Generate:       generate,
+		NewExecutor: func(string) LanguageExecutor {
+			return &CommandExecutor{file: cfg.File, compile: cfg.Compile, run: cfg.Run}
+		},
+	}, nil
+}
+
+func (c *CommandExecutor) CheckAvailability() error {
+	for _, command := range [][]string{c.compile, c.run} {
+		if len(command) == 0 {
+			continue
+		}
+		if _, err := exec.LookPath(command[0]); err != nil {
+			return fmt.Errorf("%s not found on PATH", command[0])
+		}
+	}
+	return nil
+}
+
+func (c *CommandExecutor) Compile(ctx context.Context, execDir, code string) (*ExecutionResult, error) {
+	if err := os.WriteFile(filepath.Join(execDir, c.file), []byte(code), 0644); err != nil {
+		return nil, fmt.Errorf("failed to write %s: %w", c.file, err)
+	}
+
+	if len(c.compile) > 0 {
+		compile := expandCommand(c.compile, c.file, execDir)
+		compileCmd := exec.CommandContext(ctx, compile[0], compile[1:]...)
+		compileCmd.Dir = execDir
+		if output, err := compileCmd.CombinedOutput(); err != nil {
+			return buildFailed(ctx, output, fmt.Errorf("compilation failed: %w", err)), nil
+		}
+	}
+	return nil, nil
+}
+
+func (c *CommandExecutor) Run(ctx context.Context, execDir string, limits Limits) (*ExecutionResult, error) {
+	run := expandCommand(c.run, c.file, execDir)
+	return runLimited(ctx, execDir, limits, run[0], run[1:]...)
+}
+
+// expandCommand replaces {file} and {dir} in each argument.
+func expandCommand(args []string, file, dir string) []string {
+	replacer := strings.NewReplacer("{file}", file, "{dir}", dir)
+	expanded := make([]string, len(args))
+	for i, arg := range args {
+		expanded[i] = replacer.Replace(arg)
+	}
+	return expanded
+}
@@ -0,0 +1,118 @@
+package native
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/crisecheverria/codequest/internal/config"
+)
+
+func TestExpandCommand(t *testing.T) {
+	got := expandCommand([]string{"rustc", "{file}", "-o", "{dir}/solution"}, "main.rs", "/tmp/run")
+	want := []string{"rustc", "main.rs", "-o", "/tmp/run/solution"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expandCommand() = %v, want %v", got, want)
+	}
+}
+
+func TestRegisterConfig(t *testing.T) {
+	harnessPath := filepath.Join(t.TempDir(), "ruby.tmpl")
+	if err := os.WriteFile(harnessPath, []byte("{{.Solution}}"), 0644); err != nil {
+		t.Fatalf("Failed to write harness template: %v", err)
+	}
+
+	cfg := &config.Config{Executors: []config.Executor{{
+		Language: "ruby-test",
+		File:     "solution.rb",
+		Harness:  harnessPath,
+		Run:      []string{"ruby", "{file}"},
+	}}}
+	if err := RegisterConfig(cfg); err != nil {
+		t.Fatalf("RegisterConfig() failed: %v", err)
+	}
+
+	lang, ok := LookupLanguage("ruby-test")
+	if !ok {
+		t.Fatal("Expected ruby-test to be registered")
+	}
+	if lang.SolutionFile != "solution.rb" || lang.Name != "ruby-test" {
+		t.Errorf("Unexpected language: %+v", lang)
+	}
+	if _, ok := lang.NewExecutor(t.TempDir()).(*CommandExecutor); !ok {
+		t.Error("Expected a CommandExecutor")
+	}
+
+	cfg.Executors[0].Harness = filepath.Join(t.TempDir(), "missing.tmpl")
+	if err := RegisterConfig(cfg); err == nil {
+		t.Error("Expected an error for a missing harness template")
+	}
+}
+
+func TestCommandExecutor(t *testing.T) {
+	executor := &CommandExecutor{
+		file:    "solution.sh",
+		compile: []string{"sh", "-n", "{file}"},
+		run:     []string{"sh", "{file}"},
+	}
+	if err := executor.CheckAvailability(); err != nil {
+		t.Skipf("sh not available: %v", err)
+	}
+
+	result, err := execute(executor, t.TempDir(), "echo hello", time.Minute, time.Minute, DefaultLimits())
+	if err != nil {
+		t.Fatalf("execute() failed: %v", err)
+	}
+	if !result.Success || result.Output != "hello\n" {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+
+	result, err = execute(executor, t.TempDir(), "if then", time.Minute, time.Minute, DefaultLimits())
+	if err != nil {
+		t.Fatalf("execute() failed: %v", err)
+	}
+	if result.Status != StatusCompileError {
+		t.Errorf("Expected a compile error, got %+v", result)
+	}
+}
+
+func TestExecuteSeparatesCompileAndRunBudgets(t *testing.T) {
+	executor := &CommandExecutor{
+		file:    "solution.sh",
+		compile: []string{"sleep", "0.3"},
+		run:     []string{"sh", "{file}"},
+	}
+	if err := executor.CheckAvailability(); err != nil {
+		t.Skipf("sh or sleep not available: %v", err)
+	}
+
+	// Compiling takes longer than the run budget without using it up
+	result, err := execute(executor, t.TempDir(), "echo hello", time.Minute, 200*time.Millisecond, DefaultLimits())
+	if err != nil {
+		t.Fatalf("execute() failed: %v", err)
+	}
+	if !result.Success || result.CompileTime < 300*time.Millisecond || result.RunTime >= 200*time.Millisecond {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+	if result.Duration != result.CompileTime+result.RunTime {
+		t.Errorf("Expected Duration to be CompileTime plus RunTime, got %+v", result)
+	}
+
+	result, err = execute(executor, t.TempDir(), "sleep 10", time.Minute, 200*time.Millisecond, DefaultLimits())
+	if err != nil {
+		t.Fatalf("execute() failed: %v", err)
+	}
+	if result.Status != StatusTimeLimitExceeded {
+		t.Errorf("Expected %q, got %+v", StatusTimeLimitExceeded, result)
+	}
+
+	result, err = execute(executor, t.TempDir(), "echo hello", 100*time.Millisecond, time.Minute, DefaultLimits())
+	if err != nil {
+		t.Fatalf("execute() failed: %v", err)
+	}
+	if result.Status != StatusCompileError || result.RunTime != 0 {
+		t.Errorf("Expected a compile error without running, got %+v", result)
+	}
+}
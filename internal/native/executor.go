@@ -6,20 +6,35 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
-	"strings"
 	"time"
+
+	"github.com/crisecheverria/codequest/internal/harness"
 )
 
 type ExecutionResult struct {
-	Success  bool
-	Output   string
-	Error    string
+	Success bool
+	Output  string
+	Error   string
+	// Duration is CompileTime plus RunTime
 	Duration time.Duration
-	ExitCode int
+	// CompileTime is the time spent preparing and compiling the program
+	CompileTime time.Duration
+	// RunTime is the time the program ran, zero if it never started
+	RunTime time.Duration
+	// PeakMemory is the program's largest resident set size in bytes, or 0
+	// where it is not measured
+	PeakMemory int64
+	ExitCode   int
+	// Status tells a compile error or an exceeded limit apart from a
+	// program that ran and failed
+	Status Status
 }
 
+// Executor runs programs in a temporary working directory. It keeps each
+// language's executor, and the toolchain it found, between runs.
 type Executor struct {
-	workDir string
+	workDir   string
+	languages map[string]LanguageExecutor
 }
 
 func NewExecutor() (*Executor, error) {
@@ -29,56 +44,93 @@ func NewExecutor() (*Executor, error) {
 		return nil, fmt.Errorf("failed to create temp directory: %w", err)
 	}
 
-	return &Executor{workDir: workDir}, nil
+	return &Executor{workDir: workDir, languages: map[string]LanguageExecutor{}}, nil
 }
 
 func (e *Executor) Close() error {
 	return os.RemoveAll(e.workDir)
 }
 
-func (e *Executor) ExecuteCode(language, code string, timeLimit int) (*ExecutionResult, error) {
-	start := time.Now()
-
+// ExecuteCode compiles code within the language's build time limit, then
+// runs it within timeLimit milliseconds with the program itself held to
+// limits. Compilation never uses up the program's time limit.
+func (e *Executor) ExecuteCode(language, code string, timeLimit int, limits Limits) (*ExecutionResult, error) {
 	// Create language-specific executor
-	var executor LanguageExecutor
-	switch language {
-	case "go":
-		executor = &GoExecutor{workDir: e.workDir}
-	case "javascript", "typescript":
-		executor = &NodeExecutor{workDir: e.workDir}
-	case "python":
-		executor = &PythonExecutor{workDir: e.workDir}
-	default:
+	lang, ok := LookupLanguage(language)
+	if !ok {
 		return nil, fmt.Errorf("unsupported language: %s", language)
 	}
+	executor, ok := e.languages[language]
+	if !ok {
+		executor = lang.NewExecutor(e.workDir)
 
-	// Check if the language runtime is available
-	if err := executor.CheckAvailability(); err != nil {
-		return nil, fmt.Errorf("language runtime not available: %w", err)
+		// Check if the language runtime is available
+		if err := executor.CheckAvailability(); err != nil {
+			return nil, fmt.Errorf("language runtime not available: %w", err)
+		}
+		e.languages[language] = executor
+	}
+
+	// Create a unique subdirectory for this execution
+	execDir := filepath.Join(e.workDir, fmt.Sprintf("%s-%d", lang.ID, time.Now().UnixNano()))
+	if err := os.MkdirAll(execDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create execution directory: %w", err)
 	}
+	defer os.RemoveAll(execDir)
 
-	// Execute the code
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(timeLimit)*time.Millisecond)
-	defer cancel()
+	return execute(executor, execDir, code, lang.buildTimeout(), time.Duration(timeLimit)*time.Millisecond, limits)
+}
 
-	result, err := executor.Execute(ctx, code)
+// execute compiles code in dir within compileTimeout and runs it within
+// runTimeout, timing each phase.
+func execute(executor LanguageExecutor, dir, code string, compileTimeout, runTimeout time.Duration, limits Limits) (*ExecutionResult, error) {
+	start := time.Now()
+	compileCtx, cancel := context.WithTimeout(context.Background(), compileTimeout)
+	result, err := executor.Compile(compileCtx, dir, code)
+	cancel()
 	if err != nil {
 		return nil, err
 	}
+	compileTime := time.Since(start)
+
+	if result == nil {
+		start = time.Now()
+		runCtx, cancel := context.WithTimeout(context.Background(), runTimeout)
+		defer cancel()
+		if result, err = executor.Run(runCtx, dir, limits); err != nil {
+			return nil, err
+		}
+		result.RunTime = time.Since(start)
+	}
 
-	result.Duration = time.Since(start)
+	result.CompileTime = compileTime
+	result.Duration = result.CompileTime + result.RunTime
 	return result, nil
 }
 
-// LanguageExecutor interface for different language executors
+// LanguageExecutor runs programs in one language in two phases with separate
+// time budgets.
 type LanguageExecutor interface {
 	CheckAvailability() error
-	Execute(ctx context.Context, code string) (*ExecutionResult, error)
+	// Compile writes code to dir and compiles or checks it if the language
+	// needs to. It returns a result only when compilation failed.
+	Compile(ctx context.Context, dir, code string) (*ExecutionResult, error)
+	// Run executes the program that Compile prepared in dir.
+	Run(ctx context.Context, dir string, limits Limits) (*ExecutionResult, error)
 }
 
 // GoExecutor implements Go code execution
-type GoExecutor struct {
-	workDir string
+type GoExecutor struct{}
+
+func init() {
+	mustRegister(Language{
+		ID:             "go",
+		Name:           "Go",
+		SolutionFile:   "solution.go",
+		BuildTimeLimit: 15000,
+		Generate:       harness.GenerateGo,
+		NewExecutor:    func(string) LanguageExecutor { return &GoExecutor{} },
+	})
 }
 
 func (g *GoExecutor) CheckAvailability() error {
@@ -89,14 +141,7 @@ func (g *GoExecutor) CheckAvailability() error {
 	return nil
 }
 
-func (g *GoExecutor) Execute(ctx context.Context, code string) (*ExecutionResult, error) {
-	// Create a unique subdirectory for this execution
-	execDir := filepath.Join(g.workDir, fmt.Sprintf("go-%d", time.Now().UnixNano()))
-	if err := os.MkdirAll(execDir, 0755); err != nil {
-		return nil, fmt.Errorf("failed to create execution directory: %w", err)
-	}
-	defer os.RemoveAll(execDir)
-
+func (g *GoExecutor) Compile(ctx context.Context, execDir, code string) (*ExecutionResult, error) {
 	// Write code to main.go
 	mainFile := filepath.Join(execDir, "main.go")
 	if err := os.WriteFile(mainFile, []byte(code), 0644); err != nil {
@@ -110,37 +155,55 @@ func (g *GoExecutor) Execute(ctx context.Context, code string) (*ExecutionResult
 		return nil, fmt.Errorf("failed to initialize Go module: %w", err)
 	}
 
-	// Execute go run
-	cmd := exec.CommandContext(ctx, "go", "run", "main.go")
-	cmd.Dir = execDir
-
-	output, err := cmd.CombinedOutput()
-	outputStr := string(output)
-
-	result := &ExecutionResult{
-		Success:  err == nil,
-		Output:   outputStr,
-		ExitCode: 0,
-	}
-
-	if err != nil {
-		if exitError, ok := err.(*exec.ExitError); ok {
-			result.ExitCode = exitError.ExitCode()
-		} else {
-			result.ExitCode = 1
-		}
-		result.Error = err.Error()
+	// Compile once so a harness running many test cases pays the build cost a single time
+	buildCmd := exec.CommandContext(ctx, "go", "build", "-o", "solution", "main.go")
+	buildCmd.Dir = execDir
+	if output, err := buildCmd.CombinedOutput(); err != nil {
+		return buildFailed(ctx, output, fmt.Errorf("compilation failed: %w", err)), nil
 	}
+	return nil, nil
+}
 
-	return result, nil
+func (g *GoExecutor) Run(ctx context.Context, execDir string, limits Limits) (*ExecutionResult, error) {
+	// Execute the compiled binary
+	return runLimited(ctx, execDir, limits, filepath.Join(execDir, "solution"))
 }
 
-// NodeExecutor implements Node.js code execution
+// NodeExecutor implements Node.js code execution. TypeScript runs through
+// the first available TypeScript toolchain.
 type NodeExecutor struct {
-	workDir string
+	typeScript bool
+	toolchain  *typeScriptToolchain
+}
+
+func init() {
+	mustRegister(Language{
+		ID:           "javascript",
+		Name:         "JavaScript",
+		SolutionFile: "solution.js",
+		Generate:     harness.GenerateNode,
+		NewExecutor:  func(string) LanguageExecutor { return &NodeExecutor{} },
+	})
+	mustRegister(Language{
+		ID:             "typescript",
+		Name:           "TypeScript",
+		SolutionFile:   "solution.ts",
+		BuildTimeLimit: 10000,
+		Generate:       harness.GenerateNode,
+		NewExecutor:    func(string) LanguageExecutor { return &NodeExecutor{typeScript: true} },
+	})
 }
 
 func (n *NodeExecutor) CheckAvailability() error {
+	if n.typeScript {
+		toolchain, err := findTypeScriptToolchain()
+		if err != nil {
+			return err
+		}
+		n.toolchain = toolchain
+		return nil
+	}
+
 	_, err := exec.LookPath("node")
 	if err != nil {
 		return fmt.Errorf("Node.js runtime not found. Please install Node.js from https://nodejs.org/")
@@ -148,71 +211,44 @@ func (n *NodeExecutor) CheckAvailability() error {
 	return nil
 }
 
-func (n *NodeExecutor) Execute(ctx context.Context, code string) (*ExecutionResult, error) {
-	// Create a unique subdirectory for this execution
-	execDir := filepath.Join(n.workDir, fmt.Sprintf("node-%d", time.Now().UnixNano()))
-	if err := os.MkdirAll(execDir, 0755); err != nil {
-		return nil, fmt.Errorf("failed to create execution directory: %w", err)
-	}
-	defer os.RemoveAll(execDir)
-
-	// For TypeScript, we'll transpile to JavaScript (basic approach)
-	jsCode := code
-	if strings.Contains(code, ": number") || strings.Contains(code, ": string") || strings.Contains(code, ": boolean") {
-		jsCode = transpileTypeScript(code)
+func (n *NodeExecutor) Compile(ctx context.Context, execDir, code string) (*ExecutionResult, error) {
+	if n.typeScript {
+		if n.toolchain == nil {
+			if err := n.CheckAvailability(); err != nil {
+				return nil, err
+			}
+		}
+		return n.toolchain.build(ctx, execDir, code)
 	}
 
 	// Write code to solution.js
 	jsFile := filepath.Join(execDir, "solution.js")
-	if err := os.WriteFile(jsFile, []byte(jsCode), 0644); err != nil {
+	if err := os.WriteFile(jsFile, []byte(code), 0644); err != nil {
 		return nil, fmt.Errorf("failed to write JavaScript code: %w", err)
 	}
+	return nil, nil
+}
 
-	// Execute node
-	cmd := exec.CommandContext(ctx, "node", "solution.js")
-	cmd.Dir = execDir
-
-	output, err := cmd.CombinedOutput()
-	outputStr := string(output)
-
-	result := &ExecutionResult{
-		Success:  err == nil,
-		Output:   outputStr,
-		ExitCode: 0,
-	}
-
-	if err != nil {
-		if exitError, ok := err.(*exec.ExitError); ok {
-			result.ExitCode = exitError.ExitCode()
-		} else {
-			result.ExitCode = 1
-		}
-		result.Error = err.Error()
+func (n *NodeExecutor) Run(ctx context.Context, execDir string, limits Limits) (*ExecutionResult, error) {
+	if n.typeScript {
+		return n.toolchain.execute(ctx, execDir, limits)
 	}
 
-	return result, nil
-}
-
-// Basic TypeScript to JavaScript transpilation
-func transpileTypeScript(code string) string {
-	// Remove common TypeScript type annotations
-	jsCode := strings.ReplaceAll(code, ": number", "")
-	jsCode = strings.ReplaceAll(jsCode, ": string", "")
-	jsCode = strings.ReplaceAll(jsCode, ": boolean", "")
-	jsCode = strings.ReplaceAll(jsCode, ": void", "")
-	jsCode = strings.ReplaceAll(jsCode, ": any", "")
-	
-	// Remove array type annotations like number[], string[]
-	jsCode = strings.ReplaceAll(jsCode, ": number[]", "")
-	jsCode = strings.ReplaceAll(jsCode, ": string[]", "")
-	jsCode = strings.ReplaceAll(jsCode, ": boolean[]", "")
-	
-	return jsCode
+	// Execute node
+	return runLimited(ctx, execDir, limits, "node", "solution.js")
 }
 
 // PythonExecutor implements Python code execution
-type PythonExecutor struct {
-	workDir string
+type PythonExecutor struct{}
+
+func init() {
+	mustRegister(Language{
+		ID:           "python",
+		Name:         "Python",
+		SolutionFile: "solution.py",
+		Generate:     harness.GeneratePython,
+		NewExecutor:  func(string) LanguageExecutor { return &PythonExecutor{} },
+	})
 }
 
 func (p *PythonExecutor) CheckAvailability() error {
@@ -227,46 +263,64 @@ func (p *PythonExecutor) CheckAvailability() error {
 	return nil
 }
 
-func (p *PythonExecutor) Execute(ctx context.Context, code string) (*ExecutionResult, error) {
-	// Create a unique subdirectory for this execution
-	execDir := filepath.Join(p.workDir, fmt.Sprintf("python-%d", time.Now().UnixNano()))
-	if err := os.MkdirAll(execDir, 0755); err != nil {
-		return nil, fmt.Errorf("failed to create execution directory: %w", err)
-	}
-	defer os.RemoveAll(execDir)
-
+func (p *PythonExecutor) Compile(ctx context.Context, execDir, code string) (*ExecutionResult, error) {
 	// Write code to solution.py
 	pyFile := filepath.Join(execDir, "solution.py")
 	if err := os.WriteFile(pyFile, []byte(code), 0644); err != nil {
 		return nil, fmt.Errorf("failed to write Python code: %w", err)
 	}
+	return nil, nil
+}
 
+func (p *PythonExecutor) Run(ctx context.Context, execDir string, limits Limits) (*ExecutionResult, error) {
 	// Try python3 first, then python
-	var cmd *exec.Cmd
-	if _, err := exec.LookPath("python3"); err == nil {
-		cmd = exec.CommandContext(ctx, "python3", "solution.py")
-	} else {
-		cmd = exec.CommandContext(ctx, "python", "solution.py")
+	python := "python3"
+	if _, err := exec.LookPath("python3"); err != nil {
+		python = "python"
 	}
-	cmd.Dir = execDir
+	return runLimited(ctx, execDir, limits, python, "solution.py")
+}
+
+// PHPExecutor implements PHP code execution
+type PHPExecutor struct{}
+
+func init() {
+	mustRegister(Language{
+		ID:           "php",
+		Name:         "PHP",
+		SolutionFile: "solution.php",
+		Generate:     harness.GeneratePHP,
+		NewExecutor:  func(string) LanguageExecutor { return &PHPExecutor{} },
+	})
+}
 
-	output, err := cmd.CombinedOutput()
-	outputStr := string(output)
+func (p *PHPExecutor) CheckAvailability() error {
+	_, err := exec.LookPath("php")
+	if err != nil {
+		return fmt.Errorf("PHP runtime not found. Please install PHP from https://www.php.net/downloads")
+	}
+	return nil
+}
 
-	result := &ExecutionResult{
-		Success:  err == nil,
-		Output:   outputStr,
-		ExitCode: 0,
+func (p *PHPExecutor) Compile(ctx context.Context, execDir, code string) (*ExecutionResult, error) {
+	// Write code to solution.php
+	phpFile := filepath.Join(execDir, "solution.php")
+	if err := os.WriteFile(phpFile, []byte(code), 0644); err != nil {
+		return nil, fmt.Errorf("failed to write PHP code: %w", err)
 	}
 
-	if err != nil {
-		if exitError, ok := err.(*exec.ExitError); ok {
-			result.ExitCode = exitError.ExitCode()
-		} else {
-			result.ExitCode = 1
-		}
-		result.Error = err.Error()
+	// Lint first so syntax errors are reported as compile errors rather than
+	// a fatal error at runtime
+	lintCmd := exec.CommandContext(ctx, "php", "-l", "solution.php")
+	lintCmd.Dir = execDir
+	if output, err := lintCmd.CombinedOutput(); err != nil {
+		return buildFailed(ctx, output, fmt.Errorf("syntax check failed: %w", err)), nil
 	}
+	return nil, nil
+}
 
-	return result, nil
-}
\ No newline at end of file
+func (p *PHPExecutor) Run(ctx context.Context, execDir string, limits Limits) (*ExecutionResult, error) {
+	// Execute php with errors on stderr so they are never mistaken for
+	// harness output. Exit code 255 means PHP stopped on a fatal error.
+	return runLimited(ctx, execDir, limits, "php", "-d", "display_errors=stderr", "solution.php")
+}
@@ -0,0 +1,97 @@
+package native
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/crisecheverria/codequest/internal/harness"
+)
+
+// Language describes everything codequest needs to fetch, generate and run
+// challenges in one language. Languages are added with Register.
+type Language struct {
+	// ID is the value of Challenge.Language
+	ID string
+	// Name is the human readable name
+	Name string
+	// SolutionFile is the file created in a fetched workspace; its extension
+	// identifies the language's source files
+	SolutionFile string
+	// BuildTimeLimit is the time in milliseconds allowed for preparing and
+	// compiling or type checking a program, separate from the challenge's
+	// time limit for running it. Zero means defaultBuildTimeLimit.
+	BuildTimeLimit int
+	// Generate builds the test harness program
+	Generate harness.Generator
+	// NewExecutor returns the executor that runs the harness in workDir.
+	// Its CheckAvailability method detects the runtime.
+	NewExecutor func(workDir string) LanguageExecutor
+}
+
+// defaultBuildTimeLimit is the build time limit of languages that set none.
+const defaultBuildTimeLimit = 10000
+
+// buildTimeout returns the time allowed for compiling a program.
+func (l Language) buildTimeout() time.Duration {
+	limit := l.BuildTimeLimit
+	if limit <= 0 {
+		limit = defaultBuildTimeLimit
+	}
+	return time.Duration(limit) * time.Millisecond
+}
+
+var (
+	languagesMu sync.RWMutex
+	languages   = map[string]Language{}
+)
+
+// Register adds a language, replacing any language registered with the same
+// id so that configuration can override a built-in language.
+func Register(lang Language) error {
+	switch {
+	case lang.ID == "":
+		return fmt.Errorf("language id is required")
+	case lang.SolutionFile == "":
+		return fmt.Errorf("language %s has no solution file", lang.ID)
+	case lang.Generate == nil:
+		return fmt.Errorf("language %s has no harness generator", lang.ID)
+	case lang.NewExecutor == nil:
+		return fmt.Errorf("language %s has no executor", lang.ID)
+	}
+
+	languagesMu.Lock()
+	defer languagesMu.Unlock()
+	languages[lang.ID] = lang
+	return nil
+}
+
+func mustRegister(lang Language) {
+	if err := Register(lang); err != nil {
+		panic(err)
+	}
+}
+
+// LookupLanguage returns the language with the given id.
+func LookupLanguage(id string) (Language, bool) {
+	languagesMu.RLock()
+	defer languagesMu.RUnlock()
+	lang, ok := languages[id]
+	return lang, ok
+}
+
+// Languages returns every registered language sorted by id.
+func Languages() []Language {
+	languagesMu.RLock()
+	defer languagesMu.RUnlock()
+
+	sorted := make([]Language, 0, len(languages))
+	for _, lang := range languages {
+		sorted = append(sorted, lang)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].ID < sorted[j].ID
+	})
+	return sorted
+}
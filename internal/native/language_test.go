@@ -0,0 +1,84 @@
+package native
+
+import (
+	"testing"
+)
+
+func TestLookupLanguage(t *testing.T) {
+	tests := []struct {
+		id           string
+		solutionFile string
+	}{
+		{"go", "solution.go"},
+		{"javascript", "solution.js"},
+		{"typescript", "solution.ts"},
+		{"python", "solution.py"},
+		{"php", "solution.php"},
+		{"rust", "solution.rs"},
+	}
+
+	for _, tt := range tests {
+		lang, ok := LookupLanguage(tt.id)
+		if !ok {
+			t.Errorf("LookupLanguage(%q) found no language", tt.id)
+			continue
+		}
+		if lang.SolutionFile != tt.solutionFile {
+			t.Errorf("LookupLanguage(%q).SolutionFile = %q, want %q", tt.id, lang.SolutionFile, tt.solutionFile)
+		}
+		if lang.Generate == nil || lang.NewExecutor == nil {
+			t.Errorf("LookupLanguage(%q) has no harness generator or executor", tt.id)
+		}
+	}
+
+	if _, ok := LookupLanguage("cobol"); ok {
+		t.Error("Expected no language for cobol")
+	}
+}
+
+func TestLanguagesSorted(t *testing.T) {
+	all := Languages()
+	for i := 1; i < len(all); i++ {
+		if all[i-1].ID >= all[i].ID {
+			t.Errorf("Languages() not sorted: %q before %q", all[i-1].ID, all[i].ID)
+		}
+	}
+}
+
+func TestExecuteCodeUnsupportedLanguage(t *testing.T) {
+	executor, err := NewExecutor()
+	if err != nil {
+		t.Fatalf("NewExecutor() failed: %v", err)
+	}
+	defer executor.Close()
+
+	if _, err := executor.ExecuteCode("cobol", "", 1000, DefaultLimits()); err == nil {
+		t.Error("Expected an error for an unsupported language")
+	}
+}
+
+func TestRegister(t *testing.T) {
+	invalid := []Language{
+		{},
+		{ID: "ruby"},
+		{ID: "ruby", SolutionFile: "solution.rb"},
+	}
+	for _, lang := range invalid {
+		if err := Register(lang); err == nil {
+			t.Errorf("Register(%+v) should have failed", lang)
+		}
+	}
+
+	// Registering an existing id replaces the language
+	original, _ := LookupLanguage("python")
+	defer mustRegister(original)
+
+	override := original
+	override.Name = "Python (custom)"
+	if err := Register(override); err != nil {
+		t.Fatalf("Register() failed: %v", err)
+	}
+	if lang, _ := LookupLanguage("python"); lang.Name != "Python (custom)" {
+		t.Errorf("Expected the override to replace python, got %q", lang.Name)
+	}
+}
@@ -0,0 +1,288 @@
+package native
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/crisecheverria/codequest/internal/challenge"
+	"github.com/crisecheverria/codequest/internal/harness"
+)
+
+// Status classifies how an execution ended.
+type Status string
+
+const (
+	StatusOK                  Status = "ok"
+	StatusRuntimeError        Status = "runtime error"
+	StatusCompileError        Status = "compile error"
+	StatusTimeLimitExceeded   Status = "time limit exceeded"
+	StatusMemoryLimitExceeded Status = "memory limit exceeded"
+	StatusOutputLimitExceeded Status = "output limit exceeded"
+	// StatusStopped is a program stopped after a failed test case, see
+	// Limits.FailFast
+	StatusStopped Status = "stopped"
+)
+
+// Limits bounds the resources of an executed program. A zero field means no
+// limit. Only the program itself is limited, never the compiler that builds
+// it. Memory, CPU time, process and open file limits are enforced on Linux.
+type Limits struct {
+	// Memory is the maximum heap memory in bytes
+	Memory int64
+	// CPUTime is the maximum processor time, rounded up to whole seconds
+	CPUTime time.Duration
+	// Processes is the number of processes and threads the program may
+	// start on top of those the user already runs
+	Processes int
+	// OpenFiles is the maximum number of open file descriptors
+	OpenFiles int
+	// Output is the maximum number of bytes written to stdout and stderr
+	// combined, and the largest file the program may write
+	Output int64
+	// Sandbox runs the program without network access and with only its
+	// working directory writable. Check availability with CheckSandbox.
+	Sandbox bool
+	// FailFast asks the harness to exit after its first failed test case.
+	// A program still running failFastGrace after reporting one is killed.
+	FailFast bool
+}
+
+// failFastGrace is how long a program that reported a failed test case
+// under Limits.FailFast has to exit on its own.
+const failFastGrace = time.Second
+
+// DefaultLimits returns the limits applied to every program before the
+// challenge's own memory and time limits.
+func DefaultLimits() Limits {
+	return Limits{
+		Processes: 64,
+		OpenFiles: 256,
+		Output:    8 << 20,
+	}
+}
+
+// LimitsFor returns the limits for running a solution to ch.
+func LimitsFor(ch challenge.Challenge) Limits {
+	limits := DefaultLimits()
+	limits.Memory = int64(ch.MemoryLimit) << 20
+	limits.CPUTime = time.Duration(ch.TimeLimit) * time.Millisecond
+	return limits
+}
+
+// outOfMemoryMarkers are printed to stderr by the supported runtimes when an
+// allocation fails.
+var outOfMemoryMarkers = []string{
+	"out of memory",        // Go, Node.js and PHP
+	"MemoryError",          // Python
+	"memory allocation of", // Rust
+	"Allowed memory size",  // PHP's own memory_limit
+}
+
+// runLimited runs name with args in dir under limits and classifies how it
+// ended. Output beyond the limit is discarded and stops the program.
+func runLimited(ctx context.Context, dir string, limits Limits, name string, args ...string) (*ExecutionResult, error) {
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	cmd := exec.CommandContext(runCtx, name, args...)
+	cmd.Dir = dir
+	// Don't wait forever for children that outlive the program and keep
+	// its output open
+	cmd.WaitDelay = time.Second
+
+	output := &limitedBuffer{limit: limits.Output, exceeded: cancel}
+	if limits.FailFast {
+		// The harness stops by itself; killing it is only a backstop for
+		// one that doesn't, such as a template harness
+		var backstop *time.Timer
+		output.failed = func() { backstop = time.AfterFunc(failFastGrace, cancel) }
+		defer func() {
+			if backstop != nil {
+				backstop.Stop()
+			}
+		}()
+	}
+	// stderr is also kept on its own, as it's where runtimes report
+	// running out of memory
+	stderr := &limitedBuffer{limit: limits.Output, exceeded: cancel}
+	cmd.Stdout = output
+	cmd.Stderr = io.MultiWriter(output, stderr)
+
+	if cmd.Err == nil {
+		if path, err := filepath.Abs(cmd.Path); err == nil {
+			cmd.Path = path
+		}
+		cleanup, err := limitCommand(cmd, limits)
+		if err != nil {
+			return nil, fmt.Errorf("failed to apply resource limits: %w", err)
+		}
+		defer cleanup()
+	}
+
+	err := cmd.Run()
+	result := &ExecutionResult{
+		Success:  err == nil,
+		Output:   output.String(),
+		Status:   StatusOK,
+		ExitCode: 0,
+	}
+	if cmd.ProcessState != nil {
+		result.PeakMemory = peakMemory(cmd.ProcessState)
+	}
+
+	if err != nil {
+		var exitError *exec.ExitError
+		if errors.As(err, &exitError) {
+			result.ExitCode = exitError.ExitCode()
+		} else {
+			result.ExitCode = 1
+		}
+		result.Error = err.Error()
+		result.Status = failureStatus(ctx, cmd, output, stderr.String(), result, limits)
+	}
+
+	return result, nil
+}
+
+// failureStatus explains why a program that ran under limits failed, given
+// what it wrote to stderr.
+func failureStatus(ctx context.Context, cmd *exec.Cmd, output *limitedBuffer, stderr string, result *ExecutionResult, limits Limits) Status {
+	if output.overflowed() {
+		return StatusOutputLimitExceeded
+	}
+	if output.stoppedAtFailure() {
+		return StatusStopped
+	}
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return StatusTimeLimitExceeded
+	}
+	if state := cmd.ProcessState; state != nil {
+		if status := limitSignalStatus(state); status != "" {
+			return status
+		}
+		if limits.CPUTime > 0 && state.UserTime()+state.SystemTime() >= limits.CPUTime {
+			return StatusTimeLimitExceeded
+		}
+	}
+	if limits.Memory > 0 && result.PeakMemory >= limits.Memory {
+		return StatusMemoryLimitExceeded
+	}
+	// Only what the runtime reported counts, not test case results such
+	// as an expected MemoryError
+	_, stderr = harness.ParseResults(stderr)
+	for _, marker := range outOfMemoryMarkers {
+		if strings.Contains(stderr, marker) {
+			return StatusMemoryLimitExceeded
+		}
+	}
+	return StatusRuntimeError
+}
+
+// buildFailed returns the result of a compile or type check step that failed
+// with err. A step cut short by the deadline ran out of build time, which
+// is not the program's time limit.
+func buildFailed(ctx context.Context, output []byte, err error) *ExecutionResult {
+	result := &ExecutionResult{
+		Success:  false,
+		Output:   string(output),
+		Error:    err.Error(),
+		Status:   StatusCompileError,
+		ExitCode: 1,
+	}
+	var exitError *exec.ExitError
+	if errors.As(err, &exitError) {
+		result.ExitCode = exitError.ExitCode()
+	}
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		result.Error = fmt.Sprintf("compilation did not finish within the build time limit: %v", err)
+		if strings.TrimSpace(result.Output) == "" {
+			result.Output = "Compilation did not finish within the build time limit"
+		}
+	}
+	return result
+}
+
+// limitedBuffer collects output up to limit bytes and calls exceeded once
+// when more is written. If failed is set, it is called once when a line
+// reports a failed test case.
+type limitedBuffer struct {
+	mu       sync.Mutex
+	buf      bytes.Buffer
+	limit    int64
+	exceeded func()
+	over     bool
+	failed   func()
+	stopped  bool
+	// line is the start of a line not yet ended, checked for a failed
+	// test case once it is
+	line []byte
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.failed != nil && !b.stopped {
+		b.checkFailure(p)
+	}
+
+	if b.limit > 0 {
+		if room := b.limit - int64(b.buf.Len()); int64(len(p)) > room {
+			b.buf.Write(p[:max(room, 0)])
+			if !b.over {
+				b.over = true
+				b.exceeded()
+			}
+			// Report success so the copy keeps draining the pipe until
+			// the program is killed
+			return len(p), nil
+		}
+	}
+	return b.buf.Write(p)
+}
+
+func (b *limitedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func (b *limitedBuffer) overflowed() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.over
+}
+
+// checkFailure calls failed if p ends a line reporting a failed test case.
+func (b *limitedBuffer) checkFailure(p []byte) {
+	for len(p) > 0 {
+		end := bytes.IndexByte(p, '\n')
+		if end < 0 {
+			b.line = append(b.line, p...)
+			return
+		}
+		line := append(b.line, p[:end]...)
+		b.line, p = b.line[:0], p[end+1:]
+
+		results, _ := harness.ParseResults(string(line))
+		if len(results) == 1 && !results[0].Passed {
+			b.stopped = true
+			b.failed()
+			return
+		}
+	}
+}
+
+func (b *limitedBuffer) stoppedAtFailure() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.stopped
+}
@@ -0,0 +1,232 @@
+package native
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// childEnv passes a childSpec to the re-executed codequest binary, which
+// applies it to itself and then executes the program. Go cannot set the
+// limits of a child between fork and exec, and limits set later would race
+// with the program.
+const childEnv = "CODEQUEST_CHILD"
+
+// rlimitNproc is RLIMIT_NPROC, which the syscall package does not define.
+// MIPS numbers it differently.
+const rlimitNproc = 0x6
+
+// childSpec is the setup applied to a program before it starts.
+type childSpec struct {
+	Rlimits []rlimit     `json:"rlimits,omitempty"`
+	Sandbox *sandboxSpec `json:"sandbox,omitempty"`
+}
+
+type rlimit struct {
+	Resource int    `json:"resource"`
+	Soft     uint64 `json:"soft"`
+	Hard     uint64 `json:"hard"`
+}
+
+func init() {
+	if spec, ok := os.LookupEnv(childEnv); ok {
+		execChild(spec)
+	}
+}
+
+// execChild applies spec and replaces the process with the program in
+// os.Args[1:], whose first element is the program's path. It never returns.
+func execChild(data string) {
+	os.Unsetenv(childEnv)
+
+	// The sandbox's seccomp filter and no_new_privs only apply to the
+	// calling thread, which must be the one that executes the program
+	runtime.LockOSThread()
+
+	var spec childSpec
+	if err := json.Unmarshal([]byte(data), &spec); err != nil {
+		childFailed("invalid child setup", err)
+	}
+	if spec.Sandbox != nil {
+		if err := spec.Sandbox.enter(); err != nil {
+			childFailed("failed to enter the sandbox", err)
+		}
+	}
+	for _, limit := range spec.Rlimits {
+		if err := syscall.Setrlimit(limit.Resource, &syscall.Rlimit{Cur: limit.Soft, Max: limit.Hard}); err != nil {
+			childFailed(fmt.Sprintf("failed to set resource limit %d", limit.Resource), err)
+		}
+	}
+	if spec.Sandbox != nil {
+		if err := installSeccompFilter(); err != nil {
+			childFailed("failed to install the seccomp filter", err)
+		}
+		if spec.Sandbox.Probe {
+			os.Exit(0)
+		}
+	}
+
+	if len(os.Args) < 3 {
+		childFailed("no program to execute", nil)
+	}
+	err := syscall.Exec(os.Args[1], os.Args[2:], os.Environ())
+	fmt.Fprintf(os.Stderr, "codequest: failed to execute %s: %v\n", os.Args[1], err)
+	os.Exit(127)
+}
+
+func childFailed(message string, err error) {
+	if err != nil {
+		message = fmt.Sprintf("%s: %v", message, err)
+	}
+	fmt.Fprintf(os.Stderr, "codequest: %s\n", message)
+	os.Exit(126)
+}
+
+// limitCommand rewrites cmd to apply limits and, if requested, run in the
+// sandbox. The returned function removes what the sandbox needed once cmd
+// has finished.
+func limitCommand(cmd *exec.Cmd, limits Limits) (func(), error) {
+	spec := childSpec{Rlimits: rlimitsFor(limits)}
+	cleanup := func() {}
+	if limits.Sandbox {
+		sandbox, err := newSandboxSpec(cmd.Dir)
+		if err != nil {
+			return cleanup, err
+		}
+		spec.Sandbox = sandbox
+		cleanup = func() { os.Remove(sandbox.Root) }
+	}
+	if len(spec.Rlimits) == 0 && spec.Sandbox == nil {
+		return cleanup, nil
+	}
+
+	if err := startAsChild(cmd, spec); err != nil {
+		cleanup()
+		return func() {}, err
+	}
+	return cleanup, nil
+}
+
+// startAsChild rewrites cmd to start through the codequest binary, which
+// applies spec before executing the program.
+func startAsChild(cmd *exec.Cmd, spec childSpec) error {
+	self, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(spec)
+	if err != nil {
+		return err
+	}
+
+	env := cmd.Env
+	if env == nil {
+		env = os.Environ()
+	}
+	if spec.Sandbox != nil {
+		cmd.SysProcAttr = sandboxProcAttr()
+		// Temporary and cache files can only go to the scratch directory
+		env = append(env, "TMPDIR="+spec.Sandbox.Scratch, "XDG_CACHE_HOME="+spec.Sandbox.Scratch)
+	}
+
+	cmd.Env = append(env, childEnv+"="+string(data))
+	cmd.Args = append([]string{self, cmd.Path}, cmd.Args...)
+	cmd.Path = self
+	return nil
+}
+
+func rlimitsFor(limits Limits) []rlimit {
+	var rlimits []rlimit
+	add := func(resource int, soft, hard uint64) {
+		rlimits = append(rlimits, rlimit{Resource: resource, Soft: soft, Hard: hard})
+	}
+
+	// RLIMIT_DATA rather than RLIMIT_AS, since V8 and the Go runtime
+	// reserve far more address space than they use
+	if limits.Memory > 0 {
+		add(syscall.RLIMIT_DATA, uint64(limits.Memory), uint64(limits.Memory))
+	}
+	if limits.CPUTime > 0 {
+		// SIGXCPU at the soft limit, SIGKILL a second later
+		seconds := uint64((limits.CPUTime + 999999999) / 1000000000)
+		add(syscall.RLIMIT_CPU, seconds, seconds+1)
+	}
+	if limits.Processes > 0 && !strings.HasPrefix(runtime.GOARCH, "mips") {
+		// RLIMIT_NPROC counts every thread the user runs, not just the
+		// program's
+		n := uint64(userThreads() + limits.Processes)
+		add(rlimitNproc, n, n)
+	}
+	if limits.OpenFiles > 0 {
+		add(syscall.RLIMIT_NOFILE, uint64(limits.OpenFiles), uint64(limits.OpenFiles))
+	}
+	if limits.Output > 0 {
+		add(syscall.RLIMIT_FSIZE, uint64(limits.Output), uint64(limits.Output))
+	}
+	return rlimits
+}
+
+// userThreads counts the threads of every process owned by the current
+// user.
+func userThreads() int {
+	paths, _ := filepath.Glob("/proc/[0-9]*/status")
+	uid := strconv.Itoa(os.Getuid())
+
+	total := 0
+	for _, path := range paths {
+		file, err := os.Open(path)
+		if err != nil {
+			continue
+		}
+		owned, threads := false, 0
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			key, value, _ := strings.Cut(scanner.Text(), ":")
+			fields := strings.Fields(value)
+			switch {
+			case key == "Uid" && len(fields) > 0:
+				owned = fields[0] == uid
+			case key == "Threads" && len(fields) > 0:
+				threads, _ = strconv.Atoi(fields[0])
+			}
+		}
+		file.Close()
+		if owned {
+			total += threads
+		}
+	}
+	return total
+}
+
+// limitSignalStatus reports the limit a program exceeded if it was killed
+// for exceeding a resource limit.
+func limitSignalStatus(state *os.ProcessState) Status {
+	status, ok := state.Sys().(syscall.WaitStatus)
+	if !ok || !status.Signaled() {
+		return ""
+	}
+	switch status.Signal() {
+	case syscall.SIGXCPU:
+		return StatusTimeLimitExceeded
+	case syscall.SIGXFSZ:
+		return StatusOutputLimitExceeded
+	}
+	return ""
+}
+
+// peakMemory returns the largest resident set size of the program in bytes.
+func peakMemory(state *os.ProcessState) int64 {
+	usage, ok := state.SysUsage().(*syscall.Rusage)
+	if !ok {
+		return 0
+	}
+	// Linux reports kilobytes
+	return int64(usage.Maxrss) * 1024
+}
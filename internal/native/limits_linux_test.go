@@ -0,0 +1,63 @@
+package native
+
+import (
+	"context"
+	"os/exec"
+	"testing"
+	"time"
+)
+
+func TestRunLimitedMemoryLimit(t *testing.T) {
+	if _, err := exec.LookPath("python3"); err != nil {
+		t.Skip("python3 not available")
+	}
+
+	limits := DefaultLimits()
+	limits.Memory = 64 << 20
+	result, err := runLimited(context.Background(), t.TempDir(), limits, "python3", "-c", "x = bytearray(256 << 20)")
+	if err != nil {
+		t.Fatalf("runLimited() failed: %v", err)
+	}
+	if result.Status != StatusMemoryLimitExceeded {
+		t.Errorf("Expected %q, got %q: %s", StatusMemoryLimitExceeded, result.Status, result.Output)
+	}
+
+	result, err = runLimited(context.Background(), t.TempDir(), limits, "python3", "-c", "x = bytearray(16 << 20)")
+	if err != nil {
+		t.Fatalf("runLimited() failed: %v", err)
+	}
+	if result.Status != StatusOK {
+		t.Errorf("Expected %q, got %q: %s", StatusOK, result.Status, result.Output)
+	}
+	if result.PeakMemory < 16<<20 || result.PeakMemory > limits.Memory {
+		t.Errorf("Expected peak memory between 16 MB and the limit, got %d", result.PeakMemory)
+	}
+}
+
+func TestRunLimitedCPUTime(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+
+	limits := DefaultLimits()
+	limits.CPUTime = time.Second
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	result, err := runLimited(ctx, t.TempDir(), limits, "sh", "-c", "while :; do :; done")
+	if err != nil {
+		t.Fatalf("runLimited() failed: %v", err)
+	}
+	if result.Status != StatusTimeLimitExceeded || ctx.Err() != nil {
+		t.Errorf("Expected the CPU limit to stop the program, got %q", result.Status)
+	}
+}
+
+func TestRlimitsFor(t *testing.T) {
+	rlimits := rlimitsFor(Limits{CPUTime: 1500 * time.Millisecond})
+	if len(rlimits) != 1 || rlimits[0].Soft != 2 || rlimits[0].Hard != 3 {
+		t.Errorf("Expected CPU time rounded up to 2 seconds, got %+v", rlimits)
+	}
+	if rlimits := rlimitsFor(Limits{}); len(rlimits) != 0 {
+		t.Errorf("Expected no limits, got %+v", rlimits)
+	}
+}
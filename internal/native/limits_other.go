@@ -0,0 +1,28 @@
+//go:build !linux
+
+package native
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+)
+
+// Resource limits other than the output limit, and the sandbox, are only
+// available on Linux.
+func limitCommand(cmd *exec.Cmd, limits Limits) (func(), error) {
+	return func() {}, nil
+}
+
+// CheckSandbox reports whether programs can run sandboxed.
+func CheckSandbox() error {
+	return errors.New("sandboxing requires Linux")
+}
+
+func limitSignalStatus(state *os.ProcessState) Status {
+	return ""
+}
+
+func peakMemory(state *os.ProcessState) int64 {
+	return 0
+}
@@ -0,0 +1,142 @@
+package native
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/crisecheverria/codequest/internal/challenge"
+	"github.com/crisecheverria/codequest/internal/harness"
+)
+
+func TestLimitsFor(t *testing.T) {
+	limits := LimitsFor(challenge.Challenge{TimeLimit: 5000, MemoryLimit: 128})
+	if limits.Memory != 128<<20 || limits.CPUTime != 5*time.Second {
+		t.Errorf("Unexpected limits: %+v", limits)
+	}
+	if limits.Output != DefaultLimits().Output {
+		t.Errorf("Expected the default output limit, got %d", limits.Output)
+	}
+}
+
+func TestRunLimitedOutputLimit(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+
+	limits := DefaultLimits()
+	limits.Output = 1024
+	result, err := runLimited(context.Background(), t.TempDir(), limits, "sh", "-c", "while :; do echo flood; done")
+	if err != nil {
+		t.Fatalf("runLimited() failed: %v", err)
+	}
+	if result.Status != StatusOutputLimitExceeded || result.Success {
+		t.Errorf("Expected %q, got %+v", StatusOutputLimitExceeded, result.Status)
+	}
+	if len(result.Output) != 1024 {
+		t.Errorf("Expected output truncated to 1024 bytes, got %d", len(result.Output))
+	}
+}
+
+func TestRunLimitedTimeLimit(t *testing.T) {
+	if _, err := exec.LookPath("sleep"); err != nil {
+		t.Skip("sleep not available")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	result, err := runLimited(ctx, t.TempDir(), DefaultLimits(), "sleep", "10")
+	if err != nil {
+		t.Fatalf("runLimited() failed: %v", err)
+	}
+	if result.Status != StatusTimeLimitExceeded {
+		t.Errorf("Expected %q, got %q", StatusTimeLimitExceeded, result.Status)
+	}
+}
+
+func TestRunLimitedRuntimeError(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+
+	result, err := runLimited(context.Background(), t.TempDir(), DefaultLimits(), "sh", "-c", "echo broken; exit 3")
+	if err != nil {
+		t.Fatalf("runLimited() failed: %v", err)
+	}
+	if result.Status != StatusRuntimeError || result.ExitCode != 3 || result.Output != "broken\n" {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+}
+
+func TestRunLimitedOutOfMemoryMarkers(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+
+	tests := []struct {
+		name   string
+		script string
+		status Status
+	}{
+		{"stderr", "echo 'MemoryError' >&2; exit 1", StatusMemoryLimitExceeded},
+		{"stdout", "echo 'caught MemoryError'; exit 1", StatusRuntimeError},
+		{"result", fmt.Sprintf(`printf '%%s{"index":0,"passed":false,"error":"MemoryError"}\n' %q >&2; exit 1`, harness.ResultPrefix), StatusRuntimeError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := runLimited(context.Background(), t.TempDir(), DefaultLimits(), "sh", "-c", tt.script)
+			if err != nil {
+				t.Fatalf("runLimited() failed: %v", err)
+			}
+			if result.Status != tt.status {
+				t.Errorf("Expected status %q, got %q", tt.status, result.Status)
+			}
+		})
+	}
+}
+
+func TestRunLimitedFailFast(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+
+	// The failed case is written in two parts, as a pipe may deliver it
+	script := fmt.Sprintf(`printf '%%s{"index":0,"passed":true}\n' %[1]q
+printf '%%s{"index":1,' %[1]q; sleep 0.1; printf '"passed":false}\n'
+sleep 10
+printf '%%s{"index":2,"passed":true}\n' %[1]q`, harness.ResultPrefix)
+
+	limits := DefaultLimits()
+	limits.FailFast = true
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	result, err := runLimited(ctx, t.TempDir(), limits, "sh", "-c", script)
+	if err != nil {
+		t.Fatalf("runLimited() failed: %v", err)
+	}
+	if result.Status != StatusStopped || result.Success || ctx.Err() != nil {
+		t.Errorf("Expected the program stopped at the failed case, got %q", result.Status)
+	}
+	if results, _ := harness.ParseResults(result.Output); len(results) != 2 {
+		t.Errorf("Expected the results up to the failed case, got %q", result.Output)
+	}
+}
+
+func TestBuildFailed(t *testing.T) {
+	err := exec.Command("sh", "-c", "exit 2").Run()
+	result := buildFailed(context.Background(), []byte("syntax error"), err)
+	if result.Status != StatusCompileError || result.ExitCode != 2 || result.Output != "syntax error" {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 0)
+	defer cancel()
+	<-ctx.Done()
+	result = buildFailed(ctx, nil, err)
+	if result.Status != StatusCompileError || !strings.Contains(result.Output, "build time limit") {
+		t.Errorf("Expected a build cut short by the deadline to report the build time limit, got %+v", result)
+	}
+}
@@ -0,0 +1,165 @@
+package native
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/crisecheverria/codequest/internal/harness"
+)
+
+// rustcFlags builds an optimized binary that still panics on integer
+// overflow, as a debug build would.
+var rustcFlags = []string{"--edition", "2021", "-O", "-C", "overflow-checks=on"}
+
+// rustCacheSize is how many binaries the cache keeps. The least recently
+// used ones are removed after each build.
+const rustCacheSize = 32
+
+// rustPartialMaxAge is how old a partially written binary left behind by an
+// interrupted build must be before it is removed.
+const rustPartialMaxAge = time.Hour
+
+// RustExecutor compiles Rust with rustc and runs the binary. Binaries are
+// cached by a hash of the program, compiler version and flags, so running
+// an unchanged solution again skips compilation. The cache keeps the
+// rustCacheSize most recently used binaries.
+type RustExecutor struct {
+	cacheDir string
+	// binary is the program built by the last Compile
+	binary string
+}
+
+func init() {
+	mustRegister(Language{
+		ID:             "rust",
+		Name:           "Rust",
+		SolutionFile:   "solution.rs",
+		BuildTimeLimit: 15000,
+		Generate:       harness.GenerateRust,
+		NewExecutor: func(workDir string) LanguageExecutor {
+			return &RustExecutor{cacheDir: rustCacheDir(workDir)}
+		},
+	})
+}
+
+// rustCacheDir returns the directory for cached binaries, falling back to
+// the executor's temporary directory when there is no user cache directory.
+func rustCacheDir(workDir string) string {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return filepath.Join(workDir, "rust-cache")
+	}
+	return filepath.Join(cacheDir, "codequest", "rust")
+}
+
+func (r *RustExecutor) CheckAvailability() error {
+	_, err := exec.LookPath("rustc")
+	if err != nil {
+		return fmt.Errorf("Rust compiler not found. Please install Rust from https://rustup.rs/")
+	}
+	return nil
+}
+
+func (r *RustExecutor) Compile(ctx context.Context, execDir, code string) (*ExecutionResult, error) {
+	version, err := exec.CommandContext(ctx, "rustc", "--version").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rustc version: %w", err)
+	}
+	hash := sha256.Sum256([]byte(string(version) + "\x00" + strings.Join(rustcFlags, " ") + "\x00" + code))
+	binary := filepath.Join(r.cacheDir, hex.EncodeToString(hash[:]))
+
+	if _, err := os.Stat(binary); err != nil {
+		if result, err := r.build(ctx, execDir, binary, code); err != nil || result != nil {
+			return result, err
+		}
+		pruneCache(r.cacheDir, rustCacheSize, time.Now())
+	} else {
+		// The modification time records when a binary was last used
+		now := time.Now()
+		os.Chtimes(binary, now, now)
+	}
+	r.binary = binary
+	return nil, nil
+}
+
+func (r *RustExecutor) Run(ctx context.Context, execDir string, limits Limits) (*ExecutionResult, error) {
+	// Execute the compiled binary
+	return runLimited(ctx, execDir, limits, r.binary)
+}
+
+// build compiles code into binary. It returns a result only when
+// compilation failed.
+func (r *RustExecutor) build(ctx context.Context, execDir, binary, code string) (*ExecutionResult, error) {
+	mainFile := filepath.Join(execDir, "main.rs")
+	if err := os.WriteFile(mainFile, []byte(code), 0644); err != nil {
+		return nil, fmt.Errorf("failed to write Rust code: %w", err)
+	}
+	if err := os.MkdirAll(r.cacheDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create Rust cache directory: %w", err)
+	}
+
+	// Build next to the cache entry and rename it into place so that a
+	// concurrent run never sees a partially written binary
+	partial := fmt.Sprintf("%s.%d.tmp", binary, time.Now().UnixNano())
+	args := append(append([]string{}, rustcFlags...), "-o", partial, "main.rs")
+	buildCmd := exec.CommandContext(ctx, "rustc", args...)
+	buildCmd.Dir = execDir
+	if output, err := buildCmd.CombinedOutput(); err != nil {
+		os.Remove(partial)
+		return buildFailed(ctx, output, fmt.Errorf("compilation failed: %w", err)), nil
+	}
+
+	if err := os.Rename(partial, binary); err != nil {
+		os.Remove(partial)
+		return nil, fmt.Errorf("failed to cache Rust binary: %w", err)
+	}
+	return nil, nil
+}
+
+// pruneCache removes all but the keep most recently modified binaries in
+// dir, and partially written ones older than rustPartialMaxAge. Errors are
+// ignored, as a binary that can't be removed only takes up space.
+func pruneCache(dir string, keep int, now time.Time) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+
+	type cached struct {
+		path    string
+		modTime time.Time
+	}
+	var binaries []cached
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		if strings.HasSuffix(entry.Name(), ".tmp") {
+			if now.Sub(info.ModTime()) > rustPartialMaxAge {
+				os.Remove(path)
+			}
+			continue
+		}
+		binaries = append(binaries, cached{path, info.ModTime()})
+	}
+
+	if len(binaries) <= keep {
+		return
+	}
+	sort.Slice(binaries, func(i, j int) bool {
+		return binaries[i].modTime.After(binaries[j].modTime)
+	})
+	for _, binary := range binaries[keep:] {
+		os.Remove(binary.path)
+	}
+}
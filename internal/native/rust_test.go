@@ -0,0 +1,81 @@
+package native
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestRustExecutor(t *testing.T) {
+	executor := &RustExecutor{cacheDir: t.TempDir()}
+	if err := executor.CheckAvailability(); err != nil {
+		t.Skipf("rustc not available: %v", err)
+	}
+
+	code := "fn main() { println!(\"hello\"); }"
+	result, err := execute(executor, t.TempDir(), code, time.Minute, time.Minute, DefaultLimits())
+	if err != nil {
+		t.Fatalf("execute() failed: %v", err)
+	}
+	if !result.Success || result.Output != "hello\n" {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+
+	// The second run reuses the cached binary
+	entries, err := os.ReadDir(executor.cacheDir)
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("Expected one cached binary, got %v (%v)", entries, err)
+	}
+	if _, err := execute(executor, t.TempDir(), code, time.Minute, time.Minute, DefaultLimits()); err != nil {
+		t.Fatalf("execute() failed: %v", err)
+	}
+	if entries, _ := os.ReadDir(executor.cacheDir); len(entries) != 1 {
+		t.Errorf("Expected the cached binary to be reused, got %d entries", len(entries))
+	}
+
+	result, err = execute(executor, t.TempDir(), "fn main() { let x: i32 = \"no\"; }", time.Minute, time.Minute, DefaultLimits())
+	if err != nil {
+		t.Fatalf("execute() failed: %v", err)
+	}
+	if result.Status != StatusCompileError {
+		t.Errorf("Expected a compile error, got %+v", result)
+	}
+}
+
+func TestPruneCache(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+	files := map[string]time.Duration{
+		"a":       3 * time.Minute,
+		"b":       time.Minute,
+		"c":       2 * time.Minute,
+		"d":       4 * time.Minute,
+		"e.1.tmp": 2 * rustPartialMaxAge,
+		"f.2.tmp": time.Minute,
+	}
+	for name, age := range files {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, nil, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, now.Add(-age), now.Add(-age)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	pruneCache(dir, 2, now)
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	if want := []string{"b", "c", "f.2.tmp"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("Expected %v to remain, got %v", want, names)
+	}
+}
@@ -0,0 +1,337 @@
+package native
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"unsafe"
+)
+
+// sandboxSpec describes the filesystem of a sandboxed program. The program
+// runs in new user, mount, pid, network, IPC and UTS namespaces, sees the
+// host's root read-only with only Scratch writable, and runs under a seccomp
+// filter.
+type sandboxSpec struct {
+	// Root is an empty directory the new root is assembled on
+	Root string `json:"root"`
+	// Scratch is the only writable directory and the working directory
+	Scratch string `json:"scratch"`
+	// Probe sets the sandbox up and exits instead of executing a program
+	Probe bool `json:"probe,omitempty"`
+}
+
+const (
+	prSetNoNewPrivs      = 38
+	prCapAmbient         = 47
+	prCapAmbientClearAll = 4
+	secbitNoRoot         = 1 << 0
+	secbitNoRootLocked   = 1 << 1
+	seccompModeFilter    = 2
+	seccompRetKill       = 0x80000000
+	seccompRetErrno      = 0x00050000
+	seccompRetAllow      = 0x7fff0000
+
+	// cloneNamespaces are the clone flags that create namespaces
+	cloneNamespaces = syscall.CLONE_NEWNS | 0x02000000 | syscall.CLONE_NEWUTS |
+		syscall.CLONE_NEWIPC | syscall.CLONE_NEWUSER | syscall.CLONE_NEWPID | syscall.CLONE_NEWNET
+)
+
+// newSandboxSpec creates the root mount point next to scratch.
+func newSandboxSpec(scratch string) (*sandboxSpec, error) {
+	scratch, err := filepath.Abs(scratch)
+	if err != nil {
+		return nil, err
+	}
+	root, err := os.MkdirTemp(filepath.Dir(scratch), "sandbox-root-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create sandbox root: %w", err)
+	}
+	return &sandboxSpec{Root: root, Scratch: scratch}, nil
+}
+
+// sandboxProcAttr starts the process in new namespaces, mapping the user to
+// itself so that files keep their owners.
+func sandboxProcAttr() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{
+		Cloneflags: syscall.CLONE_NEWUSER | syscall.CLONE_NEWNS | syscall.CLONE_NEWPID |
+			syscall.CLONE_NEWNET | syscall.CLONE_NEWIPC | syscall.CLONE_NEWUTS,
+		UidMappings: []syscall.SysProcIDMap{{ContainerID: os.Getuid(), HostID: os.Getuid(), Size: 1}},
+		GidMappings: []syscall.SysProcIDMap{{ContainerID: os.Getgid(), HostID: os.Getgid(), Size: 1}},
+		Pdeathsig:   syscall.SIGKILL,
+	}
+}
+
+var (
+	sandboxOnce sync.Once
+	sandboxErr  error
+)
+
+// CheckSandbox reports whether programs can run sandboxed, by setting up a
+// sandbox once. It fails where unprivileged user namespaces are disabled.
+func CheckSandbox() error {
+	sandboxOnce.Do(func() {
+		sandboxErr = probeSandbox()
+	})
+	return sandboxErr
+}
+
+func probeSandbox() error {
+	if deniedSyscalls == nil {
+		return fmt.Errorf("no seccomp filter for %s", runtime.GOARCH)
+	}
+
+	scratch, err := os.MkdirTemp("", "codequest-sandbox-*")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(scratch)
+
+	sandbox, err := newSandboxSpec(scratch)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(sandbox.Root)
+	sandbox.Probe = true
+
+	// The probe exits before executing a program
+	cmd := exec.Command("true")
+	cmd.Dir = scratch
+	if err := startAsChild(cmd, childSpec{Sandbox: sandbox}); err != nil {
+		return err
+	}
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		if message := strings.TrimSpace(string(output)); message != "" {
+			return fmt.Errorf("%w: %s", err, strings.TrimPrefix(message, "codequest: "))
+		}
+		return err
+	}
+	return nil
+}
+
+// enter assembles a read-only copy of the root with Scratch writable,
+// switches to it and drops every capability the program could regain.
+func (s *sandboxSpec) enter() error {
+	// Keep every mount below private to this namespace
+	if err := syscall.Mount("", "/", "", syscall.MS_REC|syscall.MS_PRIVATE, ""); err != nil {
+		return fmt.Errorf("failed to make mounts private: %w", err)
+	}
+	if err := syscall.Mount("/", s.Root, "", syscall.MS_BIND|syscall.MS_REC, ""); err != nil {
+		return fmt.Errorf("failed to bind the root: %w", err)
+	}
+	if err := remountReadOnly(s.Root); err != nil {
+		return err
+	}
+
+	// A proc for the new pid namespace and a sysfs for the new network
+	// namespace; the read-only host ones remain where the kernel refuses
+	syscall.Mount("proc", filepath.Join(s.Root, "proc"), "proc", syscall.MS_NOSUID|syscall.MS_NODEV|syscall.MS_NOEXEC, "")
+	syscall.Mount("sysfs", filepath.Join(s.Root, "sys"), "sysfs", syscall.MS_RDONLY|syscall.MS_NOSUID|syscall.MS_NODEV|syscall.MS_NOEXEC, "")
+
+	if err := syscall.Mount(s.Scratch, filepath.Join(s.Root, s.Scratch), "", syscall.MS_BIND, ""); err != nil {
+		return fmt.Errorf("failed to bind the scratch directory: %w", err)
+	}
+
+	// Switch to the new root and detach the old one
+	if err := syscall.Chdir(s.Root); err != nil {
+		return err
+	}
+	if err := syscall.PivotRoot(".", "."); err != nil {
+		return fmt.Errorf("failed to switch root: %w", err)
+	}
+	if err := syscall.Unmount(".", syscall.MNT_DETACH); err != nil {
+		return fmt.Errorf("failed to detach the old root: %w", err)
+	}
+	if err := syscall.Chdir(s.Scratch); err != nil {
+		return err
+	}
+
+	return dropCapabilities()
+}
+
+// remountReadOnly makes root and every mount below it read-only. A mount
+// that cannot be remounted is detached instead.
+func remountReadOnly(root string) error {
+	mountPoints, err := mountPointsBelow(root)
+	if err != nil {
+		return err
+	}
+
+	var detached []string
+	for _, mountPoint := range mountPoints {
+		if isBelowAny(mountPoint, detached) {
+			continue
+		}
+		if err := syscall.Mount("", mountPoint, "", syscall.MS_BIND|syscall.MS_REMOUNT|syscall.MS_RDONLY|lockedMountFlags(mountPoint), ""); err == nil {
+			continue
+		}
+		if mountPoint == root {
+			return fmt.Errorf("failed to make the root read-only: %w", err)
+		}
+		if err := syscall.Unmount(mountPoint, syscall.MNT_DETACH); err != nil {
+			return fmt.Errorf("failed to make %s read-only: %w", strings.TrimPrefix(mountPoint, root), err)
+		}
+		detached = append(detached, mountPoint)
+	}
+	return nil
+}
+
+// mountPointsBelow lists root and the mounts below it, parents first.
+func mountPointsBelow(root string) ([]string, error) {
+	file, err := os.Open("/proc/self/mountinfo")
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var mountPoints []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 5 {
+			continue
+		}
+		mountPoint := unescapeMountPoint(fields[4])
+		if mountPoint == root || strings.HasPrefix(mountPoint, root+"/") {
+			mountPoints = append(mountPoints, mountPoint)
+		}
+	}
+	return mountPoints, scanner.Err()
+}
+
+// unescapeMountPoint decodes the octal escapes mountinfo uses for spaces,
+// tabs, newlines and backslashes.
+func unescapeMountPoint(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+3 < len(s) {
+			if n, err := strconv.ParseUint(s[i+1:i+4], 8, 8); err == nil {
+				b.WriteByte(byte(n))
+				i += 3
+				continue
+			}
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+func isBelowAny(path string, parents []string) bool {
+	for _, parent := range parents {
+		if strings.HasPrefix(path, parent+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// lockedMountFlags returns the flags of the mount at path that a user
+// namespace may not clear, which a remount must therefore repeat.
+func lockedMountFlags(path string) uintptr {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(path, &stat); err != nil {
+		return 0
+	}
+	// The ST_ and MS_ values agree except for relatime
+	flags := uintptr(stat.Flags) & (syscall.MS_NOSUID | syscall.MS_NODEV | syscall.MS_NOEXEC | syscall.MS_NOATIME | syscall.MS_NODIRATIME)
+	if stat.Flags&0x1000 != 0 {
+		flags |= syscall.MS_RELATIME
+	}
+	return flags
+}
+
+// dropCapabilities empties the bounding and ambient sets and stops root from
+// gaining capabilities on exec, so the program keeps none.
+func dropCapabilities() error {
+	for capability := 0; ; capability++ {
+		if _, _, errno := syscall.RawSyscall(syscall.SYS_PRCTL, syscall.PR_CAPBSET_DROP, uintptr(capability), 0); errno != 0 {
+			if errno == syscall.EINVAL && capability > 0 {
+				break
+			}
+			return fmt.Errorf("failed to drop capability %d: %w", capability, errno)
+		}
+	}
+	if _, _, errno := syscall.RawSyscall6(syscall.SYS_PRCTL, prCapAmbient, prCapAmbientClearAll, 0, 0, 0, 0); errno != 0 && errno != syscall.EINVAL {
+		return fmt.Errorf("failed to clear ambient capabilities: %w", errno)
+	}
+	if _, _, errno := syscall.RawSyscall(syscall.SYS_PRCTL, syscall.PR_SET_SECUREBITS, secbitNoRoot|secbitNoRootLocked, 0); errno != 0 {
+		return fmt.Errorf("failed to set securebits: %w", errno)
+	}
+	return nil
+}
+
+// installSeccompFilter denies the calling thread, and the program it
+// executes, the system calls that could escape or weaken the sandbox.
+func installSeccompFilter() error {
+	if deniedSyscalls == nil {
+		return errors.New("no seccomp filter for " + runtime.GOARCH)
+	}
+
+	filter := seccompFilter()
+	program := syscall.SockFprog{Len: uint16(len(filter)), Filter: &filter[0]}
+	if _, _, errno := syscall.RawSyscall(syscall.SYS_PRCTL, prSetNoNewPrivs, 1, 0); errno != 0 {
+		return fmt.Errorf("failed to set no_new_privs: %w", errno)
+	}
+	if _, _, errno := syscall.RawSyscall(syscall.SYS_PRCTL, syscall.PR_SET_SECCOMP, seccompModeFilter, uintptr(unsafe.Pointer(&program))); errno != 0 {
+		return errno
+	}
+	return nil
+}
+
+// seccompFilter builds a BPF program that kills processes using another
+// architecture's system calls, fails denied system calls with EPERM and
+// clone calls that create namespaces, and fails clone3, whose flags it
+// cannot inspect, with ENOSYS so that libc falls back to clone.
+func seccompFilter() []syscall.SockFilter {
+	stmt := func(code uint16, k uint32) syscall.SockFilter {
+		return syscall.SockFilter{Code: code, K: k}
+	}
+	jump := func(code uint16, k uint32, jt, jf uint8) syscall.SockFilter {
+		return syscall.SockFilter{Code: code, Jt: jt, Jf: jf, K: k}
+	}
+	const (
+		load     = syscall.BPF_LD | syscall.BPF_W | syscall.BPF_ABS
+		jumpEq   = syscall.BPF_JMP | syscall.BPF_JEQ | syscall.BPF_K
+		jumpGe   = syscall.BPF_JMP | syscall.BPF_JGE | syscall.BPF_K
+		jumpSet  = syscall.BPF_JMP | syscall.BPF_JSET | syscall.BPF_K
+		ret      = syscall.BPF_RET | syscall.BPF_K
+		nrOffset = 0
+		archOff  = 4
+		arg0Off  = 16
+	)
+	deny := seccompRetErrno | uint32(syscall.EPERM)
+
+	filter := []syscall.SockFilter{
+		stmt(load, archOff),
+		jump(jumpEq, auditArch, 1, 0),
+		stmt(ret, seccompRetKill),
+		stmt(load, nrOffset),
+	}
+	if syscallNumberLimit > 0 {
+		filter = append(filter,
+			jump(jumpGe, syscallNumberLimit, 0, 1),
+			stmt(ret, seccompRetErrno|uint32(syscall.ENOSYS)),
+		)
+	}
+	for _, nr := range deniedSyscalls {
+		filter = append(filter, jump(jumpEq, nr, 0, 1), stmt(ret, deny))
+	}
+	return append(filter,
+		jump(jumpEq, sysClone3, 0, 1),
+		stmt(ret, seccompRetErrno|uint32(syscall.ENOSYS)),
+		jump(jumpEq, sysClone, 0, 3),
+		stmt(load, arg0Off),
+		jump(jumpSet, cloneNamespaces, 0, 1),
+		stmt(ret, deny),
+		stmt(ret, seccompRetAllow),
+	)
+}
@@ -0,0 +1,51 @@
+package native
+
+import (
+	"context"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestSandbox(t *testing.T) {
+	if err := CheckSandbox(); err != nil {
+		t.Skipf("sandbox not available: %v", err)
+	}
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+
+	outside := t.TempDir()
+	limits := DefaultLimits()
+	limits.Sandbox = true
+	script := `
+echo "pid $$"
+echo data > scratch.txt && echo "scratch writable"
+touch ` + outside + `/escaped 2>/dev/null && echo "outside writable"
+touch /codequest-escaped 2>/dev/null && echo "root writable"
+tail -n +3 /proc/net/dev | cut -d: -f1
+grep -E '^(CapEff|Seccomp):' /proc/self/status
+unshare -r true 2>/dev/null && echo "unshare allowed"
+`
+	result, err := runLimited(context.Background(), t.TempDir(), limits, "sh", "-c", script)
+	if err != nil {
+		t.Fatalf("runLimited() failed: %v", err)
+	}
+
+	for _, want := range []string{"pid 1\n", "scratch writable", "CapEff:\t0000000000000000", "Seccomp:\t2"} {
+		if !strings.Contains(result.Output, want) {
+			t.Errorf("Expected %q in the output:\n%s", want, result.Output)
+		}
+	}
+	for _, unwanted := range []string{"outside writable", "root writable", "eth", "unshare allowed"} {
+		if strings.Contains(result.Output, unwanted) {
+			t.Errorf("Expected no %q in the output:\n%s", unwanted, result.Output)
+		}
+	}
+}
+
+func TestUnescapeMountPoint(t *testing.T) {
+	if got := unescapeMountPoint(`/mnt/my\040disk\134x`); got != `/mnt/my disk\x` {
+		t.Errorf("unescapeMountPoint() = %q", got)
+	}
+}
@@ -0,0 +1,57 @@
+package native
+
+// auditArch is AUDIT_ARCH_X86_64.
+const auditArch = 0xc000003e
+
+// syscallNumberLimit rejects x32 system calls, which share the x86-64
+// architecture value.
+const syscallNumberLimit = 0x40000000
+
+const (
+	sysClone  = 56
+	sysClone3 = 435
+)
+
+// deniedSyscalls administer the system, change mounts or namespaces, or
+// inspect other processes.
+var deniedSyscalls = []uint32{
+	101, // ptrace
+	103, // syslog
+	153, // vhangup
+	155, // pivot_root
+	161, // chroot
+	163, // acct
+	165, // mount
+	166, // umount2
+	167, // swapon
+	168, // swapoff
+	169, // reboot
+	170, // sethostname
+	171, // setdomainname
+	172, // iopl
+	173, // ioperm
+	175, // init_module
+	176, // delete_module
+	179, // quotactl
+	246, // kexec_load
+	248, // add_key
+	249, // request_key
+	250, // keyctl
+	272, // unshare
+	298, // perf_event_open
+	304, // open_by_handle_at
+	308, // setns
+	310, // process_vm_readv
+	311, // process_vm_writev
+	313, // finit_module
+	320, // kexec_file_load
+	321, // bpf
+	323, // userfaultfd
+	428, // open_tree
+	429, // move_mount
+	430, // fsopen
+	431, // fsconfig
+	432, // fsmount
+	433, // fspick
+	442, // mount_setattr
+}
@@ -0,0 +1,14 @@
+//go:build linux && !amd64 && !arm64
+
+package native
+
+// There is no seccomp filter for this architecture, so CheckSandbox fails
+// and programs run unsandboxed.
+const (
+	auditArch          = 0
+	syscallNumberLimit = 0
+	sysClone           = 0
+	sysClone3          = 0
+)
+
+var deniedSyscalls []uint32
@@ -0,0 +1,167 @@
+package native
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// typeScriptToolchain is one way of running a TypeScript program. Toolchains
+// with a compile step report syntax and, for tsc and deno, type errors before
+// the program runs.
+type typeScriptToolchain struct {
+	name string
+	// requires lists the executables that must be on PATH
+	requires []string
+	// supported is an extra check beyond requires, if any
+	supported func() bool
+	// config holds files written next to solution.ts before compiling
+	config map[string]string
+	// compile checks or transpiles solution.ts; empty when run takes TypeScript directly
+	compile []string
+	run     []string
+}
+
+// tsconfig keeps tsc permissive enough for the generated harness, which is
+// plain JavaScript, while still type checking the solution. Forcing module
+// detection keeps user declarations from clashing with DOM globals.
+const tsconfig = `{
+  "compilerOptions": {
+    "target": "es2022",
+    "module": "commonjs",
+    "moduleDetection": "force",
+    "lib": ["es2022", "dom"],
+    "types": [],
+    "strict": false,
+    "noEmitOnError": true,
+    "skipLibCheck": true,
+    "pretty": false
+  },
+  "files": ["solution.ts"]
+}
+`
+
+// Toolchains that type check come first.
+var typeScriptToolchains = []typeScriptToolchain{
+	{
+		name:     "tsc",
+		requires: []string{"tsc", "node"},
+		config:   map[string]string{"tsconfig.json": tsconfig},
+		compile:  []string{"tsc", "-p", "tsconfig.json"},
+		run:      []string{"node", "solution.js"},
+	},
+	{
+		name:     "deno",
+		requires: []string{"deno"},
+		config:   map[string]string{"deno.json": `{"compilerOptions": {"strict": false}}` + "\n"},
+		compile:  []string{"deno", "check", "solution.ts"},
+		run:      []string{"deno", "run", "solution.ts"},
+	},
+	{
+		name:     "esbuild",
+		requires: []string{"esbuild", "node"},
+		compile:  []string{"esbuild", "solution.ts", "--outfile=solution.js", "--format=cjs", "--platform=node", "--log-level=error"},
+		run:      []string{"node", "solution.js"},
+	},
+	{
+		name:     "tsx",
+		requires: []string{"tsx"},
+		run:      []string{"tsx", "solution.ts"},
+	},
+	{
+		name:      "node",
+		requires:  []string{"node"},
+		supported: nodeStripsTypes,
+		run:       []string{"node", "--experimental-strip-types", "--no-warnings", "solution.ts"},
+	},
+	{
+		name:     "bun",
+		requires: []string{"bun"},
+		run:      []string{"bun", "run", "solution.ts"},
+	},
+}
+
+// findTypeScriptToolchain returns the first usable toolchain.
+func findTypeScriptToolchain() (*typeScriptToolchain, error) {
+	for i := range typeScriptToolchains {
+		toolchain := &typeScriptToolchains[i]
+		if toolchain.available() {
+			return toolchain, nil
+		}
+	}
+	return nil, fmt.Errorf("TypeScript toolchain not found. Install one of: " +
+		"tsc (npm install -g typescript), " +
+		"tsx (npm install -g tsx), " +
+		"esbuild (npm install -g esbuild), " +
+		"Node.js 22.6+ (built-in type stripping), " +
+		"Deno (https://deno.com) or Bun (https://bun.sh)")
+}
+
+func (t *typeScriptToolchain) available() bool {
+	for _, name := range t.requires {
+		if _, err := exec.LookPath(name); err != nil {
+			return false
+		}
+	}
+	return t.supported == nil || t.supported()
+}
+
+// build writes code to solution.ts in execDir and compiles it if the
+// toolchain has a compile step. It returns a result only when compilation
+// failed.
+func (t *typeScriptToolchain) build(ctx context.Context, execDir, code string) (*ExecutionResult, error) {
+	if err := os.WriteFile(filepath.Join(execDir, "solution.ts"), []byte(code), 0644); err != nil {
+		return nil, fmt.Errorf("failed to write TypeScript code: %w", err)
+	}
+	for name, content := range t.config {
+		if err := os.WriteFile(filepath.Join(execDir, name), []byte(content), 0644); err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", name, err)
+		}
+	}
+
+	if len(t.compile) > 0 {
+		compileCmd := exec.CommandContext(ctx, t.compile[0], t.compile[1:]...)
+		compileCmd.Dir = execDir
+		if output, err := compileCmd.CombinedOutput(); err != nil {
+			return buildFailed(ctx, output, fmt.Errorf("compilation failed (%s): %w", t.name, err)), nil
+		}
+	}
+	return nil, nil
+}
+
+// execute runs the program that build prepared in execDir.
+func (t *typeScriptToolchain) execute(ctx context.Context, execDir string, limits Limits) (*ExecutionResult, error) {
+	return runLimited(ctx, execDir, limits, t.run[0], t.run[1:]...)
+}
+
+// nodeStripsTypes reports whether the installed node can run TypeScript
+// files with --experimental-strip-types.
+func nodeStripsTypes() bool {
+	output, err := exec.Command("node", "--version").Output()
+	if err != nil {
+		return false
+	}
+	return supportsTypeStripping(string(output))
+}
+
+// supportsTypeStripping reports whether a node version string such as
+// "v22.6.0" has built-in type stripping, added in Node.js 22.6.
+func supportsTypeStripping(version string) bool {
+	parts := strings.SplitN(strings.TrimPrefix(strings.TrimSpace(version), "v"), ".", 3)
+	if len(parts) < 2 {
+		return false
+	}
+	major, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return false
+	}
+	minor, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return false
+	}
+	return major > 22 || (major == 22 && minor >= 6)
+}
@@ -0,0 +1,53 @@
+package native
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSupportsTypeStripping(t *testing.T) {
+	tests := []struct {
+		version string
+		want    bool
+	}{
+		{"v20.19.5\n", false},
+		{"v22.5.1", false},
+		{"v22.6.0", true},
+		{"v23.0.0", true},
+		{"v24.1.0\n", true},
+		{"", false},
+		{"not a version", false},
+	}
+
+	for _, tt := range tests {
+		if got := supportsTypeStripping(tt.version); got != tt.want {
+			t.Errorf("supportsTypeStripping(%q) = %v, want %v", tt.version, got, tt.want)
+		}
+	}
+}
+
+func TestTypeScriptToolchainsPreferTypeCheckers(t *testing.T) {
+	if typeScriptToolchains[0].name != "tsc" || typeScriptToolchains[1].name != "deno" {
+		t.Error("tsc and deno should be tried first because they report type errors")
+	}
+
+	for _, toolchain := range typeScriptToolchains {
+		if len(toolchain.run) == 0 {
+			t.Errorf("toolchain %s has no run command", toolchain.name)
+		}
+	}
+}
+
+func TestFindTypeScriptToolchainListsOptions(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+
+	_, err := findTypeScriptToolchain()
+	if err == nil {
+		t.Fatal("Expected an error when no toolchain is on PATH")
+	}
+	for _, option := range []string{"tsc", "tsx", "esbuild", "Node.js 22.6+", "Deno", "Bun"} {
+		if !strings.Contains(err.Error(), option) {
+			t.Errorf("Error should mention %s: %v", option, err)
+		}
+	}
+}
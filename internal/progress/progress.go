@@ -0,0 +1,327 @@
+// Package progress keeps a journal of every test run and submission and
+// summarizes a learner's progress from it.
+//
+// The journal is a JSON lines file in the codequest config directory with
+// one Event per line. It is only ever appended to, so concurrent runs don't
+// lose each other's events.
+package progress
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/crisecheverria/codequest/internal/challenge"
+	"github.com/crisecheverria/codequest/internal/config"
+)
+
+// FileName is the journal file in the codequest config directory.
+const FileName = "progress.jsonl"
+
+// Kind is the command that produced an event.
+type Kind string
+
+const (
+	KindTest   Kind = "test"
+	KindSubmit Kind = "submit"
+)
+
+// Event is one run of a solution against a challenge's test cases.
+type Event struct {
+	Kind     Kind      `json:"kind"`
+	Slug     string    `json:"slug"`
+	Language string    `json:"language"`
+	Time     time.Time `json:"time"`
+	// Passed is true when every test case passed
+	Passed bool `json:"passed"`
+	// Status is how the program ended, such as "ok" or "compile error"
+	Status      string `json:"status"`
+	TestsPassed int    `json:"testsPassed"`
+	TestsTotal  int    `json:"testsTotal"`
+}
+
+// Journal is the append-only file of events.
+type Journal struct {
+	path string
+}
+
+// NewJournal returns the journal stored at path.
+func NewJournal(path string) *Journal {
+	return &Journal{path: path}
+}
+
+// DefaultJournal returns the journal in the codequest config directory.
+func DefaultJournal() (*Journal, error) {
+	dir, err := config.Dir()
+	if err != nil {
+		return nil, err
+	}
+	return NewJournal(filepath.Join(dir, FileName)), nil
+}
+
+// Append adds event to the journal.
+func (j *Journal) Append(event Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode progress: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(j.path), 0755); err != nil {
+		return fmt.Errorf("failed to create progress directory: %w", err)
+	}
+	file, err := os.OpenFile(j.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open progress journal: %w", err)
+	}
+	// A single write keeps the line whole when runs append concurrently
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to record progress: %w", err)
+	}
+	return file.Close()
+}
+
+// Events returns every event in the order it was recorded. A missing
+// journal has no events, and lines that can't be decoded, such as one cut
+// short by a crash, are skipped.
+func (j *Journal) Events() ([]Event, error) {
+	file, err := os.Open(j.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open progress journal: %w", err)
+	}
+	defer file.Close()
+
+	var events []Event
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1<<20)
+	for scanner.Scan() {
+		var event Event
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil || event.Slug == "" {
+			continue
+		}
+		events = append(events, event)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read progress journal: %w", err)
+	}
+	return events, nil
+}
+
+// MigrateSlugs renames events recorded under the slug a variant had before
+// its challenge was merged into a multi-language one, such as
+// add-two-numbers-typescript, to the slug of challenges it has now, so that
+// progress made before the merge still counts. Events are changed in place
+// and returned.
+func MigrateSlugs(events []Event, challenges []challenge.Challenge) []Event {
+	known := map[Key]bool{}
+	for _, ch := range challenges {
+		known[KeyOf(ch)] = true
+	}
+	for i := range events {
+		event := &events[i]
+		if known[Key{Slug: event.Slug, Language: event.Language}] {
+			continue
+		}
+		if ch, ok := challenge.FindVariant(challenges, event.Slug, event.Language); ok {
+			event.Slug = ch.Slug
+		}
+	}
+	return events
+}
+
+// Key identifies a challenge in one language.
+type Key struct {
+	Slug     string
+	Language string
+}
+
+// KeyOf returns the key of ch.
+func KeyOf(ch challenge.Challenge) Key {
+	return Key{Slug: ch.Slug, Language: ch.Language}
+}
+
+// Status is how far a learner got with a challenge.
+type Status string
+
+const (
+	StatusNew       Status = "new"
+	StatusAttempted Status = "attempted"
+	StatusSolved    Status = "solved"
+)
+
+// ChallengeProgress is the progress on one challenge.
+type ChallengeProgress struct {
+	// Attempts counts runs up to and including the first passing one, or
+	// every run while the challenge is unsolved
+	Attempts int
+	Solved   bool
+	SolvedAt time.Time
+	LastRun  time.Time
+}
+
+// FirstTry reports whether the first run solved the challenge.
+func (p *ChallengeProgress) FirstTry() bool {
+	return p.Solved && p.Attempts == 1
+}
+
+// Summary is the progress on every challenge that was run at least once.
+type Summary map[Key]*ChallengeProgress
+
+// Summarize replays events in order.
+func Summarize(events []Event) Summary {
+	summary := Summary{}
+	for _, event := range events {
+		key := Key{Slug: event.Slug, Language: event.Language}
+		p, ok := summary[key]
+		if !ok {
+			p = &ChallengeProgress{}
+			summary[key] = p
+		}
+		if !p.Solved {
+			p.Attempts++
+			if event.Passed {
+				p.Solved = true
+				p.SolvedAt = event.Time
+			}
+		}
+		if event.Time.After(p.LastRun) {
+			p.LastRun = event.Time
+		}
+	}
+	return summary
+}
+
+// Status returns the status of ch.
+func (s Summary) Status(ch challenge.Challenge) Status {
+	p, ok := s[KeyOf(ch)]
+	switch {
+	case !ok:
+		return StatusNew
+	case p.Solved:
+		return StatusSolved
+	default:
+		return StatusAttempted
+	}
+}
+
+// Tally counts challenges by status.
+type Tally struct {
+	Solved    int
+	Attempted int
+	Total     int
+}
+
+func (t *Tally) add(status Status) {
+	t.Total++
+	switch status {
+	case StatusSolved:
+		t.Solved++
+	case StatusAttempted:
+		t.Attempted++
+	}
+}
+
+// Totals tallies every challenge in challenges.
+func (s Summary) Totals(challenges []challenge.Challenge) Tally {
+	var tally Tally
+	for _, ch := range challenges {
+		tally.add(s.Status(ch))
+	}
+	return tally
+}
+
+// GroupBy tallies challenges under each of the groups returned by groups,
+// such as their language or concept tags.
+func (s Summary) GroupBy(challenges []challenge.Challenge, groups func(challenge.Challenge) []string) map[string]*Tally {
+	tallies := map[string]*Tally{}
+	for _, ch := range challenges {
+		status := s.Status(ch)
+		for _, group := range groups(ch) {
+			tally, ok := tallies[group]
+			if !ok {
+				tally = &Tally{}
+				tallies[group] = tally
+			}
+			tally.add(status)
+		}
+	}
+	return tallies
+}
+
+// FirstTryRate returns how many solved challenges were solved by their
+// first run and how many were solved in total.
+func (s Summary) FirstTryRate() (firstTry, solved int) {
+	for _, p := range s {
+		if p.Solved {
+			solved++
+			if p.FirstTry() {
+				firstTry++
+			}
+		}
+	}
+	return firstTry, solved
+}
+
+// AverageAttempts returns the mean number of runs it took to solve a
+// challenge, or 0 if none is solved.
+func (s Summary) AverageAttempts() float64 {
+	attempts, solved := 0, 0
+	for _, p := range s {
+		if p.Solved {
+			attempts += p.Attempts
+			solved++
+		}
+	}
+	if solved == 0 {
+		return 0
+	}
+	return float64(attempts) / float64(solved)
+}
+
+// Streaks returns the number of consecutive days with at least one run
+// ending today, or yesterday if there was no run today yet, and the longest
+// such run of days. Days are calendar days in now's location.
+func Streaks(events []Event, now time.Time) (current, longest int) {
+	days := map[time.Time]bool{}
+	for _, event := range events {
+		days[day(event.Time.In(now.Location()))] = true
+	}
+	if len(days) == 0 {
+		return 0, 0
+	}
+
+	sorted := make([]time.Time, 0, len(days))
+	for d := range days {
+		sorted = append(sorted, d)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Before(sorted[j]) })
+
+	run := 0
+	for i, d := range sorted {
+		if i > 0 && sorted[i-1].AddDate(0, 0, 1).Equal(d) {
+			run++
+		} else {
+			run = 1
+		}
+		longest = max(longest, run)
+	}
+
+	today := day(now)
+	last := sorted[len(sorted)-1]
+	if last.Equal(today) || last.AddDate(0, 0, 1).Equal(today) {
+		current = run
+	}
+	return current, longest
+}
+
+// day truncates t to midnight in its location.
+func day(t time.Time) time.Time {
+	year, month, date := t.Date()
+	return time.Date(year, month, date, 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,137 @@
+package progress
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/crisecheverria/codequest/internal/challenge"
+)
+
+func TestJournal(t *testing.T) {
+	journal := NewJournal(filepath.Join(t.TempDir(), "codequest", FileName))
+
+	events, err := journal.Events()
+	if err != nil || len(events) != 0 {
+		t.Fatalf("Expected no events from a missing journal, got %v (%v)", events, err)
+	}
+
+	first := Event{Kind: KindTest, Slug: "sum", Language: "go", Time: time.Now().UTC(), Status: "runtime error", TestsTotal: 2}
+	second := Event{Kind: KindSubmit, Slug: "sum", Language: "go", Time: time.Now().UTC(), Passed: true, Status: "ok", TestsPassed: 2, TestsTotal: 2}
+	for _, event := range []Event{first, second} {
+		if err := journal.Append(event); err != nil {
+			t.Fatalf("Append() failed: %v", err)
+		}
+	}
+
+	// A line cut short by a crash is skipped
+	file, err := os.OpenFile(journal.path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatalf("Failed to open journal: %v", err)
+	}
+	file.WriteString(`{"kind":"test","slug":"su`)
+	file.Close()
+
+	events, err = journal.Events()
+	if err != nil {
+		t.Fatalf("Events() failed: %v", err)
+	}
+	if len(events) != 2 || events[0].Status != "runtime error" || events[1].Kind != KindSubmit {
+		t.Errorf("Unexpected events: %+v", events)
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	events := []Event{
+		{Slug: "sum", Language: "go", Time: start},
+		{Slug: "sum", Language: "go", Time: start.Add(time.Minute)},
+		{Slug: "sum", Language: "go", Time: start.Add(2 * time.Minute), Passed: true},
+		{Slug: "sum", Language: "go", Time: start.Add(3 * time.Minute)},
+		{Slug: "reverse", Language: "python", Time: start, Passed: true},
+		{Slug: "average", Language: "rust", Time: start},
+	}
+	summary := Summarize(events)
+
+	sum := summary[Key{Slug: "sum", Language: "go"}]
+	if !sum.Solved || sum.Attempts != 3 || sum.FirstTry() || !sum.LastRun.Equal(start.Add(3*time.Minute)) {
+		t.Errorf("Unexpected progress: %+v", sum)
+	}
+
+	challenges := []challenge.Challenge{
+		{Slug: "sum", Language: "go", Difficulty: "easy", ConceptTags: []string{"loops"}},
+		{Slug: "reverse", Language: "python", Difficulty: "easy", ConceptTags: []string{"strings", "loops"}},
+		{Slug: "average", Language: "rust", Difficulty: "medium"},
+		{Slug: "stack", Language: "rust", Difficulty: "hard"},
+	}
+	if status := summary.Status(challenges[2]); status != StatusAttempted {
+		t.Errorf("Expected %q, got %q", StatusAttempted, status)
+	}
+	if status := summary.Status(challenges[3]); status != StatusNew {
+		t.Errorf("Expected %q, got %q", StatusNew, status)
+	}
+	if totals := summary.Totals(challenges); totals != (Tally{Solved: 2, Attempted: 1, Total: 4}) {
+		t.Errorf("Unexpected totals: %+v", totals)
+	}
+
+	concepts := summary.GroupBy(challenges, func(ch challenge.Challenge) []string { return ch.ConceptTags })
+	if *concepts["loops"] != (Tally{Solved: 2, Total: 2}) || *concepts["strings"] != (Tally{Solved: 1, Total: 1}) {
+		t.Errorf("Unexpected concept tallies: loops %+v, strings %+v", concepts["loops"], concepts["strings"])
+	}
+
+	if firstTry, solved := summary.FirstTryRate(); firstTry != 1 || solved != 2 {
+		t.Errorf("FirstTryRate() = %d, %d, want 1, 2", firstTry, solved)
+	}
+	if average := summary.AverageAttempts(); average != 2 {
+		t.Errorf("AverageAttempts() = %v, want 2", average)
+	}
+}
+
+func TestMigrateSlugs(t *testing.T) {
+	variants := []challenge.Variant{{Language: "go"}, {Language: "typescript"}}
+	challenges := []challenge.Challenge{
+		{Slug: "add-two-numbers", Language: "go", Variants: variants},
+		{Slug: "add-two-numbers", Language: "typescript", Variants: variants},
+		{Slug: "sum", Language: "go"},
+	}
+	events := MigrateSlugs([]Event{
+		{Slug: "add-two-numbers-typescript", Language: "typescript", Passed: true},
+		{Slug: "sum", Language: "go"},
+		{Slug: "removed", Language: "go"},
+	}, challenges)
+
+	if events[0].Slug != "add-two-numbers" || events[1].Slug != "sum" || events[2].Slug != "removed" {
+		t.Errorf("Unexpected slugs after migrating: %+v", events)
+	}
+	if status := Summarize(events).Status(challenges[1]); status != StatusSolved {
+		t.Errorf("Expected the variant solved under its old slug to be %q, got %q", StatusSolved, status)
+	}
+}
+
+func TestStreaks(t *testing.T) {
+	at := func(day int) Event {
+		return Event{Slug: "sum", Time: time.Date(2024, 5, day, 18, 0, 0, 0, time.UTC)}
+	}
+	events := []Event{at(1), at(2), at(3), at(3), at(7), at(8)}
+
+	tests := []struct {
+		now              time.Time
+		current, longest int
+	}{
+		{time.Date(2024, 5, 8, 20, 0, 0, 0, time.UTC), 2, 3},
+		// The streak lasts until a whole day passes without a run
+		{time.Date(2024, 5, 9, 9, 0, 0, 0, time.UTC), 2, 3},
+		{time.Date(2024, 5, 10, 9, 0, 0, 0, time.UTC), 0, 3},
+	}
+	for _, tt := range tests {
+		current, longest := Streaks(events, tt.now)
+		if current != tt.current || longest != tt.longest {
+			t.Errorf("Streaks(%v) = %d, %d, want %d, %d", tt.now, current, longest, tt.current, tt.longest)
+		}
+	}
+
+	if current, longest := Streaks(nil, time.Now()); current != 0 || longest != 0 {
+		t.Errorf("Expected no streak without events, got %d, %d", current, longest)
+	}
+}
@@ -0,0 +1,308 @@
+// Package recommend picks the challenge a learner should take on next from
+// their progress, the concept graph and challenge difficulty.
+package recommend
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/crisecheverria/codequest/internal/challenge"
+	"github.com/crisecheverria/codequest/internal/progress"
+)
+
+// Options narrows the challenges considered.
+type Options struct {
+	// Language, if set, only considers challenges in this language
+	Language string
+	// Concept, if set, only considers challenges tagged with this concept
+	Concept string
+}
+
+// Recommendation is the suggested challenge and why it was chosen.
+type Recommendation struct {
+	Challenge challenge.Challenge
+	Reasons   []string
+}
+
+// candidate is an unsolved challenge with what it takes to start it.
+type candidate struct {
+	challenge  challenge.Challenge
+	index      int
+	difficulty int
+	attempted  bool
+	// missing are prerequisite concepts not covered by solved challenges
+	missing []string
+	// order is the latest learning path position among its concepts
+	order int
+}
+
+// Next recommends an unsolved challenge. A challenge whose prerequisite
+// concepts are all covered by solved challenges comes before one that needs
+// concepts the learner hasn't practiced, then easier before harder, started
+// before new, and earlier in the learning path before later. It reports
+// false when every matching challenge is solved.
+func Next(challenges []challenge.Challenge, concepts []challenge.Concept, summary progress.Summary, opts Options) (Recommendation, bool) {
+	graph := newConceptGraph(concepts, challenges, summary)
+
+	var candidates []candidate
+	for i, ch := range challenges {
+		if !matches(ch, opts) || summary.Status(ch) == progress.StatusSolved {
+			continue
+		}
+		candidates = append(candidates, candidate{
+			challenge:  ch,
+			index:      i,
+			difficulty: difficultyRank(ch.Difficulty),
+			attempted:  summary.Status(ch) == progress.StatusAttempted,
+			missing:    graph.missingPrerequisites(ch),
+			order:      graph.order(ch),
+		})
+	}
+	if len(candidates) == 0 {
+		return Recommendation{}, false
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if (len(a.missing) == 0) != (len(b.missing) == 0) {
+			return len(a.missing) == 0
+		}
+		if len(a.missing) != len(b.missing) {
+			return len(a.missing) < len(b.missing)
+		}
+		if a.difficulty != b.difficulty {
+			return a.difficulty < b.difficulty
+		}
+		if a.attempted != b.attempted {
+			return a.attempted
+		}
+		if a.order != b.order {
+			return a.order < b.order
+		}
+		return a.index < b.index
+	})
+
+	best := candidates[0]
+	return Recommendation{
+		Challenge: best.challenge,
+		Reasons:   reasons(best, challenges, summary, graph, opts),
+	}, true
+}
+
+func matches(ch challenge.Challenge, opts Options) bool {
+	if opts.Language != "" && !strings.EqualFold(ch.Language, opts.Language) {
+		return false
+	}
+	if opts.Concept != "" {
+		for _, tag := range ch.ConceptTags {
+			if tag == opts.Concept {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+func difficultyRank(difficulty string) int {
+	for i, d := range challenge.Difficulties {
+		if strings.EqualFold(d, difficulty) {
+			return i
+		}
+	}
+	return len(challenge.Difficulties)
+}
+
+// reasons explains why c was picked.
+func reasons(c candidate, challenges []challenge.Challenge, summary progress.Summary, graph *conceptGraph, opts Options) []string {
+	var reasons []string
+	ch := c.challenge
+
+	if c.attempted {
+		reasons = append(reasons, "You have tried it before but not solved it yet.")
+	}
+
+	// Difficulty progression within the challenges considered
+	solvedEasier, unsolvedEasier := 0, 0
+	level, solvedLevel := 0, 0
+	for _, other := range challenges {
+		if !matches(other, opts) {
+			continue
+		}
+		solved := summary.Status(other) == progress.StatusSolved
+		switch rank := difficultyRank(other.Difficulty); {
+		case rank < c.difficulty && solved:
+			solvedEasier++
+		case rank < c.difficulty:
+			unsolvedEasier++
+		case rank == c.difficulty:
+			level++
+			if solved {
+				solvedLevel++
+			}
+		}
+	}
+	switch {
+	case unsolvedEasier > 0:
+		reasons = append(reasons, fmt.Sprintf("Easier challenges are left (%d), but they need concepts you haven't practiced yet.", unsolvedEasier))
+	case solvedEasier > 0:
+		reasons = append(reasons, fmt.Sprintf("You have solved every easier challenge, so it's time for %s ones (%d of %d solved).", ch.Difficulty, solvedLevel, level))
+	default:
+		reasons = append(reasons, fmt.Sprintf("It's %s, the easiest level with challenges left (%d of %d solved).", article(ch.Difficulty), solvedLevel, level))
+	}
+
+	var learned, fresh []string
+	for _, tag := range ch.ConceptTags {
+		name := graph.name(tag)
+		if graph.learned[tag] {
+			learned = append(learned, name)
+		} else {
+			fresh = append(fresh, name)
+		}
+	}
+	if len(fresh) > 0 {
+		reasons = append(reasons, "It introduces "+joinList(fresh)+".")
+	}
+	if len(learned) > 0 {
+		reasons = append(reasons, "It reinforces "+joinList(learned)+", which you have practiced.")
+	}
+
+	if len(c.missing) == 0 {
+		if prerequisites := graph.prerequisites(ch); len(prerequisites) > 0 {
+			reasons = append(reasons, "You have covered its prerequisites: "+joinList(prerequisites)+".")
+		}
+	} else {
+		var names []string
+		for _, slug := range c.missing {
+			names = append(names, graph.name(slug))
+		}
+		reasons = append(reasons, "Every remaining challenge needs concepts you haven't practiced; this one only needs "+joinList(names)+", so read up on them first.")
+	}
+
+	return reasons
+}
+
+func article(difficulty string) string {
+	if strings.HasPrefix(strings.ToLower(difficulty), "e") {
+		return "an " + difficulty + " challenge"
+	}
+	return "a " + difficulty + " challenge"
+}
+
+func joinList(items []string) string {
+	switch len(items) {
+	case 0:
+		return ""
+	case 1:
+		return items[0]
+	}
+	return strings.Join(items[:len(items)-1], ", ") + " and " + items[len(items)-1]
+}
+
+// conceptGraph answers prerequisite questions about concepts given the
+// learner's solved challenges.
+type conceptGraph struct {
+	bySlug map[string]challenge.Concept
+	// learned holds the concepts tagged on a solved challenge
+	learned map[string]bool
+	// exercised holds the concepts tagged on any challenge
+	exercised map[string]bool
+	met       map[string]bool
+}
+
+func newConceptGraph(concepts []challenge.Concept, challenges []challenge.Challenge, summary progress.Summary) *conceptGraph {
+	g := &conceptGraph{
+		bySlug:    map[string]challenge.Concept{},
+		learned:   map[string]bool{},
+		exercised: map[string]bool{},
+		met:       map[string]bool{},
+	}
+	for _, concept := range concepts {
+		g.bySlug[concept.Slug] = concept
+	}
+	for _, ch := range challenges {
+		solved := summary.Status(ch) == progress.StatusSolved
+		for _, tag := range ch.ConceptTags {
+			g.exercised[tag] = true
+			if solved {
+				g.learned[tag] = true
+			}
+		}
+	}
+	return g
+}
+
+// isMet reports whether a prerequisite concept is covered: practiced in a
+// solved challenge or, when no challenge exercises it, with its own
+// prerequisites covered.
+func (g *conceptGraph) isMet(slug string) bool {
+	if met, ok := g.met[slug]; ok {
+		return met
+	}
+	// Assume an unresolved cycle is not met
+	g.met[slug] = false
+
+	met := g.learned[slug]
+	if !met && !g.exercised[slug] {
+		met = true
+		for _, dependency := range g.bySlug[slug].Dependencies {
+			if !g.isMet(dependency) {
+				met = false
+				break
+			}
+		}
+	}
+	g.met[slug] = met
+	return met
+}
+
+// prerequisites returns the direct dependencies of ch's concepts that ch
+// doesn't exercise itself.
+func (g *conceptGraph) prerequisites(ch challenge.Challenge) []string {
+	tagged := map[string]bool{}
+	for _, tag := range ch.ConceptTags {
+		tagged[tag] = true
+	}
+	seen := map[string]bool{}
+	var prerequisites []string
+	for _, tag := range ch.ConceptTags {
+		for _, dependency := range g.bySlug[tag].Dependencies {
+			if !tagged[dependency] && !seen[dependency] {
+				seen[dependency] = true
+				prerequisites = append(prerequisites, dependency)
+			}
+		}
+	}
+	return prerequisites
+}
+
+// missingPrerequisites returns the prerequisites of ch that are not met.
+func (g *conceptGraph) missingPrerequisites(ch challenge.Challenge) []string {
+	var missing []string
+	for _, slug := range g.prerequisites(ch) {
+		if !g.isMet(slug) {
+			missing = append(missing, slug)
+		}
+	}
+	return missing
+}
+
+// order returns the latest learning path position of ch's concepts.
+func (g *conceptGraph) order(ch challenge.Challenge) int {
+	order := 0
+	for _, tag := range ch.ConceptTags {
+		order = max(order, g.bySlug[tag].Order)
+	}
+	return order
+}
+
+// name describes a concept by name and slug, or by slug alone when it isn't
+// in the graph.
+func (g *conceptGraph) name(slug string) string {
+	concept, ok := g.bySlug[slug]
+	if !ok || concept.Name == "" {
+		return slug
+	}
+	return fmt.Sprintf("%s (%s)", concept.Name, slug)
+}
@@ -0,0 +1,90 @@
+package recommend
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/crisecheverria/codequest/internal/challenge"
+	"github.com/crisecheverria/codequest/internal/progress"
+)
+
+var (
+	testConcepts = []challenge.Concept{
+		{Slug: "variables", Name: "Variables", Language: "go", Order: 1},
+		{Slug: "loops", Name: "Loops", Language: "go", Order: 2, Dependencies: []string{"variables"}},
+		{Slug: "slices", Name: "Slices", Language: "go", Order: 3, Dependencies: []string{"loops"}},
+		// No challenge exercises syntax, so it never blocks anything
+		{Slug: "syntax", Name: "Syntax", Language: "python", Order: 1},
+		{Slug: "functions", Name: "Functions", Language: "python", Order: 2, Dependencies: []string{"syntax"}},
+	}
+	testChallenges = []challenge.Challenge{
+		{Slug: "sum-slice", Language: "go", Difficulty: "easy", ConceptTags: []string{"slices"}},
+		{Slug: "count-up", Language: "go", Difficulty: "medium", ConceptTags: []string{"loops"}},
+		{Slug: "declare", Language: "go", Difficulty: "easy", ConceptTags: []string{"variables"}},
+		{Slug: "swap", Language: "go", Difficulty: "easy", ConceptTags: []string{"variables"}},
+		{Slug: "double", Language: "python", Difficulty: "easy", ConceptTags: []string{"functions"}},
+	}
+)
+
+func summaryOf(solved, attempted []string) progress.Summary {
+	var events []progress.Event
+	now := time.Now()
+	for _, slug := range attempted {
+		ch, _ := challenge.FindBySlug(testChallenges, slug)
+		events = append(events, progress.Event{Slug: slug, Language: ch.Language, Time: now})
+	}
+	for _, slug := range solved {
+		ch, _ := challenge.FindBySlug(testChallenges, slug)
+		events = append(events, progress.Event{Slug: slug, Language: ch.Language, Time: now, Passed: true})
+	}
+	return progress.Summarize(events)
+}
+
+func TestNext(t *testing.T) {
+	tests := []struct {
+		name      string
+		solved    []string
+		attempted []string
+		opts      Options
+		want      string
+	}{
+		{"starts at the first challenge without prerequisites", nil, nil, Options{Language: "go"}, "declare"},
+		{"prefers a started challenge", nil, []string{"swap"}, Options{Language: "go"}, "swap"},
+		{"waits for prerequisites before the easier level", []string{"declare", "swap"}, nil, Options{Language: "go"}, "count-up"},
+		{"takes the easy challenge once prerequisites are met", []string{"declare", "count-up"}, nil, Options{Language: "go"}, "swap"},
+		{"prerequisites without challenges are met", nil, nil, Options{Language: "python"}, "double"},
+		{"filters by concept", nil, nil, Options{Concept: "slices"}, "sum-slice"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec, ok := Next(testChallenges, testConcepts, summaryOf(tt.solved, tt.attempted), tt.opts)
+			if !ok {
+				t.Fatal("Expected a recommendation")
+			}
+			if rec.Challenge.Slug != tt.want {
+				t.Errorf("Next() = %s, want %s", rec.Challenge.Slug, tt.want)
+			}
+			if len(rec.Reasons) == 0 {
+				t.Error("Expected reasons")
+			}
+		})
+	}
+}
+
+func TestNextExplainsMissingPrerequisites(t *testing.T) {
+	rec, ok := Next(testChallenges, testConcepts, nil, Options{Concept: "slices"})
+	if !ok {
+		t.Fatal("Expected a recommendation")
+	}
+	if !strings.Contains(strings.Join(rec.Reasons, "\n"), "Loops (loops)") {
+		t.Errorf("Expected the missing prerequisite in the reasons, got %q", rec.Reasons)
+	}
+}
+
+func TestNextAllSolved(t *testing.T) {
+	summary := summaryOf([]string{"double"}, nil)
+	if rec, ok := Next(testChallenges, testConcepts, summary, Options{Language: "python"}); ok {
+		t.Errorf("Expected no recommendation, got %s", rec.Challenge.Slug)
+	}
+}
@@ -0,0 +1,374 @@
+// Package report describes a test run of a solution for tools: as JSON, as
+// TAP version 13 or as JUnit XML.
+//
+// Hidden test cases only report whether they passed, how long they took and
+// how they failed. Their description, input, expected and actual values,
+// error messages and output are left out, as is the program's output when
+// it stopped before reporting them.
+package report
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/crisecheverria/codequest/internal/challenge"
+	"github.com/crisecheverria/codequest/internal/harness"
+	"github.com/crisecheverria/codequest/internal/native"
+)
+
+// Formats are the formats Write supports.
+var Formats = []string{"json", "tap", "junit"}
+
+// Failure classifies why a test case didn't pass.
+type Failure string
+
+const (
+	// FailureWrongAnswer is a case whose result didn't match the expected
+	// value
+	FailureWrongAnswer Failure = "wrong_answer"
+	// FailureRuntimeError is a case that raised an error or panicked
+	FailureRuntimeError Failure = "runtime_error"
+	// FailureCompileError is every case of a program that didn't compile
+	// or type check
+	FailureCompileError Failure = "compile_error"
+	// FailureTimeLimitExceeded, FailureMemoryLimitExceeded and
+	// FailureOutputLimitExceeded are the cases a program stopped for
+	// exceeding a limit never reported
+	FailureTimeLimitExceeded   Failure = "time_limit_exceeded"
+	FailureMemoryLimitExceeded Failure = "memory_limit_exceeded"
+	FailureOutputLimitExceeded Failure = "output_limit_exceeded"
+	// FailureNoResult is a case the program never reported, usually because
+	// it crashed
+	FailureNoResult Failure = "no_result"
+)
+
+// limitFailures classify the cases a program stopped by a limit never
+// reported.
+var limitFailures = map[native.Status]Failure{
+	native.StatusTimeLimitExceeded:   FailureTimeLimitExceeded,
+	native.StatusMemoryLimitExceeded: FailureMemoryLimitExceeded,
+	native.StatusOutputLimitExceeded: FailureOutputLimitExceeded,
+}
+
+// Report is the outcome of running a solution against test cases.
+type Report struct {
+	Slug     string `json:"slug"`
+	Title    string `json:"title"`
+	Language string `json:"language"`
+	// Passed is true when the program ran successfully and every case passed
+	Passed bool `json:"passed"`
+	// Status is how the program ended, such as "ok" or "compile error"
+	Status string `json:"status"`
+	// Error explains a program that failed as a whole, such as its compile
+	// errors or how it exited
+	Error string `json:"error,omitempty"`
+	// Output is what the program printed outside of any case, shown when
+	// it stopped before reporting every case
+	Output          string  `json:"output,omitempty"`
+	CompileTimeMs   float64 `json:"compileTimeMs"`
+	RunTimeMs       float64 `json:"runTimeMs"`
+	PeakMemoryBytes int64   `json:"peakMemoryBytes,omitempty"`
+	Tests           int     `json:"tests"`
+	TestsPassed     int     `json:"testsPassed"`
+	TestsFailed     int     `json:"testsFailed"`
+	Cases           []Case  `json:"cases"`
+}
+
+// Case is the outcome of one test case.
+type Case struct {
+	// Index is the position of the case in the run, from 0
+	Index       int    `json:"index"`
+	Description string `json:"description,omitempty"`
+	Hidden      bool   `json:"hidden,omitempty"`
+	// Local cases come from the workspace rather than the challenge
+	Local  bool `json:"local,omitempty"`
+	Passed bool `json:"passed"`
+	// Failure is empty for a case that passed
+	Failure    Failure         `json:"failure,omitempty"`
+	DurationMs float64         `json:"durationMs"`
+	Input      []interface{}   `json:"input,omitempty"`
+	Expected   json.RawMessage `json:"expected,omitempty"`
+	Actual     json.RawMessage `json:"actual,omitempty"`
+	Error      string          `json:"error,omitempty"`
+	// Output is what the solution printed while the case ran
+	Output string `json:"output,omitempty"`
+}
+
+// Name returns a one-line name for the case.
+func (c Case) Name() string {
+	if c.Hidden {
+		return "hidden test case"
+	}
+	name := strings.ReplaceAll(c.Description, "\n", " ")
+	if c.Description == "" {
+		name = fmt.Sprintf("test case %d", c.Index+1)
+	}
+	if c.Local {
+		return "local: " + name
+	}
+	return name
+}
+
+// Message describes why the case failed in one line.
+func (c Case) Message() string {
+	switch {
+	case c.Error != "":
+		return c.Error
+	case c.Failure == FailureWrongAnswer && !c.Hidden:
+		return fmt.Sprintf("expected %s, got %s", c.Expected, c.Actual)
+	default:
+		return strings.ReplaceAll(string(c.Failure), "_", " ")
+	}
+}
+
+// New describes the run of ch's testCases that produced result.
+func New(ch challenge.Challenge, testCases []challenge.TestCase, result *native.ExecutionResult) *Report {
+	r := &Report{
+		Slug:            ch.Slug,
+		Title:           ch.Title,
+		Language:        ch.Language,
+		Passed:          result.Success,
+		Status:          string(result.Status),
+		CompileTimeMs:   float64(result.CompileTime) / float64(time.Millisecond),
+		RunTimeMs:       float64(result.RunTime) / float64(time.Millisecond),
+		PeakMemoryBytes: result.PeakMemory,
+		Tests:           len(testCases),
+		Cases:           make([]Case, len(testCases)),
+	}
+
+	caseResults, output := harness.ParseResults(result.Output)
+	byIndex := make(map[int]harness.CaseResult, len(caseResults))
+	for _, caseResult := range caseResults {
+		byIndex[caseResult.Index] = caseResult
+	}
+
+	hidden := false
+	for i, testCase := range testCases {
+		c := Case{Index: i, Hidden: testCase.Hidden, Local: testCase.Local}
+		hidden = hidden || testCase.Hidden
+		caseResult, ok := byIndex[i]
+		switch {
+		case result.Status == native.StatusCompileError:
+			c.Failure = FailureCompileError
+		case !ok && limitFailures[result.Status] != "":
+			c.Failure = limitFailures[result.Status]
+		case !ok:
+			c.Failure = FailureNoResult
+		case caseResult.Passed:
+			c.Passed = true
+		case caseResult.Error != "":
+			c.Failure = FailureRuntimeError
+		default:
+			c.Failure = FailureWrongAnswer
+		}
+		c.DurationMs = caseResult.DurationMs
+
+		if !c.Hidden {
+			c.Description = testCase.Description
+			c.Input = testCase.Input
+			c.Expected, _ = json.Marshal(testCase.Expected)
+			c.Actual = caseResult.Actual
+			c.Error = caseResult.Error
+			c.Output = caseResult.Output
+		}
+
+		if c.Passed {
+			r.TestsPassed++
+		} else {
+			r.TestsFailed++
+			r.Passed = false
+		}
+		r.Cases[i] = c
+	}
+
+	switch {
+	case result.Status == native.StatusCompileError:
+		r.Error = strings.TrimSpace(result.Output)
+		if r.Error == "" {
+			r.Error = result.Error
+		}
+	case !result.Success && result.Status != native.StatusStopped:
+		// A program stopped at its first failed case ended as asked
+		r.Error = result.Error
+	}
+	// Output that isn't part of any case can give away the input of a
+	// hidden case the program never reported
+	if result.Status != native.StatusCompileError && len(caseResults) < len(testCases) && !hidden {
+		r.Output = output
+	}
+	return r
+}
+
+// Write writes r to w in format, one of Formats.
+func Write(w io.Writer, format string, r *Report) error {
+	switch format {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(r)
+	case "tap":
+		return writeTAP(w, r)
+	case "junit":
+		return writeJUnit(w, r)
+	default:
+		return fmt.Errorf("unknown format %q, expected one of %s", format, strings.Join(Formats, ", "))
+	}
+}
+
+// writeTAP writes r as a TAP version 13 stream, with the details of each
+// failed case in a YAML block. Every YAML value is written as JSON, which
+// YAML parsers read as is.
+func writeTAP(w io.Writer, r *Report) error {
+	var b strings.Builder
+	b.WriteString("TAP version 13\n")
+	fmt.Fprintf(&b, "1..%d\n", len(r.Cases))
+	for _, c := range r.Cases {
+		if c.Passed {
+			fmt.Fprintf(&b, "ok %d - %s\n", c.Index+1, c.Name())
+			continue
+		}
+		fmt.Fprintf(&b, "not ok %d - %s\n", c.Index+1, c.Name())
+		b.WriteString("  ---\n")
+		yamlField(&b, "message", c.Message())
+		yamlField(&b, "failure", string(c.Failure))
+		fmt.Fprintf(&b, "  durationMs: %s\n", strconv.FormatFloat(c.DurationMs, 'f', -1, 64))
+		if c.Failure == FailureWrongAnswer && !c.Hidden {
+			fmt.Fprintf(&b, "  expected: %s\n", c.Expected)
+			fmt.Fprintf(&b, "  actual: %s\n", c.Actual)
+		}
+		if c.Output != "" {
+			yamlField(&b, "output", c.Output)
+		}
+		b.WriteString("  ...\n")
+	}
+
+	fmt.Fprintf(&b, "# %s (%s): %d/%d passed, status %s\n", r.Slug, r.Language, r.TestsPassed, r.Tests, r.Status)
+	for _, text := range []string{r.Error, r.Output} {
+		for _, line := range strings.Split(text, "\n") {
+			if line != "" {
+				fmt.Fprintf(&b, "# %s\n", line)
+			}
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func yamlField(b *strings.Builder, key, value string) {
+	fmt.Fprintf(b, "  %s: ", key)
+	enc := json.NewEncoder(b)
+	enc.SetEscapeHTML(false)
+	enc.Encode(value)
+}
+
+// seconds formats a duration in milliseconds as JUnit does, in seconds
+// without an exponent.
+func seconds(ms float64) string {
+	return strconv.FormatFloat(ms/1000, 'f', 6, 64)
+}
+
+type junitSuites struct {
+	XMLName  xml.Name     `xml:"testsuites"`
+	Name     string       `xml:"name,attr"`
+	Tests    int          `xml:"tests,attr"`
+	Failures int          `xml:"failures,attr"`
+	Errors   int          `xml:"errors,attr"`
+	Time     string       `xml:"time,attr"`
+	Suites   []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name       string          `xml:"name,attr"`
+	Tests      int             `xml:"tests,attr"`
+	Failures   int             `xml:"failures,attr"`
+	Errors     int             `xml:"errors,attr"`
+	Time       string          `xml:"time,attr"`
+	Properties []junitProperty `xml:"properties>property"`
+	Cases      []junitCase     `xml:"testcase"`
+	SystemOut  string          `xml:"system-out,omitempty"`
+	SystemErr  string          `xml:"system-err,omitempty"`
+}
+
+type junitProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitProblem `xml:"failure"`
+	Error     *junitProblem `xml:"error"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitProblem struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// writeJUnit writes r as JUnit XML with one test suite for the challenge.
+// Wrong answers are failures and every other way a case can fail is an
+// error.
+func writeJUnit(w io.Writer, r *Report) error {
+	suite := junitSuite{
+		Name:  r.Slug,
+		Tests: r.Tests,
+		Time:  seconds(r.CompileTimeMs + r.RunTimeMs),
+		Properties: []junitProperty{
+			{Name: "title", Value: r.Title},
+			{Name: "language", Value: r.Language},
+			{Name: "status", Value: r.Status},
+		},
+		SystemOut: r.Output,
+		SystemErr: r.Error,
+	}
+	for _, c := range r.Cases {
+		jc := junitCase{
+			Name:      fmt.Sprintf("%d: %s", c.Index+1, c.Name()),
+			ClassName: r.Slug + "." + r.Language,
+			Time:      seconds(c.DurationMs),
+			SystemOut: c.Output,
+		}
+		if !c.Passed {
+			problem := &junitProblem{Message: c.Message(), Type: string(c.Failure)}
+			if c.Failure == FailureWrongAnswer && !c.Hidden {
+				problem.Text = fmt.Sprintf("expected: %s\nactual: %s", c.Expected, c.Actual)
+			}
+			if c.Failure == FailureWrongAnswer {
+				jc.Failure = problem
+				suite.Failures++
+			} else {
+				jc.Error = problem
+				suite.Errors++
+			}
+		}
+		suite.Cases = append(suite.Cases, jc)
+	}
+
+	suites := junitSuites{
+		Name:     "codequest",
+		Tests:    suite.Tests,
+		Failures: suite.Failures,
+		Errors:   suite.Errors,
+		Time:     suite.Time,
+		Suites:   []junitSuite{suite},
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(suites); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,160 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/crisecheverria/codequest/internal/challenge"
+	"github.com/crisecheverria/codequest/internal/harness"
+	"github.com/crisecheverria/codequest/internal/native"
+)
+
+var testChallenge = challenge.Challenge{Slug: "double", Title: "Double", Language: "python"}
+
+var testCases = []challenge.TestCase{
+	{Input: []interface{}{float64(1)}, Expected: float64(2), Description: "doubles one"},
+	{Input: []interface{}{float64(2)}, Expected: float64(4), Description: "doubles two"},
+	{Input: []interface{}{float64(3)}, Expected: float64(6), Description: "raises"},
+	{Input: []interface{}{float64(21)}, Expected: float64(42), Description: "secret case", Hidden: true},
+}
+
+func TestNew(t *testing.T) {
+	result := &native.ExecutionResult{
+		Status: native.StatusRuntimeError,
+		Error:  "exit status 1",
+		Output: "printing 1\n" +
+			harness.ResultPrefix + `{"index":0,"passed":true,"durationMs":1,"actual":2}` + "\n" +
+			harness.ResultPrefix + `{"index":1,"passed":false,"durationMs":2,"actual":5}` + "\n" +
+			harness.ResultPrefix + `{"index":2,"passed":false,"error":"ValueError: boom"}` + "\n" +
+			"printing 21\n" +
+			harness.ResultPrefix + `{"index":3,"passed":false,"actual":41}` + "\n",
+	}
+	r := New(testChallenge, testCases, result)
+
+	if r.Passed || r.Tests != 4 || r.TestsPassed != 1 || r.TestsFailed != 3 || r.Error != "exit status 1" {
+		t.Errorf("Unexpected report: %+v", r)
+	}
+	want := []Failure{"", FailureWrongAnswer, FailureRuntimeError, FailureWrongAnswer}
+	for i, c := range r.Cases {
+		if c.Failure != want[i] || c.Passed != (want[i] == "") {
+			t.Errorf("Case %d failure = %q, want %q", i, c.Failure, want[i])
+		}
+	}
+	if c := r.Cases[0]; c.Output != "printing 1" || string(c.Expected) != "2" || string(c.Actual) != "2" {
+		t.Errorf("Unexpected first case: %+v", c)
+	}
+	if c := r.Cases[2]; c.Error != "ValueError: boom" || c.Message() != "ValueError: boom" {
+		t.Errorf("Unexpected runtime error case: %+v", c)
+	}
+	if c := r.Cases[3]; c.Description != "" || c.Input != nil || c.Expected != nil || c.Actual != nil || c.Output != "" {
+		t.Errorf("Hidden case gives away details: %+v", c)
+	}
+}
+
+func TestNewUnreportedCases(t *testing.T) {
+	tests := []struct {
+		name   string
+		status native.Status
+		want   Failure
+	}{
+		{"crash", native.StatusRuntimeError, FailureNoResult},
+		{"time limit", native.StatusTimeLimitExceeded, FailureTimeLimitExceeded},
+		{"compile error", native.StatusCompileError, FailureCompileError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := &native.ExecutionResult{Status: tt.status, Error: "failed", Output: "Traceback: boom\n"}
+			r := New(testChallenge, testCases[:2], result)
+			for _, c := range r.Cases {
+				if c.Failure != tt.want {
+					t.Errorf("Case %d failure = %q, want %q", c.Index, c.Failure, tt.want)
+				}
+			}
+			if tt.status == native.StatusCompileError {
+				if r.Error != "Traceback: boom" || r.Output != "" {
+					t.Errorf("Expected the compiler output as the error, got %+v", r)
+				}
+			} else if r.Output != "Traceback: boom" {
+				t.Errorf("Expected the leftover output, got %q", r.Output)
+			}
+		})
+	}
+
+	// The output of a program that stopped before reporting a hidden case
+	// can give it away
+	result := &native.ExecutionResult{Status: native.StatusRuntimeError, Output: "21\n"}
+	if r := New(testChallenge, testCases, result); r.Output != "" {
+		t.Errorf("Expected no output with hidden cases, got %q", r.Output)
+	}
+}
+
+func TestWrite(t *testing.T) {
+	result := &native.ExecutionResult{
+		Status: native.StatusRuntimeError,
+		Error:  "exit status 1",
+		Output: harness.ResultPrefix + `{"index":0,"passed":true,"durationMs":1}` + "\n" +
+			"a <tag> & more\n" +
+			harness.ResultPrefix + `{"index":1,"passed":false,"durationMs":0.00001,"actual":5}` + "\n" +
+			harness.ResultPrefix + `{"index":2,"passed":false,"error":"boom"}` + "\n" +
+			harness.ResultPrefix + `{"index":3,"passed":false,"actual":41}` + "\n",
+	}
+	r := New(testChallenge, testCases, result)
+
+	var out bytes.Buffer
+	if err := Write(&out, "json", r); err != nil {
+		t.Fatalf("Write(json) failed: %v", err)
+	}
+	var decoded Report
+	if err := json.Unmarshal(out.Bytes(), &decoded); err != nil || len(decoded.Cases) != 4 || decoded.Cases[1].Failure != FailureWrongAnswer {
+		t.Errorf("Unexpected JSON report (%v):\n%s", err, out.String())
+	}
+
+	out.Reset()
+	if err := Write(&out, "tap", r); err != nil {
+		t.Fatalf("Write(tap) failed: %v", err)
+	}
+	tap := out.String()
+	for _, want := range []string{
+		"TAP version 13\n1..4\n",
+		"ok 1 - doubles one\n",
+		"not ok 2 - doubles two\n  ---\n  message: \"expected 4, got 5\"\n  failure: \"wrong_answer\"\n  durationMs: 0.00001\n  expected: 4\n  actual: 5\n  output: \"a <tag> & more\"\n  ...\n",
+		"not ok 3 - raises\n  ---\n  message: \"boom\"\n  failure: \"runtime_error\"\n",
+		"not ok 4 - hidden test case\n  ---\n  message: \"wrong answer\"\n",
+		"# double (python): 1/4 passed, status runtime error\n# exit status 1\n",
+	} {
+		if !strings.Contains(tap, want) {
+			t.Errorf("TAP output is missing %q:\n%s", want, tap)
+		}
+	}
+	for _, secret := range []string{"secret case", "42", "41"} {
+		if strings.Contains(tap, secret) {
+			t.Errorf("TAP output gives away %q of a hidden case:\n%s", secret, tap)
+		}
+	}
+
+	out.Reset()
+	if err := Write(&out, "junit", r); err != nil {
+		t.Fatalf("Write(junit) failed: %v", err)
+	}
+	var suites junitSuites
+	if err := xml.Unmarshal(out.Bytes(), &suites); err != nil {
+		t.Fatalf("Invalid JUnit XML (%v):\n%s", err, out.String())
+	}
+	suite := suites.Suites[0]
+	if suites.Tests != 4 || suite.Failures != 2 || suite.Errors != 1 || suite.Name != "double" {
+		t.Errorf("Unexpected JUnit counts: %+v", suites)
+	}
+	if c := suite.Cases[1]; c.Failure == nil || c.Failure.Type != "wrong_answer" || c.Time != "0.000000" || c.SystemOut != "a <tag> & more" {
+		t.Errorf("Unexpected JUnit wrong answer: %+v", c)
+	}
+	if c := suite.Cases[2]; c.Error == nil || c.Error.Message != "boom" {
+		t.Errorf("Unexpected JUnit runtime error: %+v", c)
+	}
+
+	if err := Write(&out, "yaml", r); err == nil {
+		t.Error("Expected an error for an unknown format")
+	}
+}
@@ -0,0 +1,232 @@
+// Package submission records signed submissions of challenge solutions and
+// posts them to a submission endpoint.
+//
+// A submission is sent and stored as an Envelope: the Record serialized as
+// JSON, an Ed25519 signature over exactly those bytes and the public key
+// that verifies it. The key is generated on first use and kept in the
+// codequest config directory, so every submission from one installation is
+// signed by the same key.
+package submission
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Algorithm identifies the signature scheme of an Envelope.
+const Algorithm = "ed25519"
+
+// Record describes one submission of a solution.
+type Record struct {
+	Slug     string `json:"slug"`
+	Language string `json:"language"`
+	// SolutionSHA256 is the hex encoded SHA-256 of the solution file
+	SolutionSHA256 string    `json:"solutionSha256"`
+	SubmittedAt    time.Time `json:"submittedAt"`
+	ClientVersion  string    `json:"clientVersion"`
+	// Passed is true when the program ran successfully and every test passed
+	Passed bool `json:"passed"`
+	// Status is how the program ended, such as "ok" or "compile error"
+	Status        string       `json:"status"`
+	CompileTimeMs float64      `json:"compileTimeMs"`
+	RunTimeMs     float64      `json:"runTimeMs"`
+	PeakMemory    int64        `json:"peakMemoryBytes,omitempty"`
+	Tests         []TestResult `json:"tests"`
+}
+
+// TestResult is the outcome of one test case.
+type TestResult struct {
+	Index      int     `json:"index"`
+	Passed     bool    `json:"passed"`
+	DurationMs float64 `json:"durationMs"`
+	Error      string  `json:"error,omitempty"`
+	// Hidden cases have no error, which could give away their input
+	Hidden bool `json:"hidden,omitempty"`
+}
+
+// HashSolution returns the hex encoded SHA-256 of a solution.
+func HashSolution(solution []byte) string {
+	sum := sha256.Sum256(solution)
+	return hex.EncodeToString(sum[:])
+}
+
+// Envelope is a signed Record as stored and posted.
+type Envelope struct {
+	Algorithm string `json:"algorithm"`
+	// PublicKey is the base64 encoded Ed25519 public key
+	PublicKey string `json:"publicKey"`
+	// Signature is the base64 encoded signature of Record's bytes
+	Signature string          `json:"signature"`
+	Record    json.RawMessage `json:"record"`
+}
+
+// Sign serializes record and signs it with key.
+func Sign(record Record, key ed25519.PrivateKey) (*Envelope, error) {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode submission: %w", err)
+	}
+	return &Envelope{
+		Algorithm: Algorithm,
+		PublicKey: base64.StdEncoding.EncodeToString(key.Public().(ed25519.PublicKey)),
+		Signature: base64.StdEncoding.EncodeToString(ed25519.Sign(key, data)),
+		Record:    data,
+	}, nil
+}
+
+// Verify checks the signature and returns the signed record.
+func (e *Envelope) Verify() (*Record, error) {
+	if e.Algorithm != Algorithm {
+		return nil, fmt.Errorf("unsupported signature algorithm %q", e.Algorithm)
+	}
+	publicKey, err := base64.StdEncoding.DecodeString(e.PublicKey)
+	if err != nil || len(publicKey) != ed25519.PublicKeySize {
+		return nil, errors.New("invalid public key")
+	}
+	signature, err := base64.StdEncoding.DecodeString(e.Signature)
+	if err != nil {
+		return nil, errors.New("invalid signature encoding")
+	}
+	if !ed25519.Verify(publicKey, e.Record, signature) {
+		return nil, errors.New("signature does not match the record")
+	}
+
+	var record Record
+	if err := json.Unmarshal(e.Record, &record); err != nil {
+		return nil, fmt.Errorf("failed to decode submission: %w", err)
+	}
+	return &record, nil
+}
+
+// LoadOrCreateKey reads the PEM encoded signing key at path, generating and
+// saving a new key if the file does not exist.
+func LoadOrCreateKey(path string) (ed25519.PrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if err == nil {
+		block, _ := pem.Decode(data)
+		if block == nil {
+			return nil, fmt.Errorf("signing key %s is not PEM encoded", path)
+		}
+		parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse signing key %s: %w", path, err)
+		}
+		key, ok := parsed.(ed25519.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("signing key %s is not an Ed25519 key", path)
+		}
+		return key, nil
+	}
+	if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read signing key: %w", err)
+	}
+
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate signing key: %w", err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode signing key: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, fmt.Errorf("failed to create key directory: %w", err)
+	}
+	data = pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return nil, fmt.Errorf("failed to save signing key: %w", err)
+	}
+	return key, nil
+}
+
+// Save writes the envelope to dir/<slug>/<time>.json and returns its path.
+// The slug of a pack challenge, such as team/two-sum, is a nested directory.
+func Save(dir string, envelope *Envelope) (string, error) {
+	var record Record
+	if err := json.Unmarshal(envelope.Record, &record); err != nil {
+		return "", fmt.Errorf("failed to decode submission: %w", err)
+	}
+	if strings.Contains(record.Slug, `\`) || !filepath.IsLocal(filepath.FromSlash(record.Slug)) {
+		return "", fmt.Errorf("invalid challenge slug %q", record.Slug)
+	}
+
+	slugDir := filepath.Join(dir, filepath.FromSlash(record.Slug))
+	if err := os.MkdirAll(slugDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create submissions directory: %w", err)
+	}
+	// Indenting would change the signed bytes of the record
+	data, err := json.Marshal(envelope)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode submission: %w", err)
+	}
+	path := filepath.Join(slugDir, record.SubmittedAt.UTC().Format("20060102T150405.000000000Z")+".json")
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return "", fmt.Errorf("failed to save submission: %w", err)
+	}
+	return path, nil
+}
+
+// Response is the body an endpoint returns for an accepted submission. Both
+// fields are optional.
+type Response struct {
+	ID      string `json:"id,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// Post sends the envelope to endpoint as a JSON POST request, with token as
+// a bearer token when it is not empty. Any 2xx status accepts the
+// submission; otherwise the error includes the response's message.
+func Post(ctx context.Context, client *http.Client, endpoint, token string, envelope *Envelope) (*Response, error) {
+	data, err := json.Marshal(envelope)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode submission: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("invalid submission endpoint: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to post submission: %w", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+
+	var response Response
+	// A body that isn't a Response is fine, it only carries a message
+	json.Unmarshal(body, &response)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		message := response.Message
+		if message == "" {
+			message = string(bytes.TrimSpace(body))
+		}
+		if message == "" {
+			return nil, fmt.Errorf("submission rejected: %s", resp.Status)
+		}
+		return nil, fmt.Errorf("submission rejected: %s: %s", resp.Status, message)
+	}
+	return &response, nil
+}
@@ -0,0 +1,166 @@
+package submission
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testRecord() Record {
+	return Record{
+		Slug:           "add-two-numbers",
+		Language:       "go",
+		SolutionSHA256: HashSolution([]byte("package main")),
+		SubmittedAt:    time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+		ClientVersion:  "dev",
+		Passed:         true,
+		Status:         "ok",
+		Tests:          []TestResult{{Index: 0, Passed: true, DurationMs: 0.5}},
+	}
+}
+
+func TestSignAndVerify(t *testing.T) {
+	key, err := LoadOrCreateKey(filepath.Join(t.TempDir(), "keys", "submit.key"))
+	if err != nil {
+		t.Fatalf("LoadOrCreateKey() failed: %v", err)
+	}
+
+	envelope, err := Sign(testRecord(), key)
+	if err != nil {
+		t.Fatalf("Sign() failed: %v", err)
+	}
+	record, err := envelope.Verify()
+	if err != nil {
+		t.Fatalf("Verify() failed: %v", err)
+	}
+	if record.Slug != "add-two-numbers" || len(record.Tests) != 1 {
+		t.Errorf("Unexpected record: %+v", record)
+	}
+
+	envelope.Record = []byte(strings.Replace(string(envelope.Record), `"passed":true`, `"passed":false`, 1))
+	if _, err := envelope.Verify(); err == nil {
+		t.Error("Expected a tampered record to fail verification")
+	}
+}
+
+func TestLoadOrCreateKeyReusesKey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "submit.key")
+	first, err := LoadOrCreateKey(path)
+	if err != nil {
+		t.Fatalf("LoadOrCreateKey() failed: %v", err)
+	}
+	second, err := LoadOrCreateKey(path)
+	if err != nil {
+		t.Fatalf("LoadOrCreateKey() failed: %v", err)
+	}
+	if !first.Equal(second) {
+		t.Error("Expected the saved key to be loaded again")
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("Expected a private key file, got %v (%v)", info, err)
+	}
+}
+
+func TestSave(t *testing.T) {
+	key, err := LoadOrCreateKey(filepath.Join(t.TempDir(), "submit.key"))
+	if err != nil {
+		t.Fatalf("LoadOrCreateKey() failed: %v", err)
+	}
+	envelope, err := Sign(testRecord(), key)
+	if err != nil {
+		t.Fatalf("Sign() failed: %v", err)
+	}
+
+	dir := t.TempDir()
+	path, err := Save(dir, envelope)
+	if err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+	if filepath.Dir(path) != filepath.Join(dir, "add-two-numbers") {
+		t.Errorf("Unexpected path %s", path)
+	}
+
+	// Pack challenges are saved in a directory per pack, and slugs can't
+	// leave dir
+	for slug, ok := range map[string]bool{"team/two-sum": true, "../two-sum": false, "/two-sum": false, "": false} {
+		record := testRecord()
+		record.Slug = slug
+		packEnvelope, err := Sign(record, key)
+		if err != nil {
+			t.Fatalf("Sign() failed: %v", err)
+		}
+		packPath, err := Save(dir, packEnvelope)
+		if ok && (err != nil || filepath.Dir(packPath) != filepath.Join(dir, "team", "two-sum")) {
+			t.Errorf("Save() of %q = %s, %v", slug, packPath, err)
+		}
+		if !ok && err == nil {
+			t.Errorf("Expected Save() to reject slug %q", slug)
+		}
+	}
+
+	// The saved file still verifies
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read submission: %v", err)
+	}
+	var saved Envelope
+	if err := json.Unmarshal(data, &saved); err != nil {
+		t.Fatalf("Failed to decode submission: %v", err)
+	}
+	if _, err := saved.Verify(); err != nil {
+		t.Errorf("Expected the saved submission to verify: %v", err)
+	}
+}
+
+func TestPost(t *testing.T) {
+	key, err := LoadOrCreateKey(filepath.Join(t.TempDir(), "submit.key"))
+	if err != nil {
+		t.Fatalf("LoadOrCreateKey() failed: %v", err)
+	}
+	envelope, err := Sign(testRecord(), key)
+	if err != nil {
+		t.Fatalf("Sign() failed: %v", err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"message":"invalid token"}`))
+			return
+		}
+		var received Envelope
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if _, err := received.Verify(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.Write([]byte(`{"id":"42","message":"accepted"}`))
+	}))
+	defer server.Close()
+
+	response, err := Post(context.Background(), server.Client(), server.URL, "secret", envelope)
+	if err != nil {
+		t.Fatalf("Post() failed: %v", err)
+	}
+	if response.ID != "42" || response.Message != "accepted" {
+		t.Errorf("Unexpected response: %+v", response)
+	}
+
+	_, err = Post(context.Background(), server.Client(), server.URL, "wrong", envelope)
+	if err == nil || !strings.Contains(err.Error(), "invalid token") {
+		t.Errorf("Expected the rejection message, got %v", err)
+	}
+}
@@ -0,0 +1,88 @@
+// Package watch reports when a file is saved.
+package watch
+
+import (
+	"io"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// DefaultDelay is how long a file must stay unchanged after a change before
+// it is reported, so that one save that writes several times is reported
+// once.
+const DefaultDelay = 100 * time.Millisecond
+
+// Watcher reports changes to one file. It watches the file's directory, so
+// it keeps working when an editor saves by replacing the file.
+type Watcher struct {
+	// Changes receives a value after the file changed and stayed
+	// unchanged for the delay. Changes made before the value is received
+	// are merged into it.
+	Changes <-chan struct{}
+	// Errors receives a value if watching stops working.
+	Errors <-chan error
+
+	source io.Closer
+	mu     sync.Mutex
+	timer  *time.Timer
+	closed bool
+}
+
+// New starts watching the file at path, which doesn't have to exist yet.
+func New(path string, delay time.Duration) (*Watcher, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make(chan struct{}, 1)
+	errs := make(chan error, 1)
+	w := &Watcher{Changes: changes, Errors: errs}
+
+	changed := func() {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		if w.closed {
+			return
+		}
+		if w.timer != nil {
+			w.timer.Reset(delay)
+			return
+		}
+		w.timer = time.AfterFunc(delay, func() {
+			select {
+			case changes <- struct{}{}:
+			default:
+			}
+		})
+	}
+	failed := func(err error) {
+		select {
+		case errs <- err:
+		default:
+		}
+	}
+
+	w.source, err = watchFile(path, changed, failed)
+	if err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// Close stops watching. A change reported before may still be waiting in
+// Changes.
+func (w *Watcher) Close() error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+	w.closed = true
+	if w.timer != nil {
+		w.timer.Stop()
+	}
+	w.mu.Unlock()
+	return w.source.Close()
+}
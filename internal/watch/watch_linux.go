@@ -0,0 +1,57 @@
+package watch
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"syscall"
+	"unsafe"
+)
+
+// watchEvents are the inotify events that change a file in the watched
+// directory: writing to it, or creating it or moving it there, as editors
+// do when they save by replacing the file.
+const watchEvents = syscall.IN_MODIFY | syscall.IN_CLOSE_WRITE | syscall.IN_CREATE | syscall.IN_MOVED_TO
+
+// watchFile calls changed whenever inotify reports a change to the file at
+// path, until the returned closer is closed, and calls failed if reading
+// events fails.
+func watchFile(path string, changed func(), failed func(error)) (io.Closer, error) {
+	fd, err := syscall.InotifyInit1(syscall.IN_CLOEXEC | syscall.IN_NONBLOCK)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start inotify: %w", err)
+	}
+	dir, name := filepath.Split(path)
+	if _, err := syscall.InotifyAddWatch(fd, dir, watchEvents); err != nil {
+		syscall.Close(fd)
+		return nil, fmt.Errorf("failed to watch %s: %w", dir, err)
+	}
+
+	// A non-blocking descriptor goes through the runtime poller, so closing
+	// the file interrupts a pending read
+	file := os.NewFile(uintptr(fd), "inotify")
+	go func() {
+		buf := make([]byte, 64*(syscall.SizeofInotifyEvent+syscall.NAME_MAX+1))
+		for {
+			n, err := file.Read(buf)
+			if err != nil {
+				if !errors.Is(err, os.ErrClosed) {
+					failed(fmt.Errorf("failed to read file events: %w", err))
+				}
+				return
+			}
+			for offset := 0; offset+syscall.SizeofInotifyEvent <= n; {
+				event := (*syscall.InotifyEvent)(unsafe.Pointer(&buf[offset]))
+				nameBytes := buf[offset+syscall.SizeofInotifyEvent : offset+syscall.SizeofInotifyEvent+int(event.Len)]
+				offset += syscall.SizeofInotifyEvent + int(event.Len)
+				if string(bytes.TrimRight(nameBytes, "\x00")) == name {
+					changed()
+				}
+			}
+		}
+	}()
+	return file, nil
+}
@@ -0,0 +1,52 @@
+//go:build !linux
+
+package watch
+
+import (
+	"io"
+	"os"
+	"time"
+)
+
+// pollInterval is how often the file is checked without inotify.
+const pollInterval = 200 * time.Millisecond
+
+// poller stops polling when closed.
+type poller chan struct{}
+
+func (p poller) Close() error {
+	close(p)
+	return nil
+}
+
+// watchFile calls changed whenever the modification time or size of the
+// file at path changes, checking it every pollInterval until the returned
+// closer is closed. Polling can't fail, so failed is never called.
+func watchFile(path string, changed func(), failed func(error)) (io.Closer, error) {
+	stat := func() (time.Time, int64) {
+		info, err := os.Stat(path)
+		if err != nil {
+			return time.Time{}, -1
+		}
+		return info.ModTime(), info.Size()
+	}
+
+	done := make(poller)
+	modTime, size := stat()
+	go func() {
+		ticker := time.NewTicker(pollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if t, s := stat(); !t.Equal(modTime) || s != size {
+					modTime, size = t, s
+					changed()
+				}
+			}
+		}
+	}()
+	return done, nil
+}
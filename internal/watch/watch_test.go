@@ -0,0 +1,85 @@
+package watch
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// expectChange fails unless w reports a change within a second.
+func expectChange(t *testing.T, w *Watcher, what string) {
+	t.Helper()
+	select {
+	case <-w.Changes:
+	case err := <-w.Errors:
+		t.Fatalf("Watching failed after %s: %v", what, err)
+	case <-time.After(time.Second):
+		t.Fatalf("Expected a change after %s", what)
+	}
+}
+
+// expectNoChange fails if w reports a change within wait.
+func expectNoChange(t *testing.T, w *Watcher, what string, wait time.Duration) {
+	t.Helper()
+	select {
+	case <-w.Changes:
+		t.Fatalf("Expected no change after %s", what)
+	case <-time.After(wait):
+	}
+}
+
+func TestWatcher(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "solution.py")
+	if err := os.WriteFile(path, []byte("a"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	w, err := New(path, 50*time.Millisecond)
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+	defer w.Close()
+
+	// Several writes in a row are one change
+	for _, content := range []string{"ab", "abc", "abcd"} {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	expectChange(t, w, "writing the file")
+	expectNoChange(t, w, "the writes were reported", 300*time.Millisecond)
+
+	// Editors that save by replacing the file
+	replacement := filepath.Join(dir, "solution.py.tmp")
+	if err := os.WriteFile(replacement, []byte("replaced"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(replacement, path); err != nil {
+		t.Fatal(err)
+	}
+	expectChange(t, w, "replacing the file")
+
+	if err := w.Close(); err != nil {
+		t.Errorf("Close() failed: %v", err)
+	}
+	if err := os.WriteFile(path, []byte("closed"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	expectNoChange(t, w, "closing the watcher", 300*time.Millisecond)
+}
+
+func TestWatcherIgnoresOtherFiles(t *testing.T) {
+	dir := t.TempDir()
+	w, err := New(filepath.Join(dir, "solution.py"), 50*time.Millisecond)
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+	defer w.Close()
+
+	if err := os.WriteFile(filepath.Join(dir, "README.md"), []byte("notes"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	expectNoChange(t, w, "writing another file", 300*time.Millisecond)
+}